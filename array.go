@@ -0,0 +1,68 @@
+package fmap
+
+import (
+	"fmt"
+	"reflect"
+	"unsafe"
+)
+
+// GetArrayIndex returns the i-th element of the array field in the provided object without copying the whole array.
+// It panics if the field is not an array or if the i is out of range.
+func (f *field) GetArrayIndex(obj any, i int) any {
+	ptr, elemType, err := f.arrayElemPtr(obj, i, false)
+	if err != nil {
+		panic(err)
+	}
+	return getValue(elemType, ptr)
+}
+
+// SetArrayIndex sets the i-th element of the array field in the provided object in place.
+// It panics if the field is not an array, if the i is out of range or if the val is not assignable to the element type.
+func (f *field) SetArrayIndex(obj any, i int, val any) {
+	ptr, elemType, err := f.arrayElemPtr(obj, i, true)
+	if err != nil {
+		panic(err)
+	}
+	setValue(elemType, ptr, f.elemValue(val, elemType).Interface())
+}
+
+// TryGetArrayIndex is the GetArrayIndex variant that returns an error instead of panic.
+func (f *field) TryGetArrayIndex(obj any, i int) (any, error) {
+	ptr, elemType, err := f.arrayElemPtr(obj, i, false)
+	if err != nil {
+		return nil, err
+	}
+	return getValue(elemType, ptr), nil
+}
+
+// TrySetArrayIndex is the SetArrayIndex variant that returns an error instead of panic.
+func (f *field) TrySetArrayIndex(obj any, i int, val any) error {
+	ptr, elemType, err := f.arrayElemPtr(obj, i, true)
+	if err != nil {
+		return err
+	}
+	valType := reflect.TypeOf(val)
+	if valType == nil && !isNilable(elemType) || valType != nil && !valType.AssignableTo(elemType) {
+		return fmt.Errorf("fmap: field %s: value of type %v is not assignable to %v", f.structPath, valType, elemType)
+	}
+	setValue(elemType, ptr, f.elemValue(val, elemType).Interface())
+	return nil
+}
+
+// arrayElemPtr returns the pointer to the i-th element of the array field and the element type.
+// The elements of the array are laid out contiguously and the element size is always a multiple of its alignment,
+// so the i-th element is at the i*elemSize offset from the array start and is properly aligned.
+func (f *field) arrayElemPtr(obj any, i int, alloc bool) (unsafe.Pointer, reflect.Type, error) {
+	if f.Type.Kind() != reflect.Array {
+		return nil, nil, fmt.Errorf("fmap: field %s: not supported type: %v, only array is supported", f.structPath, f.Type)
+	}
+	if i < 0 || i >= f.Type.Len() {
+		return nil, nil, fmt.Errorf("fmap: field %s: index out of range [%d] with length %d", f.structPath, i, f.Type.Len())
+	}
+	ptr, err := f.tryGetPtr(obj, alloc)
+	if err != nil {
+		return nil, nil, err
+	}
+	elemType, elemSize := f.elem()
+	return unsafe.Add(ptr, uintptr(i)*elemSize), elemType, nil
+}
@@ -0,0 +1,129 @@
+package fmap
+
+import (
+	"sync"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestField_ArrayIndex(t *testing.T) {
+	type point struct {
+		X, Y int16
+	}
+	type testStruct struct {
+		Flag   bool
+		Buf    [4]byte
+		Words  [3]uint16
+		Longs  [4]int64
+		Names  [2]string
+		Points [2]point
+		Slice  []int
+		Refs   [2]*int
+	}
+	fields, _ := Get[testStruct]()
+	buf := fields.MustFind("Buf")
+
+	t.Run("Get", func(t *testing.T) {
+		obj := &testStruct{
+			Buf:    [4]byte{1, 2, 3, 4},
+			Words:  [3]uint16{10, 20, 30},
+			Longs:  [4]int64{-1, -2, -3, -4},
+			Names:  [2]string{"a", "b"},
+			Points: [2]point{{1, 2}, {3, 4}},
+		}
+		assert.Equal(t, byte(3), buf.GetArrayIndex(obj, 2))
+		assert.Equal(t, uint16(30), fields.MustFind("Words").GetArrayIndex(obj, 2))
+		assert.Equal(t, int64(-4), fields.MustFind("Longs").GetArrayIndex(obj, 3))
+		assert.Equal(t, "b", fields.MustFind("Names").GetArrayIndex(obj, 1))
+		assert.Equal(t, point{3, 4}, fields.MustFind("Points").GetArrayIndex(obj, 1))
+	})
+	t.Run("Set", func(t *testing.T) {
+		obj := &testStruct{}
+		buf.SetArrayIndex(obj, 3, byte(7))
+		fields.MustFind("Words").SetArrayIndex(obj, 1, uint16(65535))
+		fields.MustFind("Longs").SetArrayIndex(obj, 0, int64(42))
+		fields.MustFind("Names").SetArrayIndex(obj, 0, "x")
+		fields.MustFind("Points").SetArrayIndex(obj, 0, point{5, 6})
+		assert.Equal(t, [4]byte{0, 0, 0, 7}, obj.Buf)
+		assert.Equal(t, [3]uint16{0, 65535, 0}, obj.Words)
+		assert.Equal(t, [4]int64{42, 0, 0, 0}, obj.Longs)
+		assert.Equal(t, [2]string{"x", ""}, obj.Names)
+		assert.Equal(t, [2]point{{5, 6}, {}}, obj.Points)
+		assert.False(t, obj.Flag)
+	})
+	t.Run("Alignment", func(t *testing.T) {
+		obj := &testStruct{}
+		for _, path := range []string{"Buf", "Words", "Longs", "Names", "Points"} {
+			fld := fields.MustFind(path).(*field)
+			elemType := fld.Type.Elem()
+			for i := 0; i < fld.Type.Len(); i++ {
+				ptr, _, err := fld.arrayElemPtr(obj, i, false)
+				assert.NoError(t, err)
+				assert.Equal(t, uintptr(0), uintptr(ptr)%uintptr(elemType.Align()), path)
+			}
+		}
+		ptr, _, _ := fields.MustFind("Longs").(*field).arrayElemPtr(obj, 3, false)
+		assert.Equal(t, unsafe.Pointer(&obj.Longs[3]), ptr)
+		ptr, _, _ = fields.MustFind("Words").(*field).arrayElemPtr(obj, 2, false)
+		assert.Equal(t, unsafe.Pointer(&obj.Words[2]), ptr)
+	})
+	t.Run("OutOfRange", func(t *testing.T) {
+		obj := &testStruct{}
+		assert.PanicsWithError(t, "fmap: field Buf: index out of range [4] with length 4", func() {
+			buf.GetArrayIndex(obj, 4)
+		})
+		assert.Panics(t, func() { buf.SetArrayIndex(obj, -1, byte(1)) })
+		_, err := buf.TryGetArrayIndex(obj, 4)
+		assert.EqualError(t, err, "fmap: field Buf: index out of range [4] with length 4")
+		assert.Error(t, buf.TrySetArrayIndex(obj, -1, byte(1)))
+	})
+	t.Run("WrongType", func(t *testing.T) {
+		obj := &testStruct{}
+		assert.Panics(t, func() { buf.SetArrayIndex(obj, 0, 1) })
+		assert.EqualError(t, buf.TrySetArrayIndex(obj, 0, 1), "fmap: field Buf: value of type int is not assignable to uint8")
+		assert.EqualError(t, fields.MustFind("Slice").TrySetArrayIndex(obj, 0, 1),
+			"fmap: field Slice: not supported type: []int, only array is supported")
+		assert.Equal(t, [4]byte{}, obj.Buf)
+	})
+	t.Run("Try", func(t *testing.T) {
+		obj := &testStruct{Buf: [4]byte{9}}
+		val, err := buf.TryGetArrayIndex(obj, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, byte(9), val)
+		assert.NoError(t, buf.TrySetArrayIndex(obj, 1, byte(8)))
+		assert.Equal(t, byte(8), obj.Buf[1])
+		_, err = buf.TryGetArrayIndex(testStruct{}, 0)
+		assert.Error(t, err)
+	})
+	t.Run("Nil", func(t *testing.T) {
+		one := 1
+		obj := &testStruct{Refs: [2]*int{&one, &one}}
+		refs := fields.MustFind("Refs")
+		assert.NoError(t, refs.TrySetArrayIndex(obj, 0, nil))
+		refs.SetArrayIndex(obj, 1, nil)
+		assert.Equal(t, [2]*int{}, obj.Refs)
+		assert.EqualError(t, buf.TrySetArrayIndex(obj, 0, nil), "fmap: field Buf: value of type <nil> is not assignable to uint8")
+	})
+}
+
+func TestField_ArrayIndexConcurrent(t *testing.T) {
+	type testStruct struct {
+		Longs [4]int64
+		Prep  [2]uint32
+	}
+	fields, _ := GetFromWithOptions(testStruct{}, Options{})
+	obj := &testStruct{Longs: [4]int64{1, 2, 3, 4}}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fields.Prepare()
+			assert.Equal(t, int64(4), fields.MustFind("Longs").GetArrayIndex(obj, 3))
+			assert.Equal(t, uint32(0), fields.MustFind("Prep").GetArrayIndex(obj, 1))
+		}()
+	}
+	wg.Wait()
+}
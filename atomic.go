@@ -0,0 +1,123 @@
+package fmap
+
+import (
+	"fmt"
+	"reflect"
+	"sync/atomic"
+	"unsafe"
+)
+
+// GetAtomic returns the value of the int32, int64, uint32 or uint64 field in the provided object loaded atomically,
+// e.g. for the lock-free counters stored in the struct fields. The named types are returned as the declared type.
+// The 64-bit fields must be 64-bit aligned, which is guaranteed on the 64-bit platforms only: on the 32-bit ones,
+// e.g. 386 and ARM, the struct must be allocated and the field must be the first one or follow the 64-bit fields,
+// see the sync/atomic bugs section.
+// It panics if the field is not one of the supported kinds or is not aligned.
+func (f *field) GetAtomic(obj any) any {
+	ptr := f.atomicPtr(f.getReadPtr(obj))
+	var val any
+	switch f.Type.Kind() {
+	case reflect.Int32:
+		val = atomic.LoadInt32((*int32)(ptr))
+	case reflect.Int64:
+		val = atomic.LoadInt64((*int64)(ptr))
+	case reflect.Uint32:
+		val = atomic.LoadUint32((*uint32)(ptr))
+	default:
+		val = atomic.LoadUint64((*uint64)(ptr))
+	}
+	if f.Type.PkgPath() != "" {
+		return reflect.ValueOf(val).Convert(f.Type).Interface()
+	}
+	return val
+}
+
+// SetAtomic stores the val to the int32, int64, uint32 or uint64 field in the provided object atomically.
+// It panics if the field is not one of the supported kinds or is not aligned, see GetAtomic,
+// or if the val is not of the field type.
+func (f *field) SetAtomic(obj any, val any) {
+	ptr := f.atomicPtr(f.getPtr(obj))
+	valOf := reflect.ValueOf(val)
+	if !valOf.IsValid() || valOf.Type() != f.Type {
+		panic(fmt.Errorf("fmap: field %s: value of type %v is not assignable to %v", f.structPath, reflect.TypeOf(val), f.Type))
+	}
+	switch f.Type.Kind() {
+	case reflect.Int32:
+		atomic.StoreInt32((*int32)(ptr), int32(valOf.Int()))
+	case reflect.Int64:
+		atomic.StoreInt64((*int64)(ptr), valOf.Int())
+	case reflect.Uint32:
+		atomic.StoreUint32((*uint32)(ptr), uint32(valOf.Uint()))
+	default:
+		atomic.StoreUint64((*uint64)(ptr), valOf.Uint())
+	}
+}
+
+// AddAtomic atomically adds the delta to the int32, int64, uint32 or uint64 field in the provided object
+// and returns the new value. The delta and the result are truncated to the field bit width, e.g. the negative delta
+// decrements the unsigned field, and the uint64 result above math.MaxInt64 is returned as the negative int64.
+// It panics if the field is not one of the supported kinds or is not aligned, see GetAtomic.
+func (f *field) AddAtomic(obj any, delta int64) int64 {
+	ptr := f.atomicPtr(f.getPtr(obj))
+	switch f.Type.Kind() {
+	case reflect.Int32:
+		return int64(atomic.AddInt32((*int32)(ptr), int32(delta)))
+	case reflect.Int64:
+		return atomic.AddInt64((*int64)(ptr), delta)
+	case reflect.Uint32:
+		return int64(atomic.AddUint32((*uint32)(ptr), uint32(delta)))
+	default:
+		return int64(atomic.AddUint64((*uint64)(ptr), uint64(delta)))
+	}
+}
+
+// atomicPtr checks that the field kind is supported by the atomic operations and the ptr is aligned for them.
+func (f *field) atomicPtr(ptr unsafe.Pointer) unsafe.Pointer {
+	switch f.Type.Kind() {
+	case reflect.Int32, reflect.Int64, reflect.Uint32, reflect.Uint64:
+	default:
+		panic(fmt.Errorf("fmap: field %s: not supported type: %v, only int32, int64, uint32 and uint64 are supported", f.structPath, f.Type))
+	}
+	if uintptr(ptr)%f.Type.Size() != 0 {
+		panic(fmt.Errorf("fmap: field %s: address %p is not %d-bit aligned for the atomic access", f.structPath, ptr, f.Type.Bits()))
+	}
+	return ptr
+}
+
+// isAtomicType reports whether the typeOf is one of the sync/atomic wrapper structs, e.g. atomic.Int64,
+// atomic.Value or atomic.Pointer[T], which must not be copied and are kept as leaves.
+func isAtomicType(typeOf reflect.Type) bool {
+	return typeOf.Kind() == reflect.Struct && typeOf.PkgPath() == "sync/atomic"
+}
+
+// GetAtomicValue returns the value of the sync/atomic wrapper field, e.g. atomic.Int64, atomic.Bool, atomic.Value
+// or atomic.Pointer[T], in the provided object loaded with its Load method, e.g. int64 for the atomic.Int64 field,
+// as Get returns the copy of the wrapper struct, which is not safe. Use GetAtomic for the plain integer fields.
+// It panics if the field is not the sync/atomic wrapper.
+func (f *field) GetAtomicValue(obj any) any {
+	return f.atomicMethod(f.getReadPtr(obj), "Load").Call(nil)[0].Interface()
+}
+
+// SetAtomicValue stores the val to the sync/atomic wrapper field in the provided object with its Store method,
+// the nil val is stored as the nil pointer to the atomic.Pointer[T] field.
+// It panics if the field is not the sync/atomic wrapper or if the val is not assignable to the Store argument type.
+func (f *field) SetAtomicValue(obj any, val any) {
+	store := f.atomicMethod(f.getPtr(obj), "Store")
+	argType := store.Type().In(0)
+	valOf := reflect.ValueOf(val)
+	switch {
+	case !valOf.IsValid() && isNilable(argType) && argType.Kind() != reflect.Interface:
+		valOf = reflect.Zero(argType)
+	case !valOf.IsValid() || !valOf.Type().AssignableTo(argType):
+		panic(fmt.Errorf("fmap: field %s: value of type %v is not assignable to %v", f.structPath, reflect.TypeOf(val), argType))
+	}
+	store.Call([]reflect.Value{valOf})
+}
+
+// atomicMethod returns the method of the sync/atomic wrapper field at the ptr.
+func (f *field) atomicMethod(ptr unsafe.Pointer, name string) reflect.Value {
+	if !isAtomicType(f.Type) {
+		panic(fmt.Errorf("fmap: field %s: not supported type: %v, only sync/atomic types are supported", f.structPath, f.Type))
+	}
+	return reflect.NewAt(f.Type, ptr).MethodByName(name)
+}
@@ -0,0 +1,79 @@
+package fmap
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type atomicCounter int64
+
+func TestField_Atomic(t *testing.T) {
+	type testStruct struct {
+		Int64   int64
+		Uint64  uint64
+		Int32   int32
+		Uint32  uint32
+		Named   atomicCounter
+		Int     int
+		Float64 float64
+	}
+	fields, _ := Get[testStruct]()
+
+	t.Run("GetSet", func(t *testing.T) {
+		obj := &testStruct{}
+		for path, val := range map[string]any{
+			"Int64":  int64(-5),
+			"Uint64": uint64(1 << 63),
+			"Int32":  int32(-7),
+			"Uint32": uint32(7),
+			"Named":  atomicCounter(3),
+		} {
+			fld := fields.MustFind(path)
+			fld.SetAtomic(obj, val)
+			assert.Equal(t, val, fld.GetAtomic(obj), path)
+			assert.Equal(t, val, fld.Get(obj), path)
+		}
+	})
+	t.Run("Add", func(t *testing.T) {
+		obj := &testStruct{}
+		assert.Equal(t, int64(5), fields.MustFind("Int64").AddAtomic(obj, 5))
+		assert.Equal(t, int64(3), fields.MustFind("Int64").AddAtomic(obj, -2))
+		assert.Equal(t, int64(-1), fields.MustFind("Int32").AddAtomic(obj, -1))
+		assert.Equal(t, int64(2), fields.MustFind("Uint32").AddAtomic(obj, 2))
+		assert.Equal(t, int64(1), fields.MustFind("Uint32").AddAtomic(obj, -1))
+		// the negative delta wraps the unsigned field
+		assert.Equal(t, int64(-1), fields.MustFind("Uint64").AddAtomic(obj, -1))
+		assert.Equal(t, ^uint64(0), obj.Uint64)
+		assert.Equal(t, int64(3), obj.Int64)
+	})
+	t.Run("Concurrent", func(t *testing.T) {
+		obj := &testStruct{}
+		counter := fields.MustFind("Int64")
+		wg := sync.WaitGroup{}
+		for i := 0; i < 100; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				counter.AddAtomic(obj, 1)
+				_ = counter.GetAtomic(obj)
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, int64(100), counter.GetAtomic(obj))
+	})
+	t.Run("Errors", func(t *testing.T) {
+		obj := &testStruct{}
+		assert.PanicsWithError(t, "fmap: field Int: not supported type: int, only int32, int64, uint32 and uint64 are supported", func() {
+			fields.MustFind("Int").GetAtomic(obj)
+		})
+		assert.Panics(t, func() { fields.MustFind("Float64").AddAtomic(obj, 1) })
+		assert.PanicsWithError(t, "fmap: field Int64: value of type int is not assignable to int64", func() {
+			fields.MustFind("Int64").SetAtomic(obj, 1)
+		})
+		assert.Panics(t, func() { fields.MustFind("Named").SetAtomic(obj, int64(1)) })
+		assert.Panics(t, func() { fields.MustFind("Int64").SetAtomic(obj, nil) })
+		assert.Panics(t, func() { fields.MustFind("Int64").GetAtomic(*obj) })
+	})
+}
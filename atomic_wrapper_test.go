@@ -0,0 +1,51 @@
+//go:build go1.19
+
+package fmap
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type atomicWrappers struct {
+	Count   atomic.Int64
+	Enabled atomic.Bool
+	Config  atomic.Value
+	Name    atomic.Pointer[string]
+	Plain   int64
+}
+
+func TestField_AtomicValue(t *testing.T) {
+	fields, err := Get[atomicWrappers]()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Count", "Enabled", "Config", "Name", "Plain"}, fields.GetAllPaths())
+	withUnexported, err := GetFromWithOptions(atomicWrappers{}, Options{IncludeUnexported: true})
+	assert.NoError(t, err)
+	assert.Equal(t, fields.GetAllPaths(), withUnexported.GetAllPaths())
+
+	obj := &atomicWrappers{}
+	obj.Count.Store(5)
+	assert.Equal(t, int64(5), fields.MustFind("Count").GetAtomicValue(obj))
+	fields.MustFind("Count").SetAtomicValue(obj, int64(7))
+	assert.Equal(t, int64(7), obj.Count.Load())
+
+	fields.MustFind("Enabled").SetAtomicValue(obj, true)
+	assert.True(t, obj.Enabled.Load())
+	assert.Equal(t, true, fields.MustFind("Enabled").GetAtomicValue(obj))
+
+	assert.Nil(t, fields.MustFind("Config").GetAtomicValue(obj))
+	fields.MustFind("Config").SetAtomicValue(obj, "cfg")
+	assert.Equal(t, "cfg", obj.Config.Load())
+
+	name := "john"
+	fields.MustFind("Name").SetAtomicValue(obj, &name)
+	assert.Same(t, &name, fields.MustFind("Name").GetAtomicValue(obj))
+	fields.MustFind("Name").SetAtomicValue(obj, nil)
+	assert.Nil(t, obj.Name.Load())
+
+	assert.Panics(t, func() { fields.MustFind("Count").SetAtomicValue(obj, 1) })
+	assert.Panics(t, func() { fields.MustFind("Plain").GetAtomicValue(obj) })
+	assert.Panics(t, func() { fields.MustFind("Count").GetAtomicValue(atomicWrappers{}) })
+}
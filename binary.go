@@ -0,0 +1,169 @@
+package fmap
+
+import (
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"sync"
+	"unsafe"
+)
+
+// binaryPlans caches the binaryPlan of the ptr to struct types.
+var binaryPlans sync.Map
+
+// binaryPlan is the fixed binary layout of the struct built from the field map.
+type binaryPlan struct {
+	fields []binaryField
+	size   int
+	// err is the unsupported field error, the plan is cached with it to not walk the fields again.
+	err error
+}
+
+// binaryField is the leaf field encoded as the count of the elemSize-byte values.
+type binaryField struct {
+	fld      *field
+	elemSize uintptr
+	count    int
+	isBool   bool
+}
+
+// EncodeBinary returns the fixed-layout binary encoding of the struct pointed to by obj, e.g. for the binary protocols.
+// The leaf fields are encoded one after another in the declaration order, without the padding, like binary.Write does,
+// each value in the order byte order. The supported types are bool, int8-int64, uint8-uint64, float32, float64,
+// complex64, complex128 and arrays of them, the variable-size fields, e.g. strings and slices, and the platform
+// dependent int, uint and uintptr return the error. The nested structs are expanded, the fields behind the nil embedded
+// struct pointers are encoded as zero. The unexported fields, including the blank ones, aren't encoded.
+func EncodeBinary(obj any, order binary.ByteOrder) ([]byte, error) {
+	fields, err := getFromPtr(obj)
+	if err != nil {
+		return nil, err
+	}
+	root := objPointer(obj)
+	if root == nil {
+		return nil, fmt.Errorf("fmap: binary: can't encode the nil %v", reflect.TypeOf(obj))
+	}
+	plan := getBinaryPlan(reflect.TypeOf(obj), fields)
+	if plan.err != nil {
+		return nil, plan.err
+	}
+	data := make([]byte, plan.size)
+	off := 0
+	for _, bf := range plan.fields {
+		base := bf.fld.basePtr(root, false)
+		if base == nil {
+			off += bf.count * int(bf.elemSize)
+			continue
+		}
+		ptr := unsafe.Add(base, bf.fld.Offset)
+		for i := 0; i < bf.count; i++ {
+			bits := loadBits(unsafe.Add(ptr, uintptr(i)*bf.elemSize), bf.elemSize)
+			if bf.isBool && bits != 0 {
+				bits = 1
+			}
+			putBits(data[off:], order, bf.elemSize, bits)
+			off += int(bf.elemSize)
+		}
+	}
+	return data, nil
+}
+
+// DecodeBinary decodes the fixed-layout binary data into the struct pointed to by obj, the inverse of EncodeBinary.
+// The data length must match the encoded struct size exactly, the nil embedded struct pointers are allocated.
+// The non-zero bytes are decoded as the true bool values.
+func DecodeBinary(obj any, data []byte, order binary.ByteOrder) error {
+	fields, err := getFromPtr(obj)
+	if err != nil {
+		return err
+	}
+	root := objPointer(obj)
+	if root == nil {
+		return fmt.Errorf("fmap: binary: can't decode into the nil %v", reflect.TypeOf(obj))
+	}
+	plan := getBinaryPlan(reflect.TypeOf(obj), fields)
+	if plan.err != nil {
+		return plan.err
+	}
+	if len(data) != plan.size {
+		return fmt.Errorf("fmap: binary: data length %d doesn't match the %v size %d", len(data), reflect.TypeOf(obj).Elem(), plan.size)
+	}
+	off := 0
+	for _, bf := range plan.fields {
+		ptr := unsafe.Add(bf.fld.basePtr(root, true), bf.fld.Offset)
+		for i := 0; i < bf.count; i++ {
+			bits := getBits(data[off:], order, bf.elemSize)
+			if bf.isBool && bits != 0 {
+				bits = 1
+			}
+			storeBits(unsafe.Add(ptr, uintptr(i)*bf.elemSize), bf.elemSize, bits)
+			off += int(bf.elemSize)
+		}
+	}
+	return nil
+}
+
+func getBinaryPlan(typeOf reflect.Type, fields *storage) *binaryPlan {
+	if plan, ok := binaryPlans.Load(typeOf); ok {
+		return plan.(*binaryPlan)
+	}
+	plan, _ := binaryPlans.LoadOrStore(typeOf, newBinaryPlan(fields))
+	return plan.(*binaryPlan)
+}
+
+func newBinaryPlan(fields *storage) *binaryPlan {
+	plan := &binaryPlan{}
+	for _, path := range fields.paths {
+		fld := fields.asMap[path].(*field)
+		if fld.hasChildren || fld.readOnly {
+			continue
+		}
+		bf := binaryField{fld: fld, count: 1}
+		elemType := fld.Type
+		for elemType.Kind() == reflect.Array {
+			bf.count *= elemType.Len()
+			elemType = elemType.Elem()
+		}
+		switch elemType.Kind() {
+		case reflect.Bool, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+			bf.elemSize = elemType.Size()
+		case reflect.Complex64, reflect.Complex128:
+			// the real and imaginary parts are encoded as two floats
+			bf.elemSize = elemType.Size() / 2
+			bf.count *= 2
+		default:
+			return &binaryPlan{err: fmt.Errorf("fmap: field %s: not supported type: %v, only fixed-size bool, integer, float, complex and arrays of them are supported", fld.structPath, fld.Type)}
+		}
+		bf.isBool = elemType.Kind() == reflect.Bool
+		plan.fields = append(plan.fields, bf)
+		plan.size += bf.count * int(bf.elemSize)
+	}
+	return plan
+}
+
+// putBits writes the low size bytes of the bits to the data in the order.
+func putBits(data []byte, order binary.ByteOrder, size uintptr, bits uint64) {
+	switch size {
+	case 1:
+		data[0] = uint8(bits)
+	case 2:
+		order.PutUint16(data, uint16(bits))
+	case 4:
+		order.PutUint32(data, uint32(bits))
+	default:
+		order.PutUint64(data, bits)
+	}
+}
+
+// getBits reads the size bytes from the data in the order, the inverse of putBits.
+func getBits(data []byte, order binary.ByteOrder, size uintptr) uint64 {
+	switch size {
+	case 1:
+		return uint64(data[0])
+	case 2:
+		return uint64(order.Uint16(data))
+	case 4:
+		return uint64(order.Uint32(data))
+	default:
+		return order.Uint64(data)
+	}
+}
@@ -0,0 +1,149 @@
+package fmap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type binaryHeader struct {
+	Version uint8
+	Flags   [2]uint16
+}
+
+type binaryPacket struct {
+	Header   binaryHeader
+	Valid    bool
+	Seq      int32
+	Offset   int64
+	Ratio    float32
+	Value    float64
+	Signal   complex64
+	Checksum uint64
+	Matrix   [2][2]int16
+}
+
+func newBinaryPacket() *binaryPacket {
+	return &binaryPacket{
+		Header:   binaryHeader{Version: 2, Flags: [2]uint16{0x0102, 0xfffe}},
+		Valid:    true,
+		Seq:      -42,
+		Offset:   1 << 40,
+		Ratio:    0.5,
+		Value:    -3.25,
+		Signal:   complex(1.5, -2),
+		Checksum: 0xdeadbeefcafebabe,
+		Matrix:   [2][2]int16{{1, -2}, {3, -4}},
+	}
+}
+
+func TestEncodeBinary(t *testing.T) {
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		t.Run(order.String(), func(t *testing.T) {
+			obj := newBinaryPacket()
+			data, err := EncodeBinary(obj, order)
+			assert.NoError(t, err)
+			var expected bytes.Buffer
+			assert.NoError(t, binary.Write(&expected, order, obj))
+			assert.Equal(t, expected.Bytes(), data)
+			assert.Equal(t, binary.Size(obj), len(data))
+
+			decoded := &binaryPacket{}
+			assert.NoError(t, DecodeBinary(decoded, data, order))
+			assert.Equal(t, obj, decoded)
+		})
+	}
+}
+
+type binaryEmbedded struct {
+	Port uint16
+}
+
+type binaryEmbeddedPacket struct {
+	ID uint32
+	*binaryEmbedded
+}
+
+func TestEncodeBinary_EmbeddedPtr(t *testing.T) {
+	data, err := EncodeBinary(&binaryEmbeddedPacket{ID: 1}, binary.BigEndian)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0, 0, 0, 1, 0, 0}, data)
+
+	decoded := &binaryEmbeddedPacket{}
+	assert.NoError(t, DecodeBinary(decoded, []byte{0, 0, 0, 1, 0x1f, 0x90}, binary.BigEndian))
+	assert.Equal(t, uint32(1), decoded.ID)
+	assert.NotNil(t, decoded.binaryEmbedded)
+	assert.Equal(t, uint16(8080), decoded.Port)
+}
+
+func TestDecodeBinary_Bool(t *testing.T) {
+	type testStruct struct {
+		A, B bool
+	}
+	obj := &testStruct{}
+	assert.NoError(t, DecodeBinary(obj, []byte{0, 7}, binary.LittleEndian))
+	assert.Equal(t, testStruct{A: false, B: true}, *obj)
+	data, _ := EncodeBinary(obj, binary.LittleEndian)
+	assert.Equal(t, []byte{0, 1}, data)
+}
+
+func TestEncodeBinary_Errors(t *testing.T) {
+	type withString struct {
+		ID   uint8
+		Name string
+	}
+	type withInt struct {
+		Count int
+	}
+	type withSlice struct {
+		Data []byte
+	}
+	_, err := EncodeBinary(&withString{}, binary.LittleEndian)
+	assert.EqualError(t, err, "fmap: field Name: not supported type: string, only fixed-size bool, integer, float, complex and arrays of them are supported")
+	_, err = EncodeBinary(&withInt{}, binary.LittleEndian)
+	assert.Error(t, err)
+	assert.Error(t, DecodeBinary(&withSlice{}, nil, binary.LittleEndian))
+
+	_, err = EncodeBinary(binaryPacket{}, binary.LittleEndian)
+	assert.Error(t, err)
+	_, err = EncodeBinary((*binaryPacket)(nil), binary.LittleEndian)
+	assert.Error(t, err)
+	assert.Error(t, DecodeBinary((*binaryPacket)(nil), nil, binary.LittleEndian))
+	assert.EqualError(t, DecodeBinary(&binaryHeader{}, []byte{1, 2}, binary.LittleEndian),
+		"fmap: binary: data length 2 doesn't match the fmap.binaryHeader size 5")
+}
+
+func BenchmarkEncodeBinary(b *testing.B) {
+	obj := newBinaryPacket()
+	b.Run("fmap", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = EncodeBinary(obj, binary.LittleEndian)
+		}
+	})
+	b.Run("encoding/binary", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var buf bytes.Buffer
+			_ = binary.Write(&buf, binary.LittleEndian, obj)
+		}
+	})
+}
+
+func BenchmarkDecodeBinary(b *testing.B) {
+	data, _ := EncodeBinary(newBinaryPacket(), binary.LittleEndian)
+	b.Run("fmap", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = DecodeBinary(&binaryPacket{}, data, binary.LittleEndian)
+		}
+	})
+	b.Run("encoding/binary", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = binary.Read(bytes.NewReader(data), binary.LittleEndian, &binaryPacket{})
+		}
+	})
+}
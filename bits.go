@@ -0,0 +1,71 @@
+package fmap
+
+import (
+	"fmt"
+	"reflect"
+	"unsafe"
+)
+
+// GetBit reports whether the bit, counting from the least significant 0, of the integer field in the provided
+// object is set, e.g. for the feature flags packed into the uint64 field.
+// It panics if the field is not an integer or if the bit is out of the field bit width.
+func (f *field) GetBit(obj any, bit int) bool {
+	f.checkBit(bit)
+	return loadBits(f.getReadPtr(obj), f.Type.Size())>>bit&1 == 1
+}
+
+// SetBit sets or clears the bit, counting from the least significant 0, of the integer field in the provided object.
+// The bit is updated with the plain read-modify-write, it's not atomic, so the concurrent SetBit calls
+// on the same field must be synchronized, e.g. with the SyncField, even for the different bits.
+// It panics if the field is not an integer or if the bit is out of the field bit width.
+func (f *field) SetBit(obj any, bit int, v bool) {
+	f.checkBit(bit)
+	ptr := f.getPtr(obj)
+	bits := loadBits(ptr, f.Type.Size())
+	if v {
+		bits |= 1 << bit
+	} else {
+		bits &^= 1 << bit
+	}
+	storeBits(ptr, f.Type.Size(), bits)
+}
+
+func (f *field) checkBit(bit int) {
+	switch f.Type.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+	default:
+		panic(fmt.Errorf("fmap: field %s: not supported type: %v, only integer is supported", f.structPath, f.Type))
+	}
+	if bit < 0 || bit >= f.Type.Bits() {
+		panic(fmt.Errorf("fmap: field %s: bit %d out of range [0, %d)", f.structPath, bit, f.Type.Bits()))
+	}
+}
+
+// loadBits returns the integer of the size at the ptr as the uint64, the signed integers as their two's complement.
+func loadBits(ptr unsafe.Pointer, size uintptr) uint64 {
+	switch size {
+	case 1:
+		return uint64(*(*uint8)(ptr))
+	case 2:
+		return uint64(*(*uint16)(ptr))
+	case 4:
+		return uint64(*(*uint32)(ptr))
+	default:
+		return *(*uint64)(ptr)
+	}
+}
+
+// storeBits stores the low size bytes of the bits to the integer of the size at the ptr.
+func storeBits(ptr unsafe.Pointer, size uintptr, bits uint64) {
+	switch size {
+	case 1:
+		*(*uint8)(ptr) = uint8(bits)
+	case 2:
+		*(*uint16)(ptr) = uint16(bits)
+	case 4:
+		*(*uint32)(ptr) = uint32(bits)
+	default:
+		*(*uint64)(ptr) = bits
+	}
+}
@@ -0,0 +1,73 @@
+package fmap
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestField_Bits(t *testing.T) {
+	type testStruct struct {
+		Flags  uint64
+		Small  uint8
+		Signed int16
+		Int    int
+		Bool   bool
+	}
+	fields, _ := Get[testStruct]()
+	flags := fields.MustFind("Flags")
+
+	t.Run("Get", func(t *testing.T) {
+		obj := &testStruct{Flags: 1<<63 | 1<<5 | 1, Signed: -1}
+		assert.True(t, flags.GetBit(obj, 0))
+		assert.False(t, flags.GetBit(obj, 1))
+		assert.True(t, flags.GetBit(obj, 5))
+		assert.True(t, flags.GetBit(obj, 63))
+		for bit := 0; bit < 16; bit++ {
+			assert.True(t, fields.MustFind("Signed").GetBit(obj, bit))
+		}
+	})
+	t.Run("Set", func(t *testing.T) {
+		obj := &testStruct{Small: 0xFF}
+		flags.SetBit(obj, 63, true)
+		flags.SetBit(obj, 2, true)
+		flags.SetBit(obj, 2, true)
+		assert.Equal(t, uint64(1<<63|1<<2), obj.Flags)
+		flags.SetBit(obj, 63, false)
+		assert.Equal(t, uint64(1<<2), obj.Flags)
+		fields.MustFind("Small").SetBit(obj, 7, false)
+		assert.Equal(t, uint8(0x7F), obj.Small)
+		fields.MustFind("Signed").SetBit(obj, 15, true)
+		assert.Equal(t, int16(-1<<15), obj.Signed)
+		fields.MustFind("Int").SetBit(obj, 1, true)
+		assert.Equal(t, 2, obj.Int)
+	})
+	t.Run("Errors", func(t *testing.T) {
+		obj := &testStruct{}
+		assert.PanicsWithError(t, "fmap: field Small: bit 8 out of range [0, 8)", func() {
+			fields.MustFind("Small").GetBit(obj, 8)
+		})
+		assert.Panics(t, func() { flags.SetBit(obj, -1, true) })
+		assert.Panics(t, func() { flags.SetBit(obj, 64, true) })
+		assert.PanicsWithError(t, "fmap: field Bool: not supported type: bool, only integer is supported", func() {
+			fields.MustFind("Bool").GetBit(obj, 0)
+		})
+		assert.Panics(t, func() { flags.GetBit(*obj, 0) })
+	})
+	t.Run("Sync", func(t *testing.T) {
+		obj := &testStruct{}
+		syncFlags := Synchronized(fields).MustFind("Flags")
+		wg := sync.WaitGroup{}
+		for bit := 0; bit < 64; bit++ {
+			wg.Add(1)
+			go func(bit int) {
+				defer wg.Done()
+				syncFlags.SetBit(obj, bit, true)
+				_ = syncFlags.GetBit(obj, bit)
+			}(bit)
+		}
+		wg.Wait()
+		assert.Equal(t, ^uint64(0), obj.Flags)
+	})
+}
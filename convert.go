@@ -0,0 +1,182 @@
+package fmap
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// SetConvert updates the value of the field in the provided object with the val converted to the field type.
+// Besides the assignable values it supports:
+//   - nil as the zero value;
+//   - the numeric, string and bool values of the different types with the same kind class,
+//     the numeric values must fit the field type without the loss, e.g. float64(2) to int;
+//   - the values for the pointer fields, the pointer is allocated, and the pointers for the value fields;
+//   - the slices and maps which elements are converted one by one, e.g. []any to []string;
+//   - the arrays to the slices and the slices to the arrays with the elements converted one by one,
+//     e.g. [4]int to []int, the slice must not be longer than the array, the rest of the array elements are zero.
+func (f *field) SetConvert(obj any, val any) error {
+	return f.SetReflectValueConvert(obj, reflect.ValueOf(val))
+}
+
+// SetReflectValueConvert is the SetConvert variant for the reflect.Value, the zero Value is converted to the zero value.
+func (f *field) SetReflectValueConvert(obj any, v reflect.Value) error {
+	ptr, err := f.tryGetPtr(obj, true)
+	if err != nil {
+		return err
+	}
+	converted, err := convertValue(v, f.Type)
+	if err != nil {
+		return fmt.Errorf("fmap: field %s: %w", f.structPath, err)
+	}
+	reflect.NewAt(f.Type, ptr).Elem().Set(converted)
+	return nil
+}
+
+// convertValue converts the val to the typeOf, see field.SetConvert for the supported conversions.
+func convertValue(val reflect.Value, typeOf reflect.Type) (reflect.Value, error) {
+	if !val.IsValid() {
+		return reflect.Zero(typeOf), nil
+	}
+	if val.Type().AssignableTo(typeOf) {
+		return val, nil
+	}
+	if val.Kind() == reflect.Interface || (val.Kind() == reflect.Ptr && typeOf.Kind() != reflect.Ptr) {
+		if val.IsNil() {
+			return reflect.Zero(typeOf), nil
+		}
+		return convertValue(val.Elem(), typeOf)
+	}
+	switch {
+	case typeOf.Kind() == reflect.Ptr:
+		elem, err := convertValue(val, typeOf.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		ptr := reflect.New(typeOf.Elem())
+		ptr.Elem().Set(elem)
+		return ptr, nil
+	case isNumberKind(val.Kind()) && isNumberKind(typeOf.Kind()):
+		return convertNumber(val, typeOf)
+	case val.Kind() == reflect.String && typeOf.Kind() == reflect.String,
+		val.Kind() == reflect.Bool && typeOf.Kind() == reflect.Bool:
+		return val.Convert(typeOf), nil
+	case (val.Kind() == reflect.Slice || val.Kind() == reflect.Array) && typeOf.Kind() == reflect.Slice:
+		if val.Kind() == reflect.Slice && val.IsNil() {
+			return reflect.Zero(typeOf), nil
+		}
+		slice := reflect.MakeSlice(typeOf, val.Len(), val.Len())
+		return slice, convertElems(slice, val)
+	case (val.Kind() == reflect.Slice || val.Kind() == reflect.Array) && typeOf.Kind() == reflect.Array:
+		if val.Len() > typeOf.Len() {
+			return reflect.Value{}, fmt.Errorf("can't convert value of type %v with length %d to %v", val.Type(), val.Len(), typeOf)
+		}
+		array := reflect.New(typeOf).Elem()
+		return array, convertElems(array, val)
+	case val.Kind() == reflect.Map && typeOf.Kind() == reflect.Map:
+		if val.IsNil() {
+			return reflect.Zero(typeOf), nil
+		}
+		m := reflect.MakeMapWithSize(typeOf, val.Len())
+		iter := val.MapRange()
+		for iter.Next() {
+			key, err := convertValue(iter.Key(), typeOf.Key())
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("key %v: %w", iter.Key(), err)
+			}
+			elem, err := convertValue(iter.Value(), typeOf.Elem())
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("key %v: %w", iter.Key(), err)
+			}
+			m.SetMapIndex(key, elem)
+		}
+		return m, nil
+	}
+	return reflect.Value{}, fmt.Errorf("can't convert value of type %v to %v", val.Type(), typeOf)
+}
+
+// convertElems converts the elements of the slice or array val to the elements of the dst with the same indexes,
+// the dst must be at least as long as the val.
+func convertElems(dst, val reflect.Value) error {
+	for i := 0; i < val.Len(); i++ {
+		elem, err := convertValue(val.Index(i), dst.Type().Elem())
+		if err != nil {
+			return fmt.Errorf("index %d: %w", i, err)
+		}
+		dst.Index(i).Set(elem)
+	}
+	return nil
+}
+
+func isNumberKind(kind reflect.Kind) bool {
+	return (kind >= reflect.Int && kind <= reflect.Uintptr) || kind == reflect.Float32 || kind == reflect.Float64
+}
+
+// convertNumber converts the numeric val to the numeric typeOf and checks that the value is not changed,
+// the float results are converted back and compared with the val, so the rounded values are rejected too.
+func convertNumber(val reflect.Value, typeOf reflect.Type) (reflect.Value, error) {
+	res := reflect.New(typeOf).Elem()
+	lossErr := fmt.Errorf("can't convert %v of type %v to %v without loss", val, val.Type(), typeOf)
+	switch {
+	case val.CanInt():
+		i := val.Int()
+		switch {
+		case res.CanInt():
+			if res.OverflowInt(i) {
+				return reflect.Value{}, lossErr
+			}
+			res.SetInt(i)
+		case res.CanUint():
+			if i < 0 || res.OverflowUint(uint64(i)) {
+				return reflect.Value{}, lossErr
+			}
+			res.SetUint(uint64(i))
+		default:
+			res.SetFloat(float64(i))
+			// the integers above 2^53 or 2^24 for float32 are rounded, the result is converted back to check it
+			if fl := res.Float(); fl >= math.MaxInt64 || int64(fl) != i {
+				return reflect.Value{}, lossErr
+			}
+		}
+	case val.CanUint():
+		u := val.Uint()
+		switch {
+		case res.CanInt():
+			if u > math.MaxInt64 || res.OverflowInt(int64(u)) {
+				return reflect.Value{}, lossErr
+			}
+			res.SetInt(int64(u))
+		case res.CanUint():
+			if res.OverflowUint(u) {
+				return reflect.Value{}, lossErr
+			}
+			res.SetUint(u)
+		default:
+			res.SetFloat(float64(u))
+			if fl := res.Float(); fl >= math.MaxUint64 || uint64(fl) != u {
+				return reflect.Value{}, lossErr
+			}
+		}
+	default:
+		fl := val.Float()
+		switch {
+		case res.CanInt():
+			if fl != math.Trunc(fl) || fl < math.MinInt64 || fl >= math.MaxInt64 || res.OverflowInt(int64(fl)) {
+				return reflect.Value{}, lossErr
+			}
+			res.SetInt(int64(fl))
+		case res.CanUint():
+			if fl != math.Trunc(fl) || fl < 0 || fl >= math.MaxUint64 || res.OverflowUint(uint64(fl)) {
+				return reflect.Value{}, lossErr
+			}
+			res.SetUint(uint64(fl))
+		default:
+			// float32 rounds both the too large and the too precise values, e.g. 0.1
+			res.SetFloat(fl)
+			if res.Float() != fl && !math.IsNaN(fl) {
+				return reflect.Value{}, lossErr
+			}
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,102 @@
+package fmap
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestField_SetConvert(t *testing.T) {
+	type Status string
+	type testStruct struct {
+		Int     int
+		Int8    int8
+		Uint    uint
+		Float32 float32
+		Float64 float64
+		Status  Status
+		Bool    bool
+		PtrInt  *int
+		Slice   []int
+		Array   [3]int
+		Map     map[string]float64
+		Any     any
+	}
+	fields, _ := Get[testStruct]()
+	obj := &testStruct{}
+	tests := []struct {
+		path    string
+		val     any
+		want    any
+		wantErr bool
+	}{
+		{path: "Int", val: float64(5), want: 5},
+		{path: "Int", val: uint8(7), want: 7},
+		{path: "Int", val: 5.5, wantErr: true},
+		{path: "Int", val: "5", wantErr: true},
+		{path: "Int8", val: 300, wantErr: true},
+		{path: "Int8", val: -5, want: int8(-5)},
+		{path: "Uint", val: -1, wantErr: true},
+		{path: "Uint", val: float64(3), want: uint(3)},
+		{path: "Float32", val: 1, want: float32(1)},
+		{path: "Float32", val: math.MaxFloat64, wantErr: true},
+		{path: "Float32", val: 0.1, wantErr: true},
+		{path: "Float32", val: 0.5, want: float32(0.5)},
+		{path: "Float32", val: 1<<24 + 1, wantErr: true},
+		{path: "Float32", val: 1 << 24, want: float32(1 << 24)},
+		{path: "Float64", val: int64(1<<53 + 1), wantErr: true},
+		{path: "Float64", val: int64(1 << 53), want: float64(1 << 53)},
+		{path: "Float64", val: int64(math.MaxInt64), wantErr: true},
+		{path: "Float64", val: int64(math.MinInt64), want: float64(math.MinInt64)},
+		{path: "Float64", val: uint64(math.MaxUint64), wantErr: true},
+		{path: "Float64", val: uint64(1 << 63), want: float64(1 << 63)},
+		{path: "Float64", val: float32(0.1), want: float64(float32(0.1))},
+		{path: "Status", val: "active", want: Status("active")},
+		{path: "Bool", val: true, want: true},
+		{path: "PtrInt", val: float64(2), want: intPtr(2)},
+		{path: "PtrInt", val: nil, want: (*int)(nil)},
+		{path: "Int", val: intPtr(9), want: 9},
+		{path: "Slice", val: []any{float64(1), 2}, want: []int{1, 2}},
+		{path: "Slice", val: []any{"a"}, wantErr: true},
+		{path: "Slice", val: [4]int{1, 2, 3, 4}, want: []int{1, 2, 3, 4}},
+		{path: "Slice", val: [2]float64{1, 2}, want: []int{1, 2}},
+		{path: "Slice", val: [1]float64{1.5}, wantErr: true},
+		{path: "Array", val: []int{1, 2, 3}, want: [3]int{1, 2, 3}},
+		{path: "Array", val: []any{float64(1)}, want: [3]int{1, 0, 0}},
+		{path: "Array", val: []int{1, 2, 3, 4}, wantErr: true},
+		{path: "Array", val: [2]int64{4, 5}, want: [3]int{4, 5, 0}},
+		{path: "Array", val: []string{"a"}, wantErr: true},
+		{path: "Map", val: map[string]any{"a": 1}, want: map[string]float64{"a": 1}},
+		{path: "Any", val: "test", want: "test"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			fld := fields.MustFind(tt.path)
+			err := fld.SetConvert(obj, tt.val)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, reflect.ValueOf(fld.GetPtr(obj)).Elem().Interface())
+		})
+	}
+	assert.Error(t, fields.MustFind("Int").SetConvert(*obj, 1))
+	assert.NoError(t, fields.MustFind("Float32").SetConvert(obj, math.NaN()))
+	assert.True(t, math.IsNaN(float64(obj.Float32)))
+
+	// the converted array doesn't share the memory with the slice and vice versa
+	src := []int{7, 8, 9}
+	assert.NoError(t, fields.MustFind("Array").SetConvert(obj, src))
+	src[0] = 0
+	assert.Equal(t, [3]int{7, 8, 9}, obj.Array)
+	assert.NoError(t, fields.MustFind("Slice").SetConvert(obj, obj.Array))
+	obj.Array[0] = 0
+	assert.Equal(t, []int{7, 8, 9}, obj.Slice)
+}
+
+func intPtr(i int) *int {
+	return &i
+}
@@ -0,0 +1,25 @@
+package fmap
+
+import "fmt"
+
+// SetCSVRecord populates the object pointed to by obj from the CSV record.
+// Each record value is parsed into the leaf field in the Storage.Columns(tag) order.
+// It returns an error if the record length doesn't match the columns count or if any value can't be parsed,
+// the error contains the name of the offending column and the path of its field.
+// The value is parsed before the nil embedded struct pointers on the way are allocated, see Field.SetFromString.
+func SetCSVRecord(obj any, record []string, tag string) error {
+	fields, err := getFromPtr(obj)
+	if err != nil {
+		return err
+	}
+	columns, columnFields := fields.columns(tag)
+	if len(record) != len(columns) {
+		return fmt.Errorf("fmap: csv: wrong record length: %d, expected %d columns", len(record), len(columns))
+	}
+	for i, fld := range columnFields {
+		if err = fld.SetFromString(obj, record[i]); err != nil {
+			return fmt.Errorf("fmap: csv: column %q: %w", columns[i], err)
+		}
+	}
+	return nil
+}
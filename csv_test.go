@@ -0,0 +1,55 @@
+package fmap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type csvAddress struct {
+	City string `csv:"city"`
+	Zip  *int   `csv:"zip"`
+}
+
+type csvPerson struct {
+	Name    string  `csv:"name"`
+	Age     uint8   `csv:"age"`
+	Score   float64 `csv:"score"`
+	Active  bool    `csv:"active"`
+	Note    string
+	Address csvAddress `csv:"address"`
+}
+
+func TestStorage_Columns(t *testing.T) {
+	fields, _ := Get[csvPerson]()
+	assert.Equal(t, []string{"name", "age", "score", "active", "address.city", "address.zip"}, fields.Columns("csv"))
+	assert.Equal(t, []string{"Name", "Age", "Score", "Active", "Note", "Address.City", "Address.Zip"}, fields.Columns(""))
+}
+
+func TestSetCSVRecord(t *testing.T) {
+	t.Run("Populate", func(t *testing.T) {
+		p := &csvPerson{}
+		err := SetCSVRecord(p, []string{"John", "42", "7.5", "true", "Paris", "0x10"}, "csv")
+		assert.NoError(t, err)
+		assert.Equal(t, "John", p.Name)
+		assert.Equal(t, uint8(42), p.Age)
+		assert.Equal(t, 7.5, p.Score)
+		assert.Equal(t, true, p.Active)
+		assert.Equal(t, "Paris", p.Address.City)
+		assert.Equal(t, 16, *p.Address.Zip)
+	})
+	t.Run("ParseError", func(t *testing.T) {
+		p := &csvPerson{}
+		err := SetCSVRecord(p, []string{"John", "300", "7.5", "true", "Paris", "1"}, "csv")
+		assert.EqualError(t, err, `fmap: csv: column "age": fmap: field Age: strconv.ParseUint: parsing "300": value out of range`)
+	})
+	t.Run("LengthMismatch", func(t *testing.T) {
+		p := &csvPerson{}
+		err := SetCSVRecord(p, []string{"John"}, "csv")
+		assert.EqualError(t, err, "fmap: csv: wrong record length: 1, expected 6 columns")
+	})
+	t.Run("NotAPointer", func(t *testing.T) {
+		err := SetCSVRecord(csvPerson{}, []string{}, "csv")
+		assert.EqualError(t, err, "fmap: not supported type: fmap.csvPerson, only ptr to struct is supported")
+	})
+}
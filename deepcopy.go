@@ -0,0 +1,212 @@
+package fmap
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"sync"
+)
+
+// DeepCopy returns the pointer to the new struct that is the deep copy of the struct pointed to by src.
+// The pointers, slices, maps, arrays, interfaces and nested structs are copied recursively, so the copy
+// doesn't share any mutable memory with the src, the pointer cycles and the shared pointers are preserved.
+// The big.Int, big.Float and big.Rat values are copied with their Set methods, as their internals are unexported.
+// The other unexported struct fields, e.g. the time.Time internals, the funcs and the chans are copied as is.
+func DeepCopy(src any) (any, error) {
+	if _, err := getFromPtr(src); err != nil {
+		return nil, err
+	}
+	srcVal := reflect.ValueOf(src)
+	if srcVal.IsNil() {
+		return nil, fmt.Errorf("fmap: can't copy the nil %v", srcVal.Type())
+	}
+	return deepCopy(srcVal).Interface(), nil
+}
+
+// copyPools caches the *sync.Pool of the zeroed copies of the ptr to struct types used by DeepCopyPooled.
+var copyPools sync.Map
+
+// DeepCopyPooled is the DeepCopy variant that takes the copied struct from the per-type sync.Pool, e.g. for
+// the request-scoped snapshots in the hot path. The returned release func zeroes the copy and puts it back
+// to the pool, so the copy must not be used after the release, it can be called more than once.
+// Only the top-level struct is pooled, the nested pointers, slices and maps of the copy are allocated as in DeepCopy.
+// It panics if the src is not a non-nil pointer to struct.
+func DeepCopyPooled(src any) (any, func()) {
+	if _, err := getFromPtr(src); err != nil {
+		panic(err)
+	}
+	srcVal := reflect.ValueOf(src)
+	if srcVal.IsNil() {
+		panic(fmt.Errorf("fmap: can't copy the nil %v", srcVal.Type()))
+	}
+	pool := getCopyPool(srcVal.Type())
+	dst := reflect.ValueOf(pool.Get())
+	deepCopyTo(dst, srcVal)
+	var once sync.Once
+	return dst.Interface(), func() {
+		once.Do(func() {
+			// the released copy is zeroed to not leak its data to the next user
+			dst.Elem().Set(reflect.Zero(dst.Type().Elem()))
+			pool.Put(dst.Interface())
+		})
+	}
+}
+
+func getCopyPool(typeOf reflect.Type) *sync.Pool {
+	if pool, ok := copyPools.Load(typeOf); ok {
+		return pool.(*sync.Pool)
+	}
+	pool, _ := copyPools.LoadOrStore(typeOf, &sync.Pool{New: func() any {
+		return reflect.New(typeOf.Elem()).Interface()
+	}})
+	return pool.(*sync.Pool)
+}
+
+// deepCopy returns the deep copy of the non-nil ptr to struct srcVal.
+func deepCopy(srcVal reflect.Value) reflect.Value {
+	dst := reflect.New(srcVal.Type().Elem())
+	deepCopyTo(dst, srcVal)
+	return dst
+}
+
+// deepCopyTo copies the non-nil ptr to struct srcVal deeply to the zeroed struct pointed to by dst.
+func deepCopyTo(dst, srcVal reflect.Value) {
+	c := &copier{visited: map[visit]reflect.Value{{srcVal.Pointer(), srcVal.Type()}: dst}}
+	c.copy(dst.Elem(), srcVal.Elem())
+}
+
+// visit is the already copied pointer or map.
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+type copier struct {
+	visited map[visit]reflect.Value
+}
+
+// copy sets the deep copy of the src to the settable zero dst of the same type.
+func (c *copier) copy(dst, src reflect.Value) {
+	if !typeHasPointers(src.Type()) {
+		dst.Set(src)
+		return
+	}
+	switch src.Kind() {
+	case reflect.Ptr:
+		if src.IsNil() {
+			return
+		}
+		key := visit{src.Pointer(), src.Type()}
+		if copied, ok := c.visited[key]; ok {
+			dst.Set(copied)
+			return
+		}
+		ptr := reflect.New(src.Type().Elem())
+		c.visited[key] = ptr
+		c.copy(ptr.Elem(), src.Elem())
+		dst.Set(ptr)
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+		slice := reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			c.copy(slice.Index(i), src.Index(i))
+		}
+		dst.Set(slice)
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		key := visit{src.Pointer(), src.Type()}
+		if copied, ok := c.visited[key]; ok {
+			dst.Set(copied)
+			return
+		}
+		m := reflect.MakeMapWithSize(src.Type(), src.Len())
+		c.visited[key] = m
+		iter := src.MapRange()
+		for iter.Next() {
+			val := reflect.New(src.Type().Elem()).Elem()
+			c.copy(val, iter.Value())
+			m.SetMapIndex(iter.Key(), val)
+		}
+		dst.Set(m)
+	case reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			c.copy(dst.Index(i), src.Index(i))
+		}
+	case reflect.Interface:
+		if src.IsNil() {
+			return
+		}
+		val := reflect.New(src.Elem().Type()).Elem()
+		c.copy(val, src.Elem())
+		dst.Set(val)
+	case reflect.Struct:
+		if copyBig(dst, src) {
+			return
+		}
+		// the unexported fields can't be copied field by field, they are copied as is
+		dst.Set(src)
+		for i := 0; i < src.NumField(); i++ {
+			if src.Type().Field(i).IsExported() {
+				c.copy(dst.Field(i), src.Field(i))
+			}
+		}
+	default:
+		dst.Set(src)
+	}
+}
+
+// copyBig copies the math/big numbers with their Set methods and reports whether the src is the one.
+func copyBig(dst, src reflect.Value) bool {
+	if src.Type().PkgPath() != "math/big" {
+		return false
+	}
+	// the dst may share the internals with the src, e.g. after the parent struct shallow copy
+	dst.Set(reflect.Zero(dst.Type()))
+	switch src := src.Interface().(type) {
+	case big.Int:
+		dst.Addr().Interface().(*big.Int).Set(&src)
+	case big.Float:
+		dst.Addr().Interface().(*big.Float).Set(&src)
+	case big.Rat:
+		dst.Addr().Interface().(*big.Rat).Set(&src)
+	default:
+		return false
+	}
+	return true
+}
+
+// Snapshot captures the deep copy of the struct pointed to by obj and returns the restore func writing
+// the captured values of all top-level fields of the field map back to the obj, e.g. to roll back the global
+// config mutated in the test. The restore writes the fresh copy every time, so it can be called more than once.
+// The unexported fields, which are not in the field map, are left as is.
+func Snapshot(obj any) (func(), error) {
+	fields, err := getFromPtr(obj)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := DeepCopy(obj)
+	if err != nil {
+		return nil, err
+	}
+	var top []*field
+	for _, path := range fields.paths {
+		if fld := fields.asMap[path].(*field); fld.parent == nil {
+			top = append(top, fld)
+		}
+	}
+	return func() {
+		snapshotVal := reflect.ValueOf(snapshot)
+		// the pointers to the snapshot root are restored as the pointers to the obj
+		c := &copier{visited: map[visit]reflect.Value{{snapshotVal.Pointer(), snapshotVal.Type()}: reflect.ValueOf(obj)}}
+		for _, fld := range top {
+			// the read pointers bypass the read-only check of the embedded unexported structs
+			dst := reflect.NewAt(fld.Type, fld.getReadPtr(obj)).Elem()
+			dst.Set(reflect.Zero(fld.Type))
+			c.copy(dst, reflect.NewAt(fld.Type, fld.getReadPtr(snapshot)).Elem())
+		}
+	}, nil
+}
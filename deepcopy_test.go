@@ -0,0 +1,218 @@
+package fmap
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type copyNode struct {
+	Value int
+	Next  *copyNode
+}
+
+type copyConfig struct {
+	Name     string
+	Port     *int
+	Tags     []string
+	Limits   map[string][]int
+	Matrix   [2][]int
+	Any      any
+	Nested   mapAddress
+	Nodes    []*copyNode
+	Self     *copyConfig
+	Deadline time.Time
+	secret   []byte
+}
+
+func newCopyConfig() *copyConfig {
+	port := 80
+	shared := &copyNode{Value: 1}
+	shared.Next = shared
+	cfg := &copyConfig{
+		Name:     "app",
+		Port:     &port,
+		Tags:     []string{"a", "b"},
+		Limits:   map[string][]int{"rps": {1, 2}},
+		Matrix:   [2][]int{{1}, {2}},
+		Any:      []int{3},
+		Nested:   mapAddress{City: "Paris"},
+		Nodes:    []*copyNode{shared, shared},
+		Deadline: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		secret:   []byte("secret"),
+	}
+	cfg.Self = cfg
+	return cfg
+}
+
+func TestDeepCopy(t *testing.T) {
+	t.Run("Copy", func(t *testing.T) {
+		src := newCopyConfig()
+		copied, err := DeepCopy(src)
+		assert.NoError(t, err)
+		dst := copied.(*copyConfig)
+		assert.Equal(t, src.Name, dst.Name)
+		assert.Equal(t, *src.Port, *dst.Port)
+		assert.NotSame(t, src.Port, dst.Port)
+		assert.Equal(t, src.Tags, dst.Tags)
+		assert.Equal(t, src.Limits, dst.Limits)
+		assert.Equal(t, src.Matrix, dst.Matrix)
+		assert.Equal(t, src.Any, dst.Any)
+		assert.Equal(t, src.Nested, dst.Nested)
+		assert.True(t, src.Deadline.Equal(dst.Deadline))
+		assert.Same(t, dst, dst.Self)
+		assert.Same(t, dst.Nodes[0], dst.Nodes[1])
+		assert.Same(t, dst.Nodes[0], dst.Nodes[0].Next)
+		assert.NotSame(t, src.Nodes[0], dst.Nodes[0])
+
+		src.Tags[0] = "changed"
+		src.Limits["rps"][0] = 100
+		src.Matrix[0][0] = 100
+		src.Any.([]int)[0] = 100
+		*src.Port = 8080
+		src.Nodes[0].Value = 100
+		assert.Equal(t, []string{"a", "b"}, dst.Tags)
+		assert.Equal(t, []int{1, 2}, dst.Limits["rps"])
+		assert.Equal(t, 1, dst.Matrix[0][0])
+		assert.Equal(t, []int{3}, dst.Any)
+		assert.Equal(t, 80, *dst.Port)
+		assert.Equal(t, 1, dst.Nodes[0].Value)
+		// the unexported fields are copied as is
+		assert.Equal(t, src.secret, dst.secret)
+	})
+	t.Run("Zero", func(t *testing.T) {
+		copied, err := DeepCopy(&copyConfig{})
+		assert.NoError(t, err)
+		assert.Equal(t, &copyConfig{}, copied)
+	})
+	t.Run("Errors", func(t *testing.T) {
+		_, err := DeepCopy(copyConfig{})
+		assert.Error(t, err)
+		_, err = DeepCopy((*copyConfig)(nil))
+		assert.Error(t, err)
+	})
+}
+
+func TestDeepCopyPooled(t *testing.T) {
+	src := newCopyConfig()
+	copied, release := DeepCopyPooled(src)
+	dst := copied.(*copyConfig)
+	assert.NotSame(t, src, dst)
+	assert.Equal(t, src.Tags, dst.Tags)
+	assert.Equal(t, *src.Port, *dst.Port)
+	assert.NotSame(t, src.Port, dst.Port)
+	assert.Same(t, dst, dst.Self)
+
+	release()
+	// the released copy is zeroed, the second release is a no-op
+	assert.Equal(t, copyConfig{}, *dst)
+	release()
+
+	reused, releaseReused := DeepCopyPooled(&copyConfig{Name: "other"})
+	defer releaseReused()
+	assert.Equal(t, &copyConfig{Name: "other"}, reused)
+
+	assert.Panics(t, func() { DeepCopyPooled(copyConfig{}) })
+	assert.Panics(t, func() { DeepCopyPooled((*copyConfig)(nil)) })
+}
+
+func BenchmarkDeepCopyPooled(b *testing.B) {
+	src := newCopyConfig()
+	b.Run("DeepCopy", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = DeepCopy(src)
+		}
+	})
+	b.Run("DeepCopyPooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, release := DeepCopyPooled(src)
+			release()
+		}
+	})
+}
+
+func TestSnapshot(t *testing.T) {
+	cfg := newCopyConfig()
+	restore, err := Snapshot(cfg)
+	assert.NoError(t, err)
+
+	mutate := func() {
+		cfg.Name = "changed"
+		*cfg.Port = 8080
+		cfg.Tags[0] = "changed"
+		cfg.Tags = append(cfg.Tags, "c")
+		cfg.Limits["rps"][0] = 100
+		cfg.Limits["new"] = nil
+		cfg.Nested.City = "Rome"
+		cfg.Nodes = nil
+		cfg.Self = nil
+		cfg.Any = nil
+		cfg.Deadline = time.Now()
+	}
+	check := func() {
+		assert.Equal(t, "app", cfg.Name)
+		assert.Equal(t, 80, *cfg.Port)
+		assert.Equal(t, []string{"a", "b"}, cfg.Tags)
+		assert.Equal(t, map[string][]int{"rps": {1, 2}}, cfg.Limits)
+		assert.Equal(t, "Paris", cfg.Nested.City)
+		assert.Len(t, cfg.Nodes, 2)
+		assert.Same(t, cfg.Nodes[0], cfg.Nodes[1])
+		assert.Same(t, cfg, cfg.Self)
+		assert.Equal(t, []int{3}, cfg.Any)
+		assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), cfg.Deadline)
+	}
+	mutate()
+	restore()
+	check()
+	// the restored values don't share memory with the snapshot
+	mutate()
+	restore()
+	check()
+
+	empty := &copyConfig{}
+	restore, err = Snapshot(empty)
+	assert.NoError(t, err)
+	*empty = *newCopyConfig()
+	restore()
+	assert.Equal(t, &copyConfig{secret: empty.secret}, empty)
+
+	_, err = Snapshot(copyConfig{})
+	assert.Error(t, err)
+}
+
+func TestDeepCopy_Big(t *testing.T) {
+	type bigStruct struct {
+		Int      *big.Int
+		Float    *big.Float
+		Rat      *big.Rat
+		IntValue big.Int
+		Ints     []*big.Int
+	}
+	src := &bigStruct{
+		Int:   big.NewInt(100),
+		Float: big.NewFloat(1.5),
+		Rat:   big.NewRat(1, 3),
+		Ints:  []*big.Int{big.NewInt(1)},
+	}
+	src.IntValue.SetInt64(200)
+	copied, err := DeepCopy(src)
+	assert.NoError(t, err)
+	dst := copied.(*bigStruct)
+	assert.NotSame(t, src.Int, dst.Int)
+	assert.NotSame(t, src.Ints[0], dst.Ints[0])
+
+	src.Int.SetInt64(-1)
+	src.Float.SetFloat64(-1)
+	src.Rat.SetInt64(-1)
+	src.IntValue.SetInt64(-1)
+	src.Ints[0].SetInt64(-1)
+	assert.Equal(t, "100", dst.Int.String())
+	assert.Equal(t, "1.5", dst.Float.String())
+	assert.Equal(t, "1/3", dst.Rat.String())
+	assert.Equal(t, "200", dst.IntValue.String())
+	assert.Equal(t, "1", dst.Ints[0].String())
+}
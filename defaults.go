@@ -0,0 +1,55 @@
+package fmap
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// DefaultTag is the tag holding the default value of the field applied by ApplyDefaults.
+const DefaultTag = "default"
+
+// DefaultsError is returned by ApplyDefaults when some default tag values can't be parsed.
+// The Errors are ordered like the fields in GetAllPaths.
+type DefaultsError struct {
+	Errors []error
+}
+
+func (e *DefaultsError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// ApplyDefaults sets the fields of the object pointed to by obj which have the default tag, e.g. `default:"5s"`,
+// from the tag value with Field.SetFromString, so the same types are supported: the primitives, time.Duration,
+// the encoding.TextUnmarshaler types and pointers to them. Only the zero fields are set, see Field.IsZero,
+// so the values already set, e.g. loaded from the config file, are kept. The nested struct fields are covered too,
+// the nil embedded struct pointers on the way to the zero fields with the default tag are allocated.
+// The parse errors don't stop applying the other defaults and are returned as the *DefaultsError.
+func ApplyDefaults(obj any) error {
+	fields, err := getFromPtr(obj)
+	if err != nil {
+		return err
+	}
+	if objPointer(obj) == nil {
+		return fmt.Errorf("fmap: defaults: can't apply to the nil %v", reflect.TypeOf(obj))
+	}
+	var errs []error
+	for _, path := range fields.paths {
+		fld := fields.asMap[path].(*field)
+		def, ok := fld.Tag.Lookup(DefaultTag)
+		if !ok || !fld.IsZero(obj) {
+			continue
+		}
+		if err = fld.SetFromString(obj, def); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return &DefaultsError{Errors: errs}
+	}
+	return nil
+}
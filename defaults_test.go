@@ -0,0 +1,72 @@
+package fmap
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type defaultsDatabase struct {
+	Host string `default:"localhost"`
+	Port int    `default:"5432"`
+}
+
+type defaultsConfig struct {
+	Name     string        `default:"app"`
+	Debug    bool          `default:"true"`
+	Ratio    *float64      `default:"0.5"`
+	Timeout  time.Duration `default:"5s"`
+	IP       net.IP        `default:"127.0.0.1"`
+	Retries  uint8         `default:"3"`
+	Plain    string
+	Database defaultsDatabase
+	*defaultsEmbedded
+}
+
+type defaultsEmbedded struct {
+	Region string `default:"eu"`
+}
+
+func TestApplyDefaults(t *testing.T) {
+	t.Run("Zero", func(t *testing.T) {
+		cfg := &defaultsConfig{}
+		assert.NoError(t, ApplyDefaults(cfg))
+		assert.Equal(t, "app", cfg.Name)
+		assert.True(t, cfg.Debug)
+		assert.Equal(t, 0.5, *cfg.Ratio)
+		assert.Equal(t, 5*time.Second, cfg.Timeout)
+		assert.Equal(t, net.ParseIP("127.0.0.1"), cfg.IP)
+		assert.Equal(t, uint8(3), cfg.Retries)
+		assert.Empty(t, cfg.Plain)
+		assert.Equal(t, defaultsDatabase{Host: "localhost", Port: 5432}, cfg.Database)
+		assert.Equal(t, "eu", cfg.Region)
+	})
+	t.Run("KeepsSet", func(t *testing.T) {
+		ratio := 0.0
+		cfg := &defaultsConfig{Name: "custom", Ratio: &ratio, Database: defaultsDatabase{Port: 1}}
+		assert.NoError(t, ApplyDefaults(cfg))
+		assert.Equal(t, "custom", cfg.Name)
+		assert.Same(t, &ratio, cfg.Ratio)
+		assert.Equal(t, defaultsDatabase{Host: "localhost", Port: 1}, cfg.Database)
+	})
+	t.Run("Errors", func(t *testing.T) {
+		type invalid struct {
+			Count   int           `default:"many"`
+			Name    string        `default:"ok"`
+			Timeout time.Duration `default:"soon"`
+		}
+		obj := &invalid{}
+		err := ApplyDefaults(obj)
+		var defaultsErr *DefaultsError
+		assert.ErrorAs(t, err, &defaultsErr)
+		assert.Len(t, defaultsErr.Errors, 2)
+		assert.Contains(t, defaultsErr.Errors[0].Error(), "fmap: field Count:")
+		assert.Contains(t, defaultsErr.Errors[1].Error(), "fmap: field Timeout:")
+		assert.Equal(t, "ok", obj.Name)
+
+		assert.Error(t, ApplyDefaults(defaultsConfig{}))
+		assert.Error(t, ApplyDefaults((*defaultsConfig)(nil)))
+	})
+}
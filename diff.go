@@ -0,0 +1,53 @@
+package fmap
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// DiffByTag compares the objects pointed to by a and b, which must be of the same type, and returns the old (a)
+// and new (b) values of the differing leaf fields keyed by the field tag paths, like ToMap keys them,
+// e.g. the audit diff in terms of the JSON names. The fields without the tag are skipped, the missing parent tags
+// are ignored. The primitives are compared with ==, the composite values with reflect.DeepEqual, see Field.Equal.
+// The result is the map, so it's unordered, sort the keys for the stable output.
+// The empty values of the fields with the omitempty tag option are absent in the ToMap output, they are represented
+// as nil in the diff, and the fields absent in both objects are not reported even if they differ, e.g. the nil and
+// the empty slice.
+func DiffByTag(a, b any, tag string) (map[string][2]any, error) {
+	typeA, typeB := reflect.TypeOf(a), reflect.TypeOf(b)
+	if typeA != typeB {
+		return nil, fmt.Errorf("fmap: diff: b type %v doesn't match a type %v", typeB, typeA)
+	}
+	fields, err := getFromPtr(a)
+	if err != nil {
+		return nil, err
+	}
+	if objPointer(a) == nil || objPointer(b) == nil {
+		return nil, fmt.Errorf("fmap: diff: not supported nil %v, only non-nil ptr to struct is supported", typeA)
+	}
+	diff := map[string][2]any{}
+	for _, path := range fields.paths {
+		fld := fields.asMap[path].(*field)
+		if fld.hasChildren {
+			continue
+		}
+		tagPath := fld.GetTagPath(tag, true)
+		if tagPath == "" || fld.Equal(a, b) {
+			continue
+		}
+		oldVal, oldOk := tagValue(fld, a, tag)
+		newVal, newOk := tagValue(fld, b, tag)
+		if oldOk || newOk {
+			diff[tagPath] = [2]any{oldVal, newVal}
+		}
+	}
+	return diff, nil
+}
+
+// tagValue returns the field value in the obj and false if the value is omitted by the omitempty tag option.
+func tagValue(fld *field, obj any, tag string) (any, bool) {
+	if fld.HasTagOption(tag, "omitempty") && isEmptyJSON(fld.Type, fld.getReadPtr(obj)) {
+		return nil, false
+	}
+	return fld.Get(obj), true
+}
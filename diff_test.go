@@ -0,0 +1,52 @@
+package fmap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type diffUser struct {
+	Name    string            `json:"name"`
+	Age     *int              `json:"age,omitempty"`
+	Tags    []string          `json:"tags,omitempty"`
+	Meta    map[string]string `json:"meta"`
+	Secret  string
+	Address mapAddress `json:"address"`
+}
+
+func TestDiffByTag(t *testing.T) {
+	t.Run("Diff", func(t *testing.T) {
+		age := 30
+		a := &diffUser{Name: "John", Tags: []string{"a"}, Meta: map[string]string{"k": "v"}, Secret: "a", Address: mapAddress{City: "Paris"}}
+		b := &diffUser{Name: "Jane", Age: &age, Meta: map[string]string{"k": "v"}, Secret: "b", Address: mapAddress{City: "Rome"}}
+		diff, err := DiffByTag(a, b, "json")
+		assert.NoError(t, err)
+		assert.Equal(t, map[string][2]any{
+			"name":         {"John", "Jane"},
+			"age":          {nil, &age},
+			"tags":         {[]string{"a"}, nil},
+			"address.city": {"Paris", "Rome"},
+		}, diff)
+	})
+	t.Run("Equal", func(t *testing.T) {
+		a := &diffUser{Name: "John", Meta: map[string]string{"k": "v"}}
+		b := &diffUser{Name: "John", Meta: map[string]string{"k": "v"}, Tags: []string{}}
+		diff, err := DiffByTag(a, b, "json")
+		assert.NoError(t, err)
+		assert.Empty(t, diff)
+	})
+	t.Run("NilAndEmpty", func(t *testing.T) {
+		diff, err := DiffByTag(&diffUser{}, &diffUser{Meta: map[string]string{}}, "json")
+		assert.NoError(t, err)
+		assert.Equal(t, map[string][2]any{"meta": {map[string]string(nil), map[string]string{}}}, diff)
+	})
+	t.Run("Errors", func(t *testing.T) {
+		_, err := DiffByTag(&diffUser{}, &mapUser{}, "json")
+		assert.Error(t, err)
+		_, err = DiffByTag(diffUser{}, diffUser{}, "json")
+		assert.Error(t, err)
+		_, err = DiffByTag(&diffUser{}, (*diffUser)(nil), "json")
+		assert.Error(t, err)
+	})
+}
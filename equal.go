@@ -0,0 +1,204 @@
+package fmap
+
+import (
+	"reflect"
+	"sync"
+	"unsafe"
+)
+
+// Equal reports whether the field values in the a and b objects are equal.
+// The primitive kinds are compared in place with ==, without boxing the values into any, the pointers, slices, arrays
+// and structs are followed in place, and the maps and interfaces are compared with reflect.DeepEqual, so the result
+// is the reflect.DeepEqual one, e.g. the pointers are equal if both are nil or they point to the deeply equal values.
+// It panics if the a or b is not a non-nil pointer to the field owner struct.
+func (f *field) Equal(a, b any) bool {
+	return equalAt(f.Type, f.getReadPtr(a), f.getReadPtr(b))
+}
+
+// maxEqualDepth is the number of the pointers and slices equalAt follows before falling back to reflect.DeepEqual,
+// which detects the cycles.
+const maxEqualDepth = 16
+
+// equalAt reports whether the typ values stored at the ptrA and ptrB are equal like Field.Equal defines it.
+func equalAt(typ reflect.Type, ptrA, ptrB unsafe.Pointer) bool {
+	return equalAtDepth(typ, ptrA, ptrB, 0)
+}
+
+// equalAtDepth is equalAt which follows the pointers, slices, arrays and structs in place the way reflect.DeepEqual does,
+// without boxing the values and allocating the cycles map, up to the maxEqualDepth pointers and slices.
+func equalAtDepth(typ reflect.Type, ptrA, ptrB unsafe.Pointer, depth int) bool {
+	switch typ.Kind() {
+	case reflect.Bool:
+		return equalPtr[bool](ptrA, ptrB)
+	case reflect.Int:
+		return equalPtr[int](ptrA, ptrB)
+	case reflect.Int8:
+		return equalPtr[int8](ptrA, ptrB)
+	case reflect.Int16:
+		return equalPtr[int16](ptrA, ptrB)
+	case reflect.Int32:
+		return equalPtr[int32](ptrA, ptrB)
+	case reflect.Int64:
+		return equalPtr[int64](ptrA, ptrB)
+	case reflect.Uint:
+		return equalPtr[uint](ptrA, ptrB)
+	case reflect.Uint8:
+		return equalPtr[uint8](ptrA, ptrB)
+	case reflect.Uint16:
+		return equalPtr[uint16](ptrA, ptrB)
+	case reflect.Uint32:
+		return equalPtr[uint32](ptrA, ptrB)
+	case reflect.Uint64:
+		return equalPtr[uint64](ptrA, ptrB)
+	case reflect.Uintptr:
+		return equalPtr[uintptr](ptrA, ptrB)
+	case reflect.Float32:
+		return equalPtr[float32](ptrA, ptrB)
+	case reflect.Float64:
+		return equalPtr[float64](ptrA, ptrB)
+	case reflect.Complex64:
+		return equalPtr[complex64](ptrA, ptrB)
+	case reflect.Complex128:
+		return equalPtr[complex128](ptrA, ptrB)
+	case reflect.String:
+		return equalPtr[string](ptrA, ptrB)
+	case reflect.Ptr:
+		elemA, elemB := *(*unsafe.Pointer)(ptrA), *(*unsafe.Pointer)(ptrB)
+		if elemA == elemB {
+			return true
+		}
+		if elemA == nil || elemB == nil {
+			return false
+		}
+		if depth < maxEqualDepth {
+			return equalAtDepth(typ.Elem(), elemA, elemB, depth+1)
+		}
+	case reflect.Array:
+		elemType, elemSize := typ.Elem(), typ.Elem().Size()
+		for i := 0; i < typ.Len(); i++ {
+			offset := uintptr(i) * elemSize
+			if !equalAtDepth(elemType, unsafe.Add(ptrA, offset), unsafe.Add(ptrB, offset), depth) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		for i := 0; i < typ.NumField(); i++ {
+			fld := typ.Field(i)
+			if !equalAtDepth(fld.Type, unsafe.Add(ptrA, fld.Offset), unsafe.Add(ptrB, fld.Offset), depth) {
+				return false
+			}
+		}
+		return true
+	case reflect.Map:
+		mapA, mapB := *(*unsafe.Pointer)(ptrA), *(*unsafe.Pointer)(ptrB)
+		if mapA == mapB {
+			return true
+		}
+		if mapA == nil || mapB == nil {
+			return false
+		}
+	case reflect.Interface:
+		ifaceA, ifaceB := reflect.NewAt(typ, ptrA).Elem(), reflect.NewAt(typ, ptrB).Elem()
+		if ifaceA.IsNil() || ifaceB.IsNil() {
+			return ifaceA.IsNil() == ifaceB.IsNil()
+		}
+	case reflect.Slice:
+		sliceA, sliceB := reflect.NewAt(typ, ptrA).Elem(), reflect.NewAt(typ, ptrB).Elem()
+		if sliceA.IsNil() != sliceB.IsNil() || sliceA.Len() != sliceB.Len() {
+			return false
+		}
+		dataA, dataB := sliceA.UnsafePointer(), sliceB.UnsafePointer()
+		if dataA == dataB {
+			return true
+		}
+		if depth < maxEqualDepth {
+			elemType, elemSize := typ.Elem(), typ.Elem().Size()
+			for i := 0; i < sliceA.Len(); i++ {
+				offset := uintptr(i) * elemSize
+				if !equalAtDepth(elemType, unsafe.Add(dataA, offset), unsafe.Add(dataB, offset), depth+1) {
+					return false
+				}
+			}
+			return true
+		}
+	}
+	// the pointers to the values are compared to not box the values, the pointed values are compared deeply
+	return reflect.DeepEqual(reflect.NewAt(typ, ptrA).Interface(), reflect.NewAt(typ, ptrB).Interface())
+}
+
+func equalPtr[T comparable](a, b unsafe.Pointer) bool {
+	return *(*T)(a) == *(*T)(b)
+}
+
+// Equal reports whether the a and b structs or pointers to structs of the same type are equal field by field,
+// the faster alternative to reflect.DeepEqual for the structs with many primitive fields.
+// The leaf fields, including the unexported ones, are compared in the declaration order like Field.Equal does,
+// the primitives in place, the composites with reflect.DeepEqual, and the comparison stops on the first difference.
+// The embedded struct pointers are equal if both are nil or both point to the equal structs.
+// It returns false if the a and b types differ and true if both are nil pointers.
+func Equal(a, b any) bool {
+	typeA, typeB := reflect.TypeOf(a), reflect.TypeOf(b)
+	if typeA != typeB {
+		return false
+	}
+	typeOf, err := checkStructType(typeA)
+	if err != nil {
+		return false
+	}
+	rootA, rootB := structPointer(a), structPointer(b)
+	if rootA == nil || rootB == nil {
+		return rootA == rootB
+	}
+	for _, fld := range getEqualPlan(typeOf) {
+		baseA, baseB := fld.basePtr(rootA, false), fld.basePtr(rootB, false)
+		if baseA == nil || baseB == nil {
+			// both embedded pointers on the way are nil, otherwise the comparison stopped on them
+			continue
+		}
+		ptrA, ptrB := unsafe.Add(baseA, fld.Offset), unsafe.Add(baseB, fld.Offset)
+		if fld.hasChildren {
+			// the embedded struct pointer, the pointed structs are compared by its children
+			if (*(*unsafe.Pointer)(ptrA) == nil) != (*(*unsafe.Pointer)(ptrB) == nil) {
+				return false
+			}
+			continue
+		}
+		if !equalAt(fld.Type, ptrA, ptrB) {
+			return false
+		}
+	}
+	return true
+}
+
+// equalPlans caches the fields compared by Equal of the ptr to struct types.
+var equalPlans sync.Map
+
+// getEqualPlan returns the leaf fields, including the unexported ones, and the embedded struct pointers
+// of the ptr to struct typeOf in the declaration order.
+func getEqualPlan(typeOf reflect.Type) []*field {
+	if plan, ok := equalPlans.Load(typeOf); ok {
+		return plan.([]*field)
+	}
+	fields := newStorage(typeOf, Options{IncludeUnexported: true})
+	plan := make([]*field, 0, len(fields.paths))
+	for _, path := range fields.paths {
+		fld := fields.asMap[path].(*field)
+		if !fld.hasChildren || fld.Type.Kind() == reflect.Ptr {
+			plan = append(plan, fld)
+		}
+	}
+	cached, _ := equalPlans.LoadOrStore(typeOf, plan)
+	return cached.([]*field)
+}
+
+// structPointer returns the pointer to the struct or the struct pointed to by obj, the struct value is copied.
+func structPointer(obj any) unsafe.Pointer {
+	valOf := reflect.ValueOf(obj)
+	if valOf.Kind() == reflect.Struct {
+		ptr := reflect.New(valOf.Type())
+		ptr.Elem().Set(valOf)
+		return ptr.UnsafePointer()
+	}
+	return valOf.UnsafePointer()
+}
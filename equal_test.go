@@ -0,0 +1,208 @@
+package fmap
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestField_Equal(t *testing.T) {
+	type Status string
+	type testStruct struct {
+		Int    int
+		Uint8  uint8
+		Float  float64
+		Name   string
+		Status Status
+		Ptr    *int
+		Slice  []string
+		Nested NestedStruct
+	}
+	fields, _ := Get[testStruct]()
+	equal := func(path string, a, b *testStruct) bool {
+		return fields.MustFind(path).Equal(a, b)
+	}
+
+	t.Run("Primitives", func(t *testing.T) {
+		a := &testStruct{Int: 1, Uint8: 2, Float: 1.5, Name: "a", Status: "active"}
+		b := &testStruct{Int: 1, Uint8: 3, Float: 1.5, Name: "b", Status: "active"}
+		assert.True(t, equal("Int", a, b))
+		assert.False(t, equal("Uint8", a, b))
+		assert.True(t, equal("Float", a, b))
+		assert.False(t, equal("Name", a, b))
+		assert.True(t, equal("Status", a, b))
+		assert.False(t, equal("Float", &testStruct{Float: math.NaN()}, &testStruct{Float: math.NaN()}))
+	})
+	t.Run("Pointers", func(t *testing.T) {
+		one, otherOne, two := 1, 1, 2
+		assert.True(t, equal("Ptr", &testStruct{}, &testStruct{}))
+		assert.False(t, equal("Ptr", &testStruct{Ptr: &one}, &testStruct{}))
+		assert.False(t, equal("Ptr", &testStruct{}, &testStruct{Ptr: &one}))
+		assert.True(t, equal("Ptr", &testStruct{Ptr: &one}, &testStruct{Ptr: &otherOne}))
+		assert.False(t, equal("Ptr", &testStruct{Ptr: &one}, &testStruct{Ptr: &two}))
+	})
+	t.Run("Composites", func(t *testing.T) {
+		assert.True(t, equal("Slice", &testStruct{Slice: []string{"a"}}, &testStruct{Slice: []string{"a"}}))
+		assert.False(t, equal("Slice", &testStruct{Slice: []string{"a"}}, &testStruct{Slice: []string{"b"}}))
+		assert.True(t, equal("Nested", &testStruct{Nested: NestedStruct{String: "a"}}, &testStruct{Nested: NestedStruct{String: "a"}}))
+		assert.False(t, equal("Nested.String", &testStruct{Nested: NestedStruct{String: "a"}}, &testStruct{}))
+	})
+	t.Run("WrongObject", func(t *testing.T) {
+		assert.Panics(t, func() { fields.MustFind("Int").Equal(&testStruct{}, testStruct{}) })
+	})
+}
+
+func BenchmarkField_Equal(b *testing.B) {
+	type testStruct struct {
+		Name string
+	}
+	fields, _ := Get[testStruct]()
+	fld := fields.MustFind("Name")
+	x, y := &testStruct{Name: "name"}, &testStruct{Name: "name"}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = fld.Equal(x, y)
+	}
+}
+
+type equalDatabase struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+	Timeout  time.Duration
+	Options  map[string]string
+}
+
+type equalConfig struct {
+	Name      string
+	Version   int
+	Debug     bool
+	Ratio     float64
+	Replicas  int32
+	Region    string
+	Zone      string
+	MaxConns  uint16
+	Retries   int8
+	Enabled   bool
+	Workers   int
+	QueueSize int
+	BatchSize int
+	Threshold float32
+	LogLevel  string
+	LogFormat string
+	Port      uint16
+	Database  equalDatabase
+	Cache     equalDatabase
+	Tags      []string
+	CreatedAt time.Time
+	Owner     *string
+	*embeddedLeaf
+	secret string
+}
+
+func newEqualConfig() *equalConfig {
+	owner := "owner"
+	return &equalConfig{
+		Name:      "app",
+		Version:   3,
+		Debug:     true,
+		Ratio:     0.5,
+		Replicas:  3,
+		Region:    "eu",
+		Zone:      "eu-1",
+		MaxConns:  100,
+		Retries:   5,
+		Enabled:   true,
+		Workers:   8,
+		QueueSize: 1024,
+		BatchSize: 64,
+		Threshold: 0.75,
+		LogLevel:  "info",
+		LogFormat: "json",
+		Port:      8080,
+		Database:  equalDatabase{Host: "db", Port: 5432, User: "user", Password: "pass", Timeout: time.Second},
+		Cache:     equalDatabase{Host: "cache", Port: 6379},
+		Tags:      []string{"a", "b"},
+		CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Owner:     &owner,
+		secret:    "secret",
+	}
+}
+
+func TestEqual(t *testing.T) {
+	t.Run("Equal", func(t *testing.T) {
+		assert.True(t, Equal(newEqualConfig(), newEqualConfig()))
+		assert.True(t, Equal(*newEqualConfig(), *newEqualConfig()))
+		assert.True(t, Equal(&equalConfig{}, &equalConfig{}))
+		assert.True(t, Equal((*equalConfig)(nil), (*equalConfig)(nil)))
+		a, b := newEqualConfig(), newEqualConfig()
+		a.embeddedLeaf, b.embeddedLeaf = &embeddedLeaf{Value: "v"}, &embeddedLeaf{Value: "v"}
+		assert.True(t, Equal(a, b))
+	})
+	t.Run("Differences", func(t *testing.T) {
+		for name, update := range map[string]func(cfg *equalConfig){
+			"Name":           func(cfg *equalConfig) { cfg.Name = "other" },
+			"Retries":        func(cfg *equalConfig) { cfg.Retries = 6 },
+			"Database.Port":  func(cfg *equalConfig) { cfg.Database.Port = 1 },
+			"Cache.Options":  func(cfg *equalConfig) { cfg.Cache.Options = map[string]string{"db": "1"} },
+			"Tags":           func(cfg *equalConfig) { cfg.Tags = append(cfg.Tags, "c") },
+			"CreatedAt":      func(cfg *equalConfig) { cfg.CreatedAt = time.Now() },
+			"Owner":          func(cfg *equalConfig) { cfg.Owner = nil },
+			"secret":         func(cfg *equalConfig) { cfg.secret = "other" },
+			"embeddedLeaf":   func(cfg *equalConfig) { cfg.embeddedLeaf = &embeddedLeaf{} },
+			"embeddedLeaf.V": func(cfg *equalConfig) { cfg.embeddedLeaf = &embeddedLeaf{Value: "v"} },
+		} {
+			a, b := newEqualConfig(), newEqualConfig()
+			update(b)
+			assert.False(t, Equal(a, b), name)
+			assert.Equal(t, reflect.DeepEqual(a, b), Equal(a, b), name)
+		}
+		a, b := newEqualConfig(), newEqualConfig()
+		a.embeddedLeaf, b.embeddedLeaf = &embeddedLeaf{Value: "a"}, &embeddedLeaf{Value: "b"}
+		assert.False(t, Equal(a, b))
+	})
+	t.Run("Cycle", func(t *testing.T) {
+		type node struct {
+			Value int
+			Next  *node
+		}
+		type list struct {
+			Head *node
+		}
+		newList := func(value int) *list {
+			a, b := &node{Value: 1}, &node{Value: value}
+			a.Next, b.Next = b, a
+			return &list{Head: a}
+		}
+		assert.True(t, Equal(newList(2), newList(2)))
+		assert.False(t, Equal(newList(2), newList(3)))
+	})
+	t.Run("Types", func(t *testing.T) {
+		assert.False(t, Equal(newEqualConfig(), *newEqualConfig()))
+		assert.False(t, Equal(&equalConfig{}, &equalDatabase{}))
+		assert.False(t, Equal(newEqualConfig(), nil))
+		assert.False(t, Equal(newEqualConfig(), (*equalConfig)(nil)))
+		assert.False(t, Equal(1, 1))
+		assert.False(t, Equal(nil, nil))
+	})
+}
+
+func BenchmarkEqual(b *testing.B) {
+	x, y := newEqualConfig(), newEqualConfig()
+	b.Run("fmap", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = Equal(x, y)
+		}
+	})
+	b.Run("reflect.DeepEqual", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = reflect.DeepEqual(x, y)
+		}
+	})
+}
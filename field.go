@@ -1,7 +1,9 @@
 package fmap
 
 import (
+	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 	"unsafe"
 )
@@ -11,6 +13,24 @@ type field struct {
 	structPath      string
 	parent          *field
 	dereferenceType reflect.Type
+	hasChildren     bool
+	hasPointers     bool
+	// owner is the type of the root struct the field offset is relative to.
+	owner reflect.Type
+	// depth is the nesting level of the field, 0 for the top-level fields.
+	depth int
+	// structType is the type of the struct declaring the field.
+	structType reflect.Type
+	// ptrParent is the closest embedded struct pointer field on the field path,
+	// if set, the field offset is relative to the struct it points to.
+	ptrParent *field
+	// elemType and elemSize are the cached element type and size of the array, slice, map and pointer fields.
+	elemType reflect.Type
+	elemSize uintptr
+	// readOnly is set for the unexported fields and the fields nested in them, see Options.IncludeUnexported.
+	readOnly bool
+	// promoted is set for the fields reached through the embedded structs, i.e. with an anonymous ancestor.
+	promoted bool
 }
 
 func (f *field) GetName() string {
@@ -25,6 +45,31 @@ func (f *field) GetType() reflect.Type {
 	return f.Type
 }
 
+func (f *field) GetKind() reflect.Kind {
+	return f.Type.Kind()
+}
+
+func (f *field) IsPointer() bool {
+	return f.Type.Kind() == reflect.Ptr
+}
+
+func (f *field) Implements(iface reflect.Type) bool {
+	return f.Type.Implements(iface)
+}
+
+func (f *field) PtrImplements(iface reflect.Type) bool {
+	return f.Type.Implements(iface) || reflect.PointerTo(f.Type).Implements(iface)
+}
+
+func (f *field) ElemKind() reflect.Kind {
+	switch f.Type.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return f.Type.Elem().Kind()
+	default:
+		return reflect.Invalid
+	}
+}
+
 func (f *field) GetTag() reflect.StructTag {
 	return f.Tag
 }
@@ -33,6 +78,22 @@ func (f *field) GetOffset() uintptr {
 	return f.Offset
 }
 
+func (f *field) GetSize() uintptr {
+	return f.Type.Size()
+}
+
+func (f *field) GetEnd() uintptr {
+	return f.Offset + f.Type.Size()
+}
+
+func (f *field) GetAlign() uintptr {
+	return uintptr(f.Type.Align())
+}
+
+func (f *field) GetFieldAlign() uintptr {
+	return uintptr(f.Type.FieldAlign())
+}
+
 func (f *field) GetIndex() []int {
 	return f.Index
 }
@@ -41,6 +102,10 @@ func (f *field) GetAnonymous() bool {
 	return f.Anonymous
 }
 
+func (f *field) IsPromoted() bool {
+	return f.promoted
+}
+
 func (f *field) IsExported() bool {
 	return f.PkgPath == ""
 }
@@ -49,24 +114,134 @@ func (f *field) GetStructPath() string {
 	return f.structPath
 }
 
+// String returns the field description for debugging,
+// e.g. fmap.Field{path: "User.Address.City", type: string, offset: 48, tag: json:"city"}.
+func (f *field) String() string {
+	sb := strings.Builder{}
+	sb.Grow(64 + len(f.structPath) + len(f.Tag))
+	sb.WriteString(`fmap.Field{path: "`)
+	sb.WriteString(f.structPath)
+	sb.WriteString(`", type: `)
+	if f.Type != nil {
+		sb.WriteString(f.Type.String())
+	} else {
+		sb.WriteString("<nil>")
+	}
+	sb.WriteString(", offset: ")
+	sb.WriteString(strconv.FormatUint(uint64(f.Offset), 10))
+	sb.WriteString(", tag: ")
+	sb.WriteString(string(f.Tag))
+	sb.WriteString("}")
+	return sb.String()
+}
+
+func (f *field) GetDepth() int {
+	return f.depth
+}
+
+func (f *field) GetStruct() reflect.Type {
+	return f.structType
+}
+
 func (f *field) GetParent() Field {
+	if f.parent == nil {
+		// the nil *field would be the non-nil Field
+		return nil
+	}
 	return f.parent
 }
 
+// Clone returns the copy of the field with the copied parent chain, which doesn't share any memory
+// with the Storage it was found in.
+func (f *field) Clone() Field {
+	return f.clone()
+}
+
+func (f *field) clone() *field {
+	c := *f
+	c.Index = append([]int(nil), f.Index...)
+	if f.parent == nil {
+		return &c
+	}
+	c.parent = f.parent.clone()
+	// the ptrParent is either the parent itself or inherited from the parent, see builder.getFieldsMapRecursive
+	switch f.ptrParent {
+	case nil:
+	case f.parent:
+		c.ptrParent = c.parent
+	default:
+		c.ptrParent = c.parent.ptrParent
+	}
+	return &c
+}
+
+// isEmbeddedChain reports whether the field and all its parents are embedded (anonymous) fields.
+func (f *field) isEmbeddedChain() bool {
+	for fld := f; fld != nil; fld = fld.parent {
+		if !fld.Anonymous {
+			return false
+		}
+	}
+	return true
+}
+
+// isEmbeddedStructPtr reports whether the field is an embedded (anonymous) pointer to struct.
+func (f *field) isEmbeddedStructPtr() bool {
+	return f.Anonymous && f.Type.Kind() == reflect.Ptr && f.Type.Elem().Kind() == reflect.Struct
+}
+
 func (f *field) GetPtr(obj interface{}) interface{} {
 	return reflect.NewAt(f.Type, f.getPtr(obj)).Interface()
 }
 
+// GetUnsafePointer returns the raw pointer to the field's value in the provided object, see Field.GetUnsafePointer.
+func (f *field) GetUnsafePointer(obj any) unsafe.Pointer {
+	return f.getPtr(obj)
+}
+
+// GetReflectValue returns the addressable reflect.Value of the field in the provided object.
+func (f *field) GetReflectValue(obj any) reflect.Value {
+	return reflect.NewAt(f.Type, f.getPtr(obj)).Elem()
+}
+
 // Get returns the value of the storage in the provided object.
 // It takes a parameter `obj` of type `interface{}`, representing the object.
 // It returns the value of the storage as an `interface{}`.
+// The value always has the declared field type, e.g. Status for the type Status int field.
 func (f *field) Get(obj interface{}) interface{} {
-	ptrToField := f.getPtr(obj)
-	kind := f.Type.Kind()
+	return getValue(f.Type, f.getReadPtr(obj))
+}
+
+// GetByIndex returns the value of the field in the provided object read with reflect.Value.FieldByIndex by the full
+// index instead of the offset, the slower but safe fallback to Get and the cross-check of the offset correctness.
+// The fields behind the nil embedded struct pointers are returned as zero values like Get does.
+func (f *field) GetByIndex(obj any) any {
+	if err := f.checkObj(obj); err != nil {
+		panic(err)
+	}
+	val, err := reflect.ValueOf(obj).Elem().FieldByIndexErr(f.Index)
+	if err != nil {
+		return reflect.Zero(f.Type).Interface()
+	}
+	// the unexported fields are read through the pointer like Get does
+	return reflect.NewAt(f.Type, unsafe.Pointer(val.UnsafeAddr())).Elem().Interface()
+}
+
+// getValue returns the value of the typ type stored at the ptr.
+// The builtin primitive types and pointers to them are read directly, all other types are read with reflect.
+func getValue(typ reflect.Type, ptrToField unsafe.Pointer) interface{} {
+	kind := typ.Kind()
 	isPtr := false
+	base := typ
 	if kind == reflect.Ptr {
 		isPtr = true
-		kind = f.Type.Elem().Kind()
+		base = typ.Elem()
+		kind = base.Kind()
+	}
+	if typ.PkgPath() != "" || base.PkgPath() != "" {
+		// the named type, e.g. type Status int or type IntPtr *int, or the pointer to it,
+		// the fast path would return its underlying builtin type
+		return reflect.NewAt(typ, ptrToField).Elem().Interface()
 	}
 	if isPtr {
 		switch kind {
@@ -98,14 +273,8 @@ func (f *field) Get(obj interface{}) interface{} {
 			return getPtrValue[*float64](ptrToField)
 		case reflect.Bool:
 			return getPtrValue[*bool](ptrToField)
-		case reflect.Struct:
-			return reflect.NewAt(f.Type, ptrToField).Elem().Interface()
-		case reflect.Slice:
-			return reflect.NewAt(f.Type, ptrToField).Elem().Interface()
-		case reflect.Array:
-			return reflect.NewAt(f.Type, ptrToField).Elem().Interface()
 		default:
-			panic("unhandled default case")
+			return reflect.NewAt(typ, ptrToField).Elem().Interface()
 		}
 	} else {
 		switch kind {
@@ -137,19 +306,32 @@ func (f *field) Get(obj interface{}) interface{} {
 			return getPtrValue[float64](ptrToField)
 		case reflect.Bool:
 			return getPtrValue[bool](ptrToField)
-		case reflect.Struct:
-			return reflect.NewAt(f.Type, ptrToField).Elem().Interface()
-		case reflect.Slice:
-			return reflect.NewAt(f.Type, ptrToField).Elem().Interface()
-		case reflect.Array:
-			return reflect.NewAt(f.Type, ptrToField).Elem().Interface()
 		default:
-			panic("unhandled default case")
+			return reflect.NewAt(typ, ptrToField).Elem().Interface()
 		}
 	}
 }
 
 func (f *field) GetTagPath(tag string, ignoreParentTagMissing bool) string {
+	return f.GetTagPathWithSep(tag, ".", ignoreParentTagMissing)
+}
+
+// GetTagPathWithSep is the GetTagPath variant that joins the parent and the child tag names with the sep.
+func (f *field) GetTagPathWithSep(tag, sep string, ignoreParentTagMissing bool) string {
+	return f.GetTagPathFunc(tag, nil, sep, ignoreParentTagMissing)
+}
+
+// GetTagPathFunc is the GetTagPathWithSep variant that applies the transform to each tag name before joining,
+// the nil transform keeps the tag names as is.
+// The fields excluded with the "-" tag value, or nested in the excluded ones, have the empty path.
+func (f *field) GetTagPathFunc(tag string, transform func(string) string, sep string, ignoreParentTagMissing bool) string {
+	if f.IsTagSkipped(tag) {
+		return ""
+	}
+	return f.tagPathFunc(tag, transform, sep, ignoreParentTagMissing)
+}
+
+func (f *field) tagPathFunc(tag string, transform func(string) string, sep string, ignoreParentTagMissing bool) string {
 	tagPath := ""
 	if val, ok := f.Tag.Lookup(tag); ok {
 		vals := strings.Split(val, ",")
@@ -160,31 +342,187 @@ func (f *field) GetTagPath(tag string, ignoreParentTagMissing bool) string {
 	if tagPath == "" {
 		return tagPath
 	}
+	if transform != nil {
+		tagPath = transform(tagPath)
+	}
 	if f.parent == nil {
 		return tagPath
 	}
-	parentTag := f.parent.GetTagPath(tag, ignoreParentTagMissing)
+	parentTag := f.parent.tagPathFunc(tag, transform, sep, ignoreParentTagMissing)
 	if parentTag == "" && !ignoreParentTagMissing {
 		return ""
 	}
 	if parentTag == "" {
 		return tagPath
 	}
-	return parentTag + "." + tagPath
+	return parentTag + sep + tagPath
+}
+
+// IsTagSkipped reports whether the field or any of its parents is excluded with the tag value of exactly "-",
+// like the `json:"-"` fields are excluded by encoding/json, the `json:"-,"` tag names the field "-" instead.
+func (f *field) IsTagSkipped(tag string) bool {
+	for parent := f; parent != nil; parent = parent.parent {
+		if parent.Tag.Get(tag) == "-" {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *field) GetTagOr(tag, def string) string {
+	if val, ok := f.Tag.Lookup(tag); ok {
+		return val
+	}
+	return def
+}
+
+// GetTagOptions returns the comma separated options following the name in the tag value,
+// e.g. ["omitempty", "string"] for the `json:"name,omitempty,string"` tag, nil if there are no options.
+func (f *field) GetTagOptions(tag string) []string {
+	_, opts, found := strings.Cut(f.Tag.Get(tag), ",")
+	if !found {
+		return nil
+	}
+	return strings.Split(opts, ",")
+}
+
+func (f *field) HasTagOption(tag, option string) bool {
+	_, opts, _ := strings.Cut(f.Tag.Get(tag), ",")
+	return hasTagOption(opts, option)
+}
+
+// hasTagOption reports whether the comma separated opts contain the option.
+func hasTagOption(opts, option string) bool {
+	for opts != "" {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
+
+// IsZero reports whether the field value in the provided object is the zero value of the field type,
+// the fields behind the nil embedded struct pointers are zero.
+func (f *field) IsZero(obj any) bool {
+	return reflect.NewAt(f.Type, f.getReadPtr(obj)).Elem().IsZero()
+}
+
+// SetDefault sets the val to the field in the provided object only if the field value is zero, see IsZero,
+// and reports whether it was set. The nil pointers are zero, the pointers to the zero values are not.
+func (f *field) SetDefault(obj any, val any) bool {
+	if !f.IsZero(obj) {
+		return false
+	}
+	f.Set(obj, val)
+	return true
 }
 
 // getPtr returns a pointer to the field's value in the provided configuration object.
-// It takes a parameter `conf` of type `any`, representing the configuration object.
+// It takes a parameter `conf` of type `any`, representing the pointer to configuration object.
 // It returns an `unsafe.Pointer` to the `field's` value in the configuration object.
+// The nil embedded struct pointers on the way to the field are allocated.
+// It panics if the obj is not a non-nil pointer to the field owner struct.
 func (f *field) getPtr(obj interface{}) unsafe.Pointer {
-	confPointer := ((*[2]unsafe.Pointer)(unsafe.Pointer(&obj)))[1]
-	ptToField := unsafe.Add(confPointer, f.Offset)
-	return ptToField
+	ptr, err := f.tryGetPtr(obj, true)
+	if err != nil {
+		panic(err)
+	}
+	return ptr
 }
 
-func setPtrValue[T any](ptr unsafe.Pointer, val any) {
-	valSet := (*T)(ptr)
-	*valSet = val.(T)
+// getReadPtr is the getPtr variant for reading, it doesn't modify the obj.
+// If the field is behind the nil embedded struct pointer, it returns the pointer to the new zero value.
+func (f *field) getReadPtr(obj interface{}) unsafe.Pointer {
+	ptr, err := f.tryGetPtr(obj, false)
+	if err != nil {
+		panic(err)
+	}
+	return ptr
+}
+
+// tryGetPtr is the getPtr variant that returns an error instead of panic.
+// The alloc is set for the write access, which is rejected for the read-only fields.
+func (f *field) tryGetPtr(obj interface{}, alloc bool) (unsafe.Pointer, error) {
+	if err := f.checkObj(obj); err != nil {
+		return nil, err
+	}
+	if alloc {
+		if err := f.checkWritable(); err != nil {
+			return nil, err
+		}
+	}
+	confPointer := objPointer(obj)
+	base := f.basePtr(confPointer, alloc)
+	if base == nil {
+		return reflect.New(f.Type).UnsafePointer(), nil
+	}
+	ptToField := unsafe.Add(base, f.Offset)
+	return ptToField, nil
+}
+
+// basePtr returns the pointer to the struct the field offset is relative to.
+// For the fields behind the embedded struct pointers the offset-only access is not possible,
+// so the chain of pointers is dereferenced one by one, nil pointers are allocated if alloc is true.
+// It returns nil if the chain contains a nil pointer and alloc is false.
+func (f *field) basePtr(root unsafe.Pointer, alloc bool) unsafe.Pointer {
+	if f.ptrParent == nil {
+		return root
+	}
+	parentBase := f.ptrParent.basePtr(root, alloc)
+	if parentBase == nil {
+		return nil
+	}
+	ptr := (*unsafe.Pointer)(unsafe.Add(parentBase, f.ptrParent.Offset))
+	if *ptr == nil {
+		if !alloc {
+			return nil
+		}
+		*ptr = reflect.New(f.ptrParent.Type.Elem()).UnsafePointer()
+	}
+	return *ptr
+}
+
+// checkObj checks that the obj is a non-nil pointer to the field owner struct, so the field can be accessed by the offset.
+func (f *field) checkObj(obj interface{}) error {
+	typeOf := reflect.TypeOf(obj)
+	if typeOf == nil || typeOf.Kind() != reflect.Ptr {
+		return fmt.Errorf("fmap: field %s: not supported object type: %v, only ptr to struct is supported", f.structPath, typeOf)
+	}
+	if f.owner != nil && typeOf.Elem() != f.owner {
+		return fmt.Errorf("fmap: field %s: object type %v doesn't match the field owner type %v", f.structPath, typeOf, f.owner)
+	}
+	if objPointer(obj) == nil {
+		return fmt.Errorf("fmap: field %s: object is a nil pointer", f.structPath)
+	}
+	return nil
+}
+
+// checkWritable checks that the field isn't read-only, see Options.IncludeUnexported.
+func (f *field) checkWritable() error {
+	if f.readOnly {
+		return fmt.Errorf("fmap: field %s: unexported field is read-only", f.structPath)
+	}
+	return nil
+}
+
+// objPointer returns the pointer held by the obj interface, the obj must be a pointer, see checkObj.
+// The struct values are never addressed: the interface holding the struct value may point to the copy of it
+// or hold it directly in the data word, so the writes through it would be lost or corrupt the memory.
+// Unlike reading the interface data word directly, reflect.Value.UnsafePointer doesn't depend on the interface
+// memory layout. It costs a few nanoseconds more, which is negligible for Get and Set, see BenchmarkObjPointer.
+func objPointer(obj any) unsafe.Pointer {
+	return reflect.ValueOf(obj).UnsafePointer()
+}
+
+// setPtrValue sets the val to the T value stored at the ptr if the val is T, it reports whether the val was set.
+func setPtrValue[T any](ptr unsafe.Pointer, val any) bool {
+	v, ok := val.(T)
+	if ok {
+		*(*T)(ptr) = v
+	}
+	return ok
 }
 
 func getPtrValue[T any](ptr unsafe.Pointer) T {
@@ -198,88 +536,274 @@ func getPtrValue[T any](ptr unsafe.Pointer) T {
 //
 // The Set method uses the getPtr method to get a pointer to the storage in the object.
 // It then performs a type switch on the kind of the storage to determine its type, and sets the value accordingly.
-// The supported storage types are string, int, and bool.
-// If the storage type is not one of the supported types, it panics with the message "unhandled default case".
+// The builtin primitive types are set directly, all other types, including the named types over the primitive kinds,
+// e.g. type Status int, are set with reflect. The values of the named type and its underlying type are interchangeable.
+// The nil val clears the pointer, slice, map, chan, func and interface fields, including the embedded interfaces,
+// i.e. sets them to nil. The pointer fields of any depth, e.g. **int, accept the values of the pointed types too,
+// e.g. *int and int, which are written through the existing pointers, the nil pointers on the way are allocated.
+// It panics if the val can't be assigned or converted to the field type, e.g. the nil val to the non-nilable field.
 func (f *field) Set(obj interface{}, val interface{}) {
-	ptrToField := f.getPtr(obj)
-	kind := f.Type.Kind()
+	ptr := f.getPtr(obj)
+	if val == nil && !isNilable(f.Type) {
+		panic(fmt.Errorf("fmap: field %s: value of type <nil> is not assignable to %v", f.structPath, f.Type))
+	}
+	setValue(f.Type, ptr, val)
+}
+
+// isNilable reports whether the nil is the valid value of the typ.
+func isNilable(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Chan, reflect.Func, reflect.Interface, reflect.UnsafePointer:
+		return true
+	default:
+		return false
+	}
+}
+
+// setValue sets the val to the typ type value stored at the ptr.
+func setValue(typ reflect.Type, ptrToField unsafe.Pointer, val interface{}) {
+	kind := typ.Kind()
 	isPtr := false
 	if kind == reflect.Ptr {
 		isPtr = true
-		kind = f.Type.Elem().Kind()
+		kind = typ.Elem().Kind()
 	}
+	// ok is false for the composite kinds and for the named types, e.g. type Status int,
+	// which values don't match the builtin type assertion of the fast path
+	ok := false
 	if isPtr {
 		switch kind {
 		case reflect.String:
-			setPtrValue[*string](ptrToField, val)
+			ok = setPtrValue[*string](ptrToField, val)
 		case reflect.Int:
-			setPtrValue[*int](ptrToField, val)
+			ok = setPtrValue[*int](ptrToField, val)
 		case reflect.Int8:
-			setPtrValue[*int8](ptrToField, val)
+			ok = setPtrValue[*int8](ptrToField, val)
 		case reflect.Int16:
-			setPtrValue[*int16](ptrToField, val)
+			ok = setPtrValue[*int16](ptrToField, val)
 		case reflect.Int32:
-			setPtrValue[*int32](ptrToField, val)
+			ok = setPtrValue[*int32](ptrToField, val)
 		case reflect.Int64:
-			setPtrValue[*int64](ptrToField, val)
+			ok = setPtrValue[*int64](ptrToField, val)
 		case reflect.Uint:
-			setPtrValue[*uint](ptrToField, val)
+			ok = setPtrValue[*uint](ptrToField, val)
 		case reflect.Uint8:
-			setPtrValue[*uint8](ptrToField, val)
+			ok = setPtrValue[*uint8](ptrToField, val)
 		case reflect.Uint16:
-			setPtrValue[*uint16](ptrToField, val)
+			ok = setPtrValue[*uint16](ptrToField, val)
 		case reflect.Uint32:
-			setPtrValue[*uint32](ptrToField, val)
+			ok = setPtrValue[*uint32](ptrToField, val)
 		case reflect.Uint64:
-			setPtrValue[*uint64](ptrToField, val)
+			ok = setPtrValue[*uint64](ptrToField, val)
 		case reflect.Float32:
-			setPtrValue[*float32](ptrToField, val)
+			ok = setPtrValue[*float32](ptrToField, val)
 		case reflect.Float64:
-			setPtrValue[*float64](ptrToField, val)
+			ok = setPtrValue[*float64](ptrToField, val)
 		case reflect.Bool:
-			setPtrValue[*bool](ptrToField, val)
-		default:
-			dest := reflect.NewAt(f.Type, ptrToField)
-			dest = dest.Elem()
-			source := reflect.ValueOf(val)
-			dest.Set(source)
+			ok = setPtrValue[*bool](ptrToField, val)
 		}
 	} else {
 		switch kind {
 		case reflect.String:
-			setPtrValue[string](ptrToField, val)
+			ok = setPtrValue[string](ptrToField, val)
 		case reflect.Int:
-			setPtrValue[int](ptrToField, val)
+			ok = setPtrValue[int](ptrToField, val)
 		case reflect.Int8:
-			setPtrValue[int8](ptrToField, val)
+			ok = setPtrValue[int8](ptrToField, val)
 		case reflect.Int16:
-			setPtrValue[int16](ptrToField, val)
+			ok = setPtrValue[int16](ptrToField, val)
 		case reflect.Int32:
-			setPtrValue[int32](ptrToField, val)
+			ok = setPtrValue[int32](ptrToField, val)
 		case reflect.Int64:
-			setPtrValue[int64](ptrToField, val)
+			ok = setPtrValue[int64](ptrToField, val)
 		case reflect.Uint:
-			setPtrValue[uint](ptrToField, val)
+			ok = setPtrValue[uint](ptrToField, val)
 		case reflect.Uint8:
-			setPtrValue[uint8](ptrToField, val)
+			ok = setPtrValue[uint8](ptrToField, val)
 		case reflect.Uint16:
-			setPtrValue[uint16](ptrToField, val)
+			ok = setPtrValue[uint16](ptrToField, val)
 		case reflect.Uint32:
-			setPtrValue[uint32](ptrToField, val)
+			ok = setPtrValue[uint32](ptrToField, val)
 		case reflect.Uint64:
-			setPtrValue[uint64](ptrToField, val)
+			ok = setPtrValue[uint64](ptrToField, val)
 		case reflect.Float32:
-			setPtrValue[float32](ptrToField, val)
+			ok = setPtrValue[float32](ptrToField, val)
 		case reflect.Float64:
-			setPtrValue[float64](ptrToField, val)
+			ok = setPtrValue[float64](ptrToField, val)
 		case reflect.Bool:
-			setPtrValue[bool](ptrToField, val)
-		default:
-			dest := reflect.NewAt(f.Type, ptrToField)
-			dest = dest.Elem()
-			source := reflect.ValueOf(val)
-			dest.Set(source)
+			ok = setPtrValue[bool](ptrToField, val)
+		}
+	}
+	if !ok {
+		setConvertedValue(typ, ptrToField, val)
+	}
+}
+
+// setConvertedValue sets the val to the typ type value stored at the ptr with reflect.
+// The val of the same kind is converted to the typ, so the named types and their underlying types are interchangeable,
+// e.g. Status and int values can be set to the Status field and vice versa.
+func setConvertedValue(typ reflect.Type, ptrToField unsafe.Pointer, val interface{}) {
+	source := reflect.ValueOf(val)
+	if !source.IsValid() && isNilable(typ) {
+		source = reflect.Zero(typ)
+	}
+	if source.IsValid() && source.Type() != typ && source.Kind() == typ.Kind() && source.Type().ConvertibleTo(typ) {
+		source = source.Convert(typ)
+	}
+	if source.IsValid() && !source.Type().AssignableTo(typ) && indirectAssignable(typ, source.Type()) {
+		// the value of the pointed type is written through the pointers, the nil ones are allocated
+		for !source.Type().AssignableTo(typ) {
+			ptr := (*unsafe.Pointer)(ptrToField)
+			if *ptr == nil {
+				*ptr = reflect.New(typ.Elem()).UnsafePointer()
+			}
+			ptrToField, typ = *ptr, typ.Elem()
+		}
+	}
+	reflect.NewAt(typ, ptrToField).Elem().Set(source)
+}
+
+// indirectAssignable reports whether the valType is assignable to the type pointed to by the typ pointer
+// at any pointer depth, e.g. int or *int for the **int typ.
+func indirectAssignable(typ, valType reflect.Type) bool {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+		if valType.AssignableTo(typ) {
+			return true
+		}
+	}
+	return false
+}
+
+// TryGet returns the value of the field in the provided object.
+// It returns an error instead of panic if the obj is not a non-nil pointer to the field owner struct.
+func (f *field) TryGet(obj any) (any, error) {
+	if err := f.checkObj(obj); err != nil {
+		return nil, err
+	}
+	return f.Get(obj), nil
+}
+
+// TrySet updates the value of the field in the provided object with the provided value.
+// It returns an error instead of panic if the obj is not a non-nil pointer to the field owner struct
+// or if the val is not assignable to the field type.
+func (f *field) TrySet(obj any, val any) error {
+	if err := f.checkSet(obj, val); err != nil {
+		return err
+	}
+	f.Set(obj, val)
+	return nil
+}
+
+// checkSet checks that the val can be set to the field in the obj without panic.
+func (f *field) checkSet(obj any, val any) error {
+	if err := f.checkObj(obj); err != nil {
+		return err
+	}
+	if err := f.checkWritable(); err != nil {
+		return err
+	}
+	valType := reflect.TypeOf(val)
+	if !settable(f.Type, valType) {
+		return fmt.Errorf("fmap: field %s: value of type %v is not assignable to %v", f.structPath, valType, f.Type)
+	}
+	return nil
+}
+
+// settable reports whether setValue accepts the value of the valType for the typ, i.e. the nil for the nilable typ,
+// the builtin type of the typ kind or the pointer to it on the fast path, the value of the same kind convertible to the typ,
+// and the value assignable to the typ or to the type pointed to by it at any pointer depth.
+func settable(typ, valType reflect.Type) bool {
+	if valType == nil {
+		return isNilable(typ)
+	}
+	if typ.Kind() == reflect.Ptr {
+		if valType.Kind() == reflect.Ptr && valType.Name() == "" && isFastKind(typ.Elem().Kind()) &&
+			isBuiltinType(valType.Elem(), typ.Elem().Kind()) {
+			return true
 		}
+	} else if isFastKind(typ.Kind()) && isBuiltinType(valType, typ.Kind()) {
+		return true
+	}
+	if valType != typ && valType.Kind() == typ.Kind() && valType.ConvertibleTo(typ) {
+		return true
+	}
+	return valType.AssignableTo(typ) || indirectAssignable(typ, valType)
+}
+
+// isFastKind reports whether the setValue sets the builtin type values of the kind without reflect.
+func isFastKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.Bool:
+		return true
+	default:
+		return false
+	}
+}
+
+// isBuiltinType reports whether the typeOf is the predeclared type of the kind, e.g. int, not type Status int.
+func isBuiltinType(typeOf reflect.Type, kind reflect.Kind) bool {
+	return typeOf.Kind() == kind && typeOf.PkgPath() == "" && typeOf.Name() != ""
+}
+
+// SetReflectValue assigns the v to the field in the provided object without boxing it into any.
+// It panics if the obj is not a non-nil pointer to the field owner struct or if the v is not assignable to the field type.
+func (f *field) SetReflectValue(obj any, v reflect.Value) {
+	if err := f.TrySetReflectValue(obj, v); err != nil {
+		panic(err)
+	}
+}
+
+// TrySetReflectValue is the SetReflectValue variant that returns an error instead of panic.
+func (f *field) TrySetReflectValue(obj any, v reflect.Value) error {
+	ptr, err := f.tryGetPtr(obj, true)
+	if err != nil {
+		return err
+	}
+	if !v.IsValid() || !v.Type().AssignableTo(f.Type) {
+		return fmt.Errorf("fmap: field %s: value of type %v is not assignable to %v", f.structPath, valueType(v), f.Type)
+	}
+	reflect.NewAt(f.Type, ptr).Elem().Set(v)
+	return nil
+}
+
+// valueType returns the type of the v or nil if the v is the zero Value.
+func valueType(v reflect.Value) reflect.Type {
+	if !v.IsValid() {
+		return nil
+	}
+	return v.Type()
+}
+
+// elem returns the element type and size of the array, slice, map or pointer field, they are cached by the builder,
+// see builder.getFieldsMapRecursive, the field is never modified here, as it's shared between goroutines.
+func (f *field) elem() (reflect.Type, uintptr) {
+	if f.elemType == nil {
+		elemType := f.Type.Elem()
+		return elemType, elemType.Size()
+	}
+	return f.elemType, f.elemSize
+}
+
+// hasElem reports whether the field type has the element type.
+func (f *field) hasElem() bool {
+	switch f.Type.Kind() {
+	case reflect.Array, reflect.Slice, reflect.Map, reflect.Ptr, reflect.Chan:
+		return true
+	default:
+		return false
+	}
+}
+
+// prepare resolves the pointer types of the field type and its element type,
+// so the reflect.NewAt calls in the composite Get and Set paths don't have to create them on the first use.
+func (f *field) prepare() {
+	reflect.PointerTo(f.Type)
+	if f.hasElem() {
+		elemType, _ := f.elem()
+		reflect.PointerTo(elemType)
 	}
 }
 
@@ -308,3 +832,17 @@ func (f *field) GetDereferenced(obj any) (any, bool) {
 	}
 	return nil, false
 }
+
+// EnsureNonNil allocates the zero value of the element type for the nil pointer field in the provided object
+// and returns the field pointer value, the non-nil pointers are returned untouched.
+// It panics if the field is not a pointer or if the obj is not a non-nil pointer to the field owner struct.
+func (f *field) EnsureNonNil(obj any) any {
+	if f.Type.Kind() != reflect.Ptr {
+		panic(fmt.Errorf("fmap: field %s: not supported type: %v, only pointer is supported", f.structPath, f.Type))
+	}
+	ptr := (*unsafe.Pointer)(f.getPtr(obj))
+	if *ptr == nil {
+		*ptr = reflect.New(f.Type.Elem()).UnsafePointer()
+	}
+	return reflect.NewAt(f.Type.Elem(), *ptr).Interface()
+}
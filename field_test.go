@@ -1,9 +1,12 @@
 package fmap
 
 import (
+	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
+	"unsafe"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -236,6 +239,148 @@ func TestField_Set(t *testing.T) {
 	})
 }
 
+type Level int
+
+const (
+	LevelLow Level = iota + 1
+	LevelHigh
+)
+
+type Color string
+
+func TestField_SetNamedPrimitive(t *testing.T) {
+	type testStruct struct {
+		Level    Level
+		Color    Color
+		PtrLevel *Level
+		PtrColor *Color
+		Int      int
+	}
+	fields, _ := Get[testStruct]()
+
+	t.Run("NamedValue", func(t *testing.T) {
+		obj := &testStruct{}
+		high, red := LevelHigh, Color("red")
+		fields.MustFind("Level").Set(obj, LevelHigh)
+		fields.MustFind("Color").Set(obj, red)
+		fields.MustFind("PtrLevel").Set(obj, &high)
+		fields.MustFind("PtrColor").Set(obj, &red)
+		assert.Equal(t, LevelHigh, obj.Level)
+		assert.Equal(t, Color("red"), obj.Color)
+		assert.Same(t, &high, obj.PtrLevel)
+		assert.Same(t, &red, obj.PtrColor)
+	})
+	t.Run("UnderlyingValue", func(t *testing.T) {
+		obj := &testStruct{}
+		low, blue := 1, "blue"
+		fields.MustFind("Level").Set(obj, 2)
+		fields.MustFind("Color").Set(obj, "green")
+		fields.MustFind("PtrLevel").Set(obj, &low)
+		fields.MustFind("PtrColor").Set(obj, &blue)
+		fields.MustFind("Int").Set(obj, LevelHigh)
+		assert.Equal(t, LevelHigh, obj.Level)
+		assert.Equal(t, Color("green"), obj.Color)
+		assert.Equal(t, LevelLow, *obj.PtrLevel)
+		assert.Equal(t, Color("blue"), *obj.PtrColor)
+		assert.Equal(t, 2, obj.Int)
+	})
+	t.Run("TrySet", func(t *testing.T) {
+		obj := &testStruct{}
+		low, blue := 1, "blue"
+		assert.NoError(t, fields.MustFind("Level").TrySet(obj, LevelLow))
+		assert.Equal(t, LevelLow, obj.Level)
+		assert.NoError(t, fields.MustFind("Level").TrySet(obj, 2))
+		assert.NoError(t, fields.MustFind("Color").TrySet(obj, "green"))
+		assert.NoError(t, fields.MustFind("PtrLevel").TrySet(obj, &low))
+		assert.NoError(t, fields.MustFind("PtrColor").TrySet(obj, &blue))
+		assert.NoError(t, fields.MustFind("Int").TrySet(obj, LevelHigh))
+		assert.Equal(t, testStruct{Level: LevelHigh, Color: "green", PtrLevel: obj.PtrLevel, PtrColor: obj.PtrColor, Int: 2}, *obj)
+		assert.EqualError(t, fields.MustFind("Level").TrySet(obj, "high"),
+			"fmap: field Level: value of type string is not assignable to fmap.Level")
+		assert.Error(t, fields.MustFind("Level").TrySet(obj, int64(1)))
+
+		assert.NoError(t, SetAll(obj, map[Field]any{fields.MustFind("Level"): 1, fields.MustFind("Color"): "red"}))
+		assert.Equal(t, LevelLow, obj.Level)
+		assert.Equal(t, Color("red"), obj.Color)
+	})
+	t.Run("TrySetMatchesSet", func(t *testing.T) {
+		low, blue, level := 1, "blue", LevelLow
+		values := []any{nil, 1, int64(1), "blue", LevelHigh, Color("red"), &low, &blue, &level, true}
+		for _, path := range []string{"Level", "Color", "PtrLevel", "PtrColor", "Int"} {
+			fld := fields.MustFind(path)
+			for _, val := range values {
+				setPanics := func() (panics bool) {
+					defer func() { panics = recover() != nil }()
+					fld.Set(&testStruct{}, val)
+					return false
+				}()
+				err := fld.TrySet(&testStruct{}, val)
+				assert.Equal(t, setPanics, err != nil, "%s: %T", path, val)
+			}
+		}
+	})
+	t.Run("Mismatch", func(t *testing.T) {
+		obj := &testStruct{}
+		assert.Panics(t, func() { fields.MustFind("Level").Set(obj, "high") })
+		assert.Panics(t, func() { fields.MustFind("Color").Set(obj, 1) })
+		assert.Panics(t, func() { fields.MustFind("Level").Set(obj, int64(1)) })
+		assert.Equal(t, testStruct{}, *obj)
+	})
+}
+
+func TestField_GetNamedPrimitive(t *testing.T) {
+	type testStruct struct {
+		Level    Level
+		Color    Color
+		PtrLevel *Level
+		Int      int
+		PtrInt   *int
+	}
+	fields, _ := Get[testStruct]()
+	high, one := LevelHigh, 1
+	obj := &testStruct{Level: LevelLow, Color: "red", PtrLevel: &high, Int: 1, PtrInt: &one}
+
+	level := fields.MustFind("Level").Get(obj)
+	assert.IsType(t, LevelLow, level)
+	assert.Equal(t, LevelLow, level)
+	assert.Equal(t, Color("red"), fields.MustFind("Color").Get(obj))
+	assert.Same(t, &high, fields.MustFind("PtrLevel").Get(obj))
+	assert.Equal(t, 1, fields.MustFind("Int").Get(obj))
+	assert.Same(t, &one, fields.MustFind("PtrInt").Get(obj))
+
+	switch val := level.(type) {
+	case Level:
+		assert.Equal(t, LevelLow, val)
+	default:
+		t.Errorf("unexpected type %T", val)
+	}
+	assert.Equal(t, (*Level)(nil), fields.MustFind("PtrLevel").Get(&testStruct{}))
+}
+
+func TestField_GetUnsafePointer(t *testing.T) {
+	type inner struct {
+		Port int
+	}
+	type testStruct struct {
+		Name string
+		*inner
+	}
+	fields, _ := Get[testStruct]()
+	obj := &testStruct{Name: "a"}
+	ptr := fields.MustFind("Name").GetUnsafePointer(obj)
+	assert.Equal(t, unsafe.Pointer(&obj.Name), ptr)
+	*(*string)(ptr) = "b"
+	assert.Equal(t, "b", obj.Name)
+
+	port := fields.MustFind("inner.Port").GetUnsafePointer(obj)
+	assert.NotNil(t, obj.inner)
+	assert.Equal(t, unsafe.Pointer(&obj.Port), port)
+
+	assert.Panics(t, func() { fields.MustFind("Name").GetUnsafePointer(testStruct{}) })
+	assert.Panics(t, func() { fields.MustFind("Name").GetUnsafePointer((*testStruct)(nil)) })
+	assert.Panics(t, func() { fields.MustFind("Name").GetUnsafePointer(&inner{}) })
+}
+
 func TestField_GetPtr(t *testing.T) {
 	t.Run("Get pointer to storage from struct", func(t *testing.T) {
 		strVal := "Test2"
@@ -299,6 +444,30 @@ func TestField_GetPtr(t *testing.T) {
 	})
 }
 
+func TestField_GetReflectValue(t *testing.T) {
+	source := &TestStruct{String: "a", Slice: []string{"x", "y"}}
+	fields, _ := GetFrom(source)
+
+	str := fields.MustFind("String").GetReflectValue(source)
+	assert.True(t, str.CanAddr())
+	assert.True(t, str.CanSet())
+	assert.Equal(t, "a", str.String())
+	str.SetString("b")
+	assert.Equal(t, "b", source.String)
+	assert.Equal(t, &source.String, str.Addr().Interface())
+
+	slice := fields.MustFind("Slice").GetReflectValue(source)
+	assert.Equal(t, 2, slice.Len())
+	slice.Set(reflect.Append(slice, reflect.ValueOf("z")))
+	assert.Equal(t, []string{"x", "y", "z"}, source.Slice)
+
+	nested := fields.MustFind("NestedStruct.PtrString").GetReflectValue(source)
+	nested.Set(reflect.ValueOf(&source.String))
+	assert.Equal(t, &source.String, source.NestedStruct.PtrString)
+
+	assert.Panics(t, func() { fields.MustFind("String").GetReflectValue(*source) })
+}
+
 func getMockField(tag reflect.StructTag, parent *field) *field {
 	return &field{
 		StructField: reflect.StructField{
@@ -308,6 +477,50 @@ func getMockField(tag reflect.StructTag, parent *field) *field {
 	}
 }
 
+func TestField_SetReflectValue(t *testing.T) {
+	type Status string
+	type testStruct struct {
+		Name   string
+		Status Status
+		Count  int64
+	}
+	fields, _ := Get[testStruct]()
+	name := fields.MustFind("Name")
+
+	t.Run("Set", func(t *testing.T) {
+		obj := &testStruct{}
+		name.SetReflectValue(obj, reflect.ValueOf("john"))
+		assert.Equal(t, "john", obj.Name)
+		fields.MustFind("Status").SetReflectValue(obj, reflect.ValueOf(Status("active")))
+		assert.Equal(t, Status("active"), obj.Status)
+
+		source := &testStruct{Name: "jane"}
+		name.SetReflectValue(obj, name.GetReflectValue(source))
+		assert.Equal(t, "jane", obj.Name)
+	})
+	t.Run("Mismatch", func(t *testing.T) {
+		obj := &testStruct{Name: "john"}
+		err := name.TrySetReflectValue(obj, reflect.ValueOf(1))
+		assert.EqualError(t, err, "fmap: field Name: value of type int is not assignable to string")
+		err = fields.MustFind("Status").TrySetReflectValue(obj, reflect.ValueOf("active"))
+		assert.EqualError(t, err, "fmap: field Status: value of type string is not assignable to fmap.Status")
+		assert.Error(t, name.TrySetReflectValue(obj, reflect.Value{}))
+		assert.Error(t, name.TrySetReflectValue(*obj, reflect.ValueOf("jane")))
+		assert.Panics(t, func() { name.SetReflectValue(obj, reflect.ValueOf(1)) })
+		assert.Equal(t, "john", obj.Name)
+	})
+	t.Run("Convert", func(t *testing.T) {
+		obj := &testStruct{Name: "john"}
+		assert.NoError(t, fields.MustFind("Status").SetReflectValueConvert(obj, reflect.ValueOf("active")))
+		assert.Equal(t, Status("active"), obj.Status)
+		assert.NoError(t, fields.MustFind("Count").SetReflectValueConvert(obj, reflect.ValueOf(uint8(7))))
+		assert.Equal(t, int64(7), obj.Count)
+		assert.NoError(t, name.SetReflectValueConvert(obj, reflect.Value{}))
+		assert.Equal(t, "", obj.Name)
+		assert.Error(t, fields.MustFind("Count").SetReflectValueConvert(obj, reflect.ValueOf(1.5)))
+	})
+}
+
 func TestField_GetTagPath(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -362,6 +575,455 @@ func TestField_GetTagPath(t *testing.T) {
 	}
 }
 
+func TestField_GetTagPathWithSep(t *testing.T) {
+	inner := getMockField(`env:"INNER"`, getMockField(`env:"OUTER"`, nil))
+	name := getMockField(`env:"NAME"`, inner)
+	assert.Equal(t, "OUTER__INNER__NAME", name.GetTagPathWithSep("env", "__", false))
+	assert.Equal(t, "OUTER/INNER/NAME", name.GetTagPathWithSep("env", "/", false))
+	assert.Equal(t, "OUTER.INNER.NAME", name.GetTagPath("env", false))
+
+	missingParent := getMockField(`env:"NAME"`, getMockField(`env:"INNER"`, getMockField(``, nil)))
+	assert.Equal(t, "", missingParent.GetTagPathWithSep("env", "__", false))
+	assert.Equal(t, "INNER__NAME", missingParent.GetTagPathWithSep("env", "__", true))
+}
+
+func TestField_GetTagPathFunc(t *testing.T) {
+	name := getMockField(`env:"name"`, getMockField(`env:"inner"`, getMockField(`env:"outer"`, nil)))
+	assert.Equal(t, "OUTER_INNER_NAME", name.GetTagPathFunc("env", strings.ToUpper, "_", false))
+	assert.Equal(t, "outer.inner.name", name.GetTagPathFunc("env", nil, ".", false))
+
+	var segments []string
+	name.GetTagPathFunc("env", func(s string) string {
+		segments = append(segments, s)
+		return s
+	}, "_", false)
+	assert.ElementsMatch(t, []string{"outer", "inner", "name"}, segments)
+
+	missingParent := getMockField(`env:"name"`, getMockField(``, nil))
+	assert.Equal(t, "", missingParent.GetTagPathFunc("env", strings.ToUpper, "_", false))
+	assert.Equal(t, "NAME", missingParent.GetTagPathFunc("env", strings.ToUpper, "_", true))
+}
+
+func TestField_IsTagSkipped(t *testing.T) {
+	skipped := getMockField(`json:"-"`, nil)
+	assert.True(t, skipped.IsTagSkipped("json"))
+	assert.False(t, skipped.IsTagSkipped("xml"))
+	assert.Equal(t, "", skipped.GetTagPath("json", true))
+
+	child := getMockField(`json:"name"`, getMockField(`json:"-"`, nil))
+	assert.True(t, child.IsTagSkipped("json"))
+	assert.Equal(t, "", child.GetTagPath("json", true))
+	assert.Equal(t, "", child.GetTagPathWithSep("json", "__", false))
+
+	dashed := getMockField(`json:"-,"`, nil)
+	assert.False(t, dashed.IsTagSkipped("json"))
+	assert.Equal(t, "-", dashed.GetTagPath("json", false))
+}
+
+func TestField_GetKind(t *testing.T) {
+	type testStruct struct {
+		Int    int
+		Ptr    *string
+		PtrPtr **int
+		Slice  []bool
+		Array  [2]float64
+		Map    map[string]uint8
+		Chan   chan int
+		Nested NestedStruct
+	}
+	fields, _ := Get[testStruct]()
+	for path, expected := range map[string]struct {
+		kind, elem reflect.Kind
+		isPointer  bool
+	}{
+		"Int":    {reflect.Int, reflect.Invalid, false},
+		"Ptr":    {reflect.Ptr, reflect.String, true},
+		"PtrPtr": {reflect.Ptr, reflect.Ptr, true},
+		"Slice":  {reflect.Slice, reflect.Bool, false},
+		"Array":  {reflect.Array, reflect.Float64, false},
+		"Map":    {reflect.Map, reflect.Uint8, false},
+		"Chan":   {reflect.Chan, reflect.Invalid, false},
+		"Nested": {reflect.Struct, reflect.Invalid, false},
+	} {
+		fld := fields.MustFind(path)
+		assert.Equal(t, expected.kind, fld.GetKind(), path)
+		assert.Equal(t, expected.elem, fld.ElemKind(), path)
+		assert.Equal(t, expected.isPointer, fld.IsPointer(), path)
+	}
+}
+
+func TestField_GetAlign(t *testing.T) {
+	type testStruct struct {
+		Bool   bool
+		Int64  int64
+		Int32  int32
+		String string
+		Nested NestedStruct
+		Array  [3]uint16
+	}
+	fields, _ := Get[testStruct]()
+	for _, path := range fields.GetAllPaths() {
+		fld := fields.MustFind(path)
+		assert.Equal(t, uintptr(fld.GetType().Align()), fld.GetAlign(), path)
+		assert.Equal(t, uintptr(fld.GetType().FieldAlign()), fld.GetFieldAlign(), path)
+		assert.Zero(t, fld.GetOffset()%fld.GetFieldAlign(), path)
+	}
+	assert.Equal(t, uintptr(1), fields.MustFind("Bool").GetAlign())
+	assert.Equal(t, unsafe.Alignof(int64(0)), fields.MustFind("Int64").GetFieldAlign())
+	assert.Equal(t, uintptr(2), fields.MustFind("Array").GetAlign())
+}
+
+func TestField_GetSize(t *testing.T) {
+	type testStruct struct {
+		Bool   bool
+		Int64  int64
+		Int32  int32
+		String string
+		Array  [3]uint16
+		Nested NestedStruct
+	}
+	fields, _ := Get[testStruct]()
+	for _, path := range fields.GetAllPaths() {
+		fld := fields.MustFind(path)
+		assert.Equal(t, fld.GetType().Size(), fld.GetSize(), path)
+		assert.Equal(t, fld.GetOffset()+fld.GetSize(), fld.GetEnd(), path)
+	}
+	assert.Equal(t, uintptr(1), fields.MustFind("Bool").GetSize())
+	assert.Equal(t, uintptr(6), fields.MustFind("Array").GetSize())
+	// the padding after the Bool field isn't included
+	assert.Equal(t, uintptr(1), fields.MustFind("Bool").GetEnd())
+	assert.LessOrEqual(t, fields.MustFind("Bool").GetEnd(), fields.MustFind("Int64").GetOffset())
+	assert.Equal(t, unsafe.Sizeof(testStruct{}.String), fields.MustFind("String").GetSize())
+}
+
+func TestField_GetByIndex(t *testing.T) {
+	type inner struct {
+		Value int
+		token string
+	}
+	type testStruct struct {
+		Name  string
+		Inner inner
+		Timestamps
+		*embeddedRoot
+	}
+	fields, err := GetFrom(testStruct{}, WithIncludeUnexported())
+	assert.NoError(t, err)
+	typeOf := reflect.TypeOf(testStruct{})
+	assert.Equal(t, []int{1, 1}, fields.MustFind("Inner.token").GetIndex())
+	assert.Equal(t, []int{3, 1, 1, 0}, fields.MustFind("embeddedRoot.embeddedMiddle.embeddedLeaf.Value").GetIndex())
+
+	objs := map[string]*testStruct{
+		"NilEmbedded": {Name: "name", Inner: inner{Value: 1, token: "token"}},
+		"Filled": {
+			Name:       "name",
+			Inner:      inner{Value: 1, token: "token"},
+			Timestamps: Timestamps{CreatedAt: time.Now(), Version: 2},
+			embeddedRoot: &embeddedRoot{Name: "root", embeddedMiddle: &embeddedMiddle{
+				ID:           3,
+				embeddedLeaf: embeddedLeaf{Value: "value", Count: 4},
+			}},
+		},
+	}
+	for name, obj := range objs {
+		for _, path := range fields.GetAllPaths() {
+			fld := fields.MustFind(path)
+			assert.Equal(t, fld.GetName(), typeOf.FieldByIndex(fld.GetIndex()).Name, path)
+			assert.Equal(t, fld.Get(obj), fld.GetByIndex(obj), name+" "+path)
+		}
+	}
+	assert.Equal(t, "value", fields.MustFind("embeddedRoot.embeddedMiddle.embeddedLeaf.Value").GetByIndex(objs["Filled"]))
+	assert.Equal(t, "", fields.MustFind("embeddedRoot.embeddedMiddle.embeddedLeaf.Value").GetByIndex(objs["NilEmbedded"]))
+	assert.Nil(t, objs["NilEmbedded"].embeddedRoot)
+	assert.Panics(t, func() { fields.MustFind("Name").GetByIndex(testStruct{}) })
+}
+
+func TestField_SetNil(t *testing.T) {
+	type testStruct struct {
+		Ptr       *int
+		PtrString *string
+		Slice     []string
+		Map       map[string]int
+		Chan      chan int
+		Func      func()
+		Iface     any
+		Int       int
+		String    string
+		Struct    NestedStruct
+	}
+	fields, _ := Get[testStruct]()
+	value := 1
+	str := "str"
+	newObj := func() *testStruct {
+		return &testStruct{
+			Ptr:       &value,
+			PtrString: &str,
+			Slice:     []string{"a"},
+			Map:       map[string]int{"a": 1},
+			Chan:      make(chan int),
+			Func:      func() {},
+			Iface:     1,
+			Int:       1,
+			String:    "string",
+		}
+	}
+	for _, path := range []string{"Ptr", "PtrString", "Slice", "Map", "Chan", "Func", "Iface"} {
+		t.Run(path, func(t *testing.T) {
+			fld := fields.MustFind(path)
+			obj := newObj()
+			assert.False(t, fld.IsZero(obj))
+			fld.Set(obj, nil)
+			assert.True(t, fld.IsZero(obj))
+			assert.Nil(t, fld.Get(obj))
+
+			obj = newObj()
+			assert.NoError(t, fld.TrySet(obj, nil))
+			assert.True(t, fld.IsZero(obj))
+		})
+	}
+	for _, path := range []string{"Int", "String", "Struct"} {
+		t.Run(path, func(t *testing.T) {
+			fld := fields.MustFind(path)
+			obj := newObj()
+			assert.PanicsWithError(t, "fmap: field "+path+": value of type <nil> is not assignable to "+fld.GetType().String(), func() {
+				fld.Set(obj, nil)
+			})
+			assert.Error(t, fld.TrySet(obj, nil))
+			assert.Equal(t, newObj().Int, obj.Int)
+		})
+	}
+}
+
+func TestField_MultiPointer(t *testing.T) {
+	type testStruct struct {
+		Int    **int
+		String ***string
+		Ptr    *int
+	}
+	fields, _ := Get[testStruct]()
+	intFld, stringFld := fields.MustFind("Int"), fields.MustFind("String")
+
+	t.Run("Get", func(t *testing.T) {
+		obj := &testStruct{}
+		assert.Equal(t, (**int)(nil), intFld.Get(obj))
+		assert.Equal(t, (***string)(nil), stringFld.Get(obj))
+		value := 5
+		ptr := &value
+		obj.Int = &ptr
+		assert.Same(t, obj.Int, intFld.Get(obj))
+		deref, ok := intFld.GetDereferenced(obj)
+		assert.True(t, ok)
+		assert.Equal(t, 5, deref)
+	})
+	t.Run("SetAllocates", func(t *testing.T) {
+		obj := &testStruct{}
+		intFld.Set(obj, 5)
+		assert.Equal(t, 5, **obj.Int)
+		stringFld.Set(obj, "value")
+		assert.Equal(t, "value", ***obj.String)
+		fields.MustFind("Ptr").Set(obj, 7)
+		assert.Equal(t, 7, *obj.Ptr)
+	})
+	t.Run("SetThroughPointers", func(t *testing.T) {
+		value := 1
+		ptr := &value
+		obj := &testStruct{Int: &ptr}
+		intFld.Set(obj, 2)
+		assert.Equal(t, 2, value)
+		assert.Same(t, ptr, *obj.Int)
+
+		other := 3
+		intFld.Set(obj, &other)
+		assert.Same(t, &other, *obj.Int)
+		assert.Equal(t, 2, value)
+
+		otherPtr := &other
+		intFld.Set(obj, &otherPtr)
+		assert.Same(t, &otherPtr, obj.Int)
+	})
+	t.Run("SetPartiallyNil", func(t *testing.T) {
+		var inner **string
+		obj := &testStruct{String: &inner}
+		stringFld.Set(obj, "value")
+		assert.Same(t, &inner, obj.String)
+		assert.Equal(t, "value", **inner)
+	})
+	t.Run("TrySet", func(t *testing.T) {
+		obj := &testStruct{}
+		assert.NoError(t, stringFld.TrySet(obj, "value"))
+		assert.Equal(t, "value", ***obj.String)
+		assert.Error(t, stringFld.TrySet(obj, 5))
+		assert.Panics(t, func() { intFld.Set(obj, "value") })
+		assert.NoError(t, intFld.TrySet(obj, nil))
+		assert.Nil(t, obj.Int)
+	})
+}
+
+func TestField_Clone(t *testing.T) {
+	fields, _ := Get[embeddedRoot]()
+	for _, path := range fields.GetAllPaths() {
+		fld := fields.MustFind(path)
+		clone := fld.Clone()
+		assert.NotSame(t, fld, clone, path)
+		assert.Equal(t, fld, clone, path)
+		assert.Equal(t, fld.GetStructPath(), clone.GetStructPath(), path)
+		if fld.GetParent() != nil {
+			assert.NotSame(t, fld.GetParent(), clone.GetParent(), path)
+			assert.Equal(t, fld.GetParent().GetStructPath(), clone.GetParent().GetStructPath(), path)
+		}
+	}
+
+	value := fields.MustFind("embeddedMiddle.embeddedLeaf.Value").Clone()
+	obj := &embeddedRoot{}
+	value.Set(obj, "value")
+	assert.Equal(t, "value", obj.Value)
+	assert.Equal(t, "value", value.Get(obj))
+
+	clone := fields.MustFind("Name").Clone()
+	assert.Nil(t, clone.GetParent())
+	clone.GetIndex()[0] = 100
+	assert.Equal(t, []int{0}, fields.MustFind("Name").GetIndex())
+
+	syncClone := Synchronized(fields).MustFind("Name").Clone()
+	assert.IsType(t, &SyncField{}, syncClone)
+	syncClone.Set(obj, "name")
+	assert.Equal(t, "name", obj.Name)
+}
+
+func TestField_SetDefault(t *testing.T) {
+	type testStruct struct {
+		Port    int
+		Host    string
+		Timeout *int
+		Tags    []string
+	}
+	fields, _ := Get[testStruct]()
+	zero, timeout := 0, 10
+
+	obj := &testStruct{Host: "localhost", Timeout: &zero}
+	assert.True(t, fields.MustFind("Port").SetDefault(obj, 8080))
+	assert.False(t, fields.MustFind("Host").SetDefault(obj, "example.com"))
+	// the pointer to the zero value is not zero
+	assert.False(t, fields.MustFind("Timeout").SetDefault(obj, &timeout))
+	assert.True(t, fields.MustFind("Tags").SetDefault(obj, []string{"a"}))
+	assert.Equal(t, &testStruct{Port: 8080, Host: "localhost", Timeout: &zero, Tags: []string{"a"}}, obj)
+
+	obj = &testStruct{}
+	assert.True(t, fields.MustFind("Timeout").SetDefault(obj, &timeout))
+	assert.Same(t, &timeout, obj.Timeout)
+	assert.False(t, fields.MustFind("Timeout").SetDefault(obj, &zero))
+	assert.Panics(t, func() { fields.MustFind("Port").SetDefault(obj, "8080") })
+
+	var changes int
+	hooked := WithSetHook(Synchronized(fields), func(Field, any, any, any) { changes++ })
+	assert.True(t, hooked.MustFind("Port").SetDefault(obj, 1))
+	assert.False(t, hooked.MustFind("Port").SetDefault(obj, 2))
+	assert.Equal(t, 1, obj.Port)
+	assert.Equal(t, 1, changes)
+}
+
+func TestField_GetTagOr(t *testing.T) {
+	type testStruct struct {
+		Name  string `json:"name,omitempty" scope:"private"`
+		Empty string `scope:""`
+	}
+	fields, _ := Get[testStruct]()
+	name := fields.MustFind("Name")
+	assert.Equal(t, "private", name.GetTagOr("scope", "public"))
+	assert.Equal(t, "name,omitempty", name.GetTagOr("json", ""))
+	assert.Equal(t, "public", name.GetTagOr("db", "public"))
+	assert.Equal(t, "", fields.MustFind("Empty").GetTagOr("scope", "public"))
+}
+
+type validator interface {
+	Validate() error
+}
+
+type valueValidator struct{}
+
+func (valueValidator) Validate() error { return nil }
+
+type ptrValidator struct{}
+
+func (*ptrValidator) Validate() error { return nil }
+
+func TestField_Implements(t *testing.T) {
+	type testStruct struct {
+		Value    valueValidator
+		Ptr      ptrValidator
+		PtrToPtr *ptrValidator
+		Iface    validator
+		Int      int
+	}
+	fields, _ := Get[testStruct]()
+	iface := reflect.TypeOf((*validator)(nil)).Elem()
+	for path, expected := range map[string][2]bool{
+		"Value":    {true, true},
+		"Ptr":      {false, true},
+		"PtrToPtr": {true, true},
+		"Iface":    {true, true},
+		"Int":      {false, false},
+	} {
+		assert.Equal(t, expected[0], fields.MustFind(path).Implements(iface), path)
+		assert.Equal(t, expected[1], fields.MustFind(path).PtrImplements(iface), path)
+	}
+
+	obj := &testStruct{}
+	var validated []string
+	for _, path := range fields.GetAllPaths() {
+		fld := fields.MustFind(path)
+		if fld.PtrImplements(iface) && !fld.Implements(iface) {
+			assert.NoError(t, fld.GetPtr(obj).(validator).Validate())
+			validated = append(validated, path)
+		}
+	}
+	assert.Equal(t, []string{"Ptr"}, validated)
+	assert.Panics(t, func() { fields.MustFind("Int").Implements(reflect.TypeOf(0)) })
+}
+
+func TestField_GetTagOptions(t *testing.T) {
+	type testStruct struct {
+		Name  string `json:"name,omitempty,string" db:"name"`
+		Empty string `json:",omitempty"`
+		Plain string
+	}
+	fields, _ := Get[testStruct]()
+	name := fields.MustFind("Name")
+	assert.Equal(t, []string{"omitempty", "string"}, name.GetTagOptions("json"))
+	assert.Nil(t, name.GetTagOptions("db"))
+	assert.Nil(t, name.GetTagOptions("yaml"))
+	assert.True(t, name.HasTagOption("json", "omitempty"))
+	assert.True(t, name.HasTagOption("json", "string"))
+	assert.False(t, name.HasTagOption("json", "name"))
+	assert.False(t, name.HasTagOption("db", "omitempty"))
+	assert.Equal(t, []string{"omitempty"}, fields.MustFind("Empty").GetTagOptions("json"))
+	assert.True(t, fields.MustFind("Empty").HasTagOption("json", "omitempty"))
+	assert.Nil(t, fields.MustFind("Plain").GetTagOptions("json"))
+}
+
+func TestField_IsZero(t *testing.T) {
+	type testStruct struct {
+		Int    int
+		String string
+		Ptr    *int
+		Slice  []string
+		Nested NestedStruct
+		Time   time.Time
+	}
+	fields, _ := Get[testStruct]()
+	zero := &testStruct{}
+	for _, path := range fields.GetAllPaths() {
+		assert.True(t, fields.MustFind(path).IsZero(zero), path)
+	}
+	obj := &testStruct{Int: 1, String: "a", Ptr: new(int), Slice: []string{}, Time: time.Now()}
+	obj.Nested.String = "a"
+	for _, path := range []string{"Int", "String", "Ptr", "Slice", "Nested", "Nested.String", "Time"} {
+		assert.False(t, fields.MustFind(path).IsZero(obj), path)
+	}
+	assert.True(t, fields.MustFind("Nested.PtrString").IsZero(obj))
+	assert.Panics(t, func() { fields.MustFind("Int").IsZero(testStruct{}) })
+}
+
 func TestField_GetDereferenced(t *testing.T) {
 	s := "field1 value"
 	type testStruct struct {
@@ -405,7 +1067,7 @@ func TestField_GetDereferenced(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, ok := tt.field.GetDereferenced(testObj)
+			_, ok := tt.field.GetDereferenced(&testObj)
 			if ok != tt.expected {
 				t.Errorf("Got %t, want %t", ok, tt.expected)
 			}
@@ -469,6 +1131,43 @@ func TestField_GetDereferencedType(t *testing.T) {
 	}
 }
 
+func TestField_EnsureNonNil(t *testing.T) {
+	type testStruct struct {
+		Nested *NestedStruct
+		PtrPtr **int
+		Int    int
+	}
+	fields, _ := Get[testStruct]()
+	nested := fields.MustFind("Nested")
+
+	t.Run("Nil", func(t *testing.T) {
+		obj := &testStruct{}
+		ptr := nested.EnsureNonNil(obj).(*NestedStruct)
+		assert.NotNil(t, obj.Nested)
+		assert.Same(t, obj.Nested, ptr)
+		assert.Equal(t, NestedStruct{}, *obj.Nested)
+		ptr.String = "test"
+		assert.Equal(t, "test", obj.Nested.String)
+	})
+	t.Run("NonNil", func(t *testing.T) {
+		existing := &NestedStruct{String: "existing"}
+		obj := &testStruct{Nested: existing}
+		assert.Same(t, existing, nested.EnsureNonNil(obj))
+		assert.Same(t, existing, obj.Nested)
+	})
+	t.Run("PtrPtr", func(t *testing.T) {
+		obj := &testStruct{}
+		ptr := fields.MustFind("PtrPtr").EnsureNonNil(obj).(**int)
+		assert.Same(t, obj.PtrPtr, ptr)
+		assert.Nil(t, *obj.PtrPtr)
+	})
+	t.Run("NotPointer", func(t *testing.T) {
+		assert.PanicsWithError(t, "fmap: field Int: not supported type: int, only pointer is supported", func() {
+			fields.MustFind("Int").EnsureNonNil(&testStruct{})
+		})
+	})
+}
+
 func TestField_ReflectStructField(t *testing.T) {
 	fields, _ := GetFrom(&struct {
 		TestField string
@@ -483,3 +1182,362 @@ func TestField_ReflectStructField(t *testing.T) {
 	fld.GetOffset()
 	fld.GetPkgPath()
 }
+
+func TestField_PointerGuard(t *testing.T) {
+	type testStruct struct {
+		Name string
+	}
+	fields, _ := Get[testStruct]()
+	fld := fields.MustFind("Name")
+
+	t.Run("GetValue", func(t *testing.T) {
+		assert.PanicsWithError(t, "fmap: field Name: not supported object type: fmap.testStruct, only ptr to struct is supported", func() {
+			fld.Get(testStruct{})
+		})
+	})
+	t.Run("SetValue", func(t *testing.T) {
+		assert.Panics(t, func() { fld.Set(testStruct{}, "test") })
+	})
+	t.Run("SetNilPointer", func(t *testing.T) {
+		assert.PanicsWithError(t, "fmap: field Name: object is a nil pointer", func() {
+			fld.Set((*testStruct)(nil), "test")
+		})
+	})
+	t.Run("TryGet", func(t *testing.T) {
+		_, err := fld.TryGet(testStruct{})
+		assert.Error(t, err)
+		_, err = fld.TryGet(nil)
+		assert.Error(t, err)
+		val, err := fld.TryGet(&testStruct{Name: "test"})
+		assert.NoError(t, err)
+		assert.Equal(t, "test", val)
+	})
+	t.Run("TrySet", func(t *testing.T) {
+		obj := &testStruct{}
+		assert.Error(t, fld.TrySet(*obj, "test"))
+		assert.Error(t, fld.TrySet(obj, 5))
+		assert.Error(t, fld.TrySet(obj, nil))
+		assert.NoError(t, fld.TrySet(obj, "test"))
+		assert.Equal(t, "test", obj.Name)
+	})
+}
+
+func TestField_StructValueGuard(t *testing.T) {
+	type small struct {
+		Name string
+	}
+	type large struct {
+		Name string
+		Data [64]int
+	}
+	for _, obj := range []struct {
+		value, ptr any
+	}{
+		{small{Name: "test"}, &small{Name: "test"}},
+		{large{Name: "test"}, &large{Name: "test"}},
+	} {
+		fields, _ := GetFrom(obj.value)
+		fld := fields.MustFind("Name")
+		typeName := reflect.TypeOf(obj.value).String()
+		msg := "fmap: field Name: not supported object type: " + typeName + ", only ptr to struct is supported"
+
+		// the struct value is rejected by every accessor instead of reading or writing its copy
+		assert.PanicsWithError(t, msg, func() { fld.Get(obj.value) }, typeName)
+		assert.PanicsWithError(t, msg, func() { fld.Set(obj.value, "new") }, typeName)
+		assert.PanicsWithError(t, msg, func() { fld.GetPtr(obj.value) }, typeName)
+		assert.PanicsWithError(t, msg, func() { fld.GetReflectValue(obj.value) }, typeName)
+		assert.PanicsWithError(t, msg, func() { fld.GetAsString(obj.value) }, typeName)
+		assert.PanicsWithError(t, msg, func() { fld.Equal(obj.value, obj.ptr) }, typeName)
+		_, err := fld.TryGet(obj.value)
+		assert.EqualError(t, err, msg, typeName)
+		assert.EqualError(t, fld.TrySet(obj.value, "new"), msg, typeName)
+		assert.EqualError(t, fld.SetConvert(obj.value, "new"), msg, typeName)
+		assert.EqualError(t, fld.SetFromString(obj.value, "new"), msg, typeName)
+
+		// the pointer works
+		assert.Equal(t, "test", fld.Get(obj.ptr), typeName)
+		fld.Set(obj.ptr, "new")
+		assert.Equal(t, "new", fld.Get(obj.ptr), typeName)
+		found, err := fields.GetFieldByPtr(obj.ptr, fld.GetPtr(obj.ptr))
+		assert.NoError(t, err, typeName)
+		assert.Same(t, fld, found, typeName)
+		_, err = fields.GetFieldByPtr(obj.value, fld.GetPtr(obj.ptr))
+		assert.Error(t, err, typeName)
+	}
+}
+
+func TestStorage_GetFieldByPtrGuard(t *testing.T) {
+	type structA struct {
+		Name string
+	}
+	type structB struct {
+		Name string
+	}
+	fields, _ := Get[structA]()
+	a, b := &structA{}, &structB{}
+	_, err := fields.GetFieldByPtr(a, nil)
+	assert.Error(t, err)
+	_, err = fields.GetFieldByPtr(nil, &a.Name)
+	assert.Error(t, err)
+	_, err = fields.GetFieldByPtr((*structA)(nil), &a.Name)
+	assert.Error(t, err)
+	// the struct of the other type is not matched by the offset
+	_, err = fields.GetFieldByPtr(b, &b.Name)
+	assert.Error(t, err)
+}
+
+func TestField_OwnerGuard(t *testing.T) {
+	type structA struct {
+		Name string
+	}
+	type structB struct {
+		Name string
+	}
+	fields, _ := Get[structA]()
+	fld := fields.MustFind("Name")
+
+	assert.PanicsWithError(t, "fmap: field Name: object type *fmap.structB doesn't match the field owner type fmap.structA", func() {
+		fld.Set(&structB{}, "test")
+	})
+	assert.Panics(t, func() { fld.Get(&structB{}) })
+	_, err := fld.TryGet(&structB{})
+	assert.Error(t, err)
+	assert.Error(t, fld.TrySet(&structB{}, "test"))
+	assert.NoError(t, fld.TrySet(&structA{}, "test"))
+}
+
+func TestField_String(t *testing.T) {
+	type Address struct {
+		City string `json:"city"`
+	}
+	type User struct {
+		ID      int
+		Address Address `json:"address"`
+	}
+	fields, _ := Get[User]()
+	fld := fields.MustFind("Address.City")
+	expected := fmt.Sprintf(`fmap.Field{path: "Address.City", type: string, offset: %d, tag: json:"city"}`, fld.GetOffset())
+	assert.Equal(t, expected, fld.String())
+	assert.Equal(t, expected, fmt.Sprint(fld))
+	assert.Equal(t, `fmap.Field{path: "", type: <nil>, offset: 0, tag: }`, (&field{}).String())
+}
+
+func TestField_GetStruct(t *testing.T) {
+	type Address struct {
+		City string
+	}
+	type User struct {
+		ID      int
+		Address Address
+	}
+	fields, _ := Get[User]()
+	assert.Equal(t, reflect.TypeOf(User{}), fields.MustFind("ID").GetStruct())
+	assert.Equal(t, reflect.TypeOf(User{}), fields.MustFind("Address").GetStruct())
+	assert.Equal(t, reflect.TypeOf(Address{}), fields.MustFind("Address.City").GetStruct())
+
+	ptrFields, _ := Get[embeddedRoot]()
+	assert.Equal(t, reflect.TypeOf(embeddedMiddle{}), ptrFields.MustFind("embeddedMiddle.ID").GetStruct())
+}
+
+func TestField_GetDepth(t *testing.T) {
+	fields, _ := Get[TestStruct]()
+	assert.Equal(t, 0, fields.MustFind("String").GetDepth())
+	assert.Equal(t, 0, fields.MustFind("NestedStruct").GetDepth())
+	assert.Equal(t, 1, fields.MustFind("NestedStruct.String").GetDepth())
+	for _, path := range fields.GetAllPaths() {
+		assert.Equal(t, strings.Count(path, "."), fields.MustFind(path).GetDepth(), path)
+	}
+	ptrFields, _ := Get[embeddedRoot]()
+	assert.Equal(t, 2, ptrFields.MustFind("embeddedMiddle.embeddedLeaf.Value").GetDepth())
+}
+
+func BenchmarkObjPointer(b *testing.B) {
+	var obj any = &TestStruct{}
+	b.Run("InterfaceDataWord", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			ptr := ((*[2]unsafe.Pointer)(unsafe.Pointer(&obj)))[1]
+			_ = ptr
+		}
+	})
+	b.Run("ReflectUnsafePointer", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			ptr := objPointer(obj)
+			_ = ptr
+		}
+	})
+}
+
+type (
+	defBool       bool
+	defInt        int
+	defInt8       int8
+	defInt16      int16
+	defInt32      int32
+	defInt64      int64
+	defUint       uint
+	defUint8      uint8
+	defUint16     uint16
+	defUint32     uint32
+	defUint64     uint64
+	defUintptr    uintptr
+	defFloat32    float32
+	defFloat64    float64
+	defComplex64  complex64
+	defComplex128 complex128
+	defString     string
+	defIntPtr     *int
+
+	aliasBool    = bool
+	aliasInt     = int
+	aliasInt8    = int8
+	aliasInt16   = int16
+	aliasInt32   = int32
+	aliasInt64   = int64
+	aliasUint    = uint
+	aliasUint8   = uint8
+	aliasUint16  = uint16
+	aliasUint32  = uint32
+	aliasUint64  = uint64
+	aliasFloat32 = float32
+	aliasFloat64 = float64
+	aliasString  = string
+)
+
+type declaredHolder[T any] struct {
+	Value T
+	Ptr   *T
+}
+
+// testDeclaredType checks that Get returns the value and the pointer of the declared field type T
+// and that Set accepts them back.
+func testDeclaredType[T any](t *testing.T, name string, val T) {
+	t.Run(name, func(t *testing.T) {
+		fields, err := Get[declaredHolder[T]]()
+		assert.NoError(t, err)
+		valueFld, ptrFld := fields.MustFind("Value"), fields.MustFind("Ptr")
+		obj := &declaredHolder[T]{Value: val, Ptr: &val}
+
+		got := valueFld.Get(obj)
+		assert.IsType(t, val, got)
+		assert.Equal(t, val, got)
+		gotPtr := ptrFld.Get(obj)
+		assert.IsType(t, &val, gotPtr)
+		assert.Same(t, &val, gotPtr)
+		assert.Equal(t, (*T)(nil), ptrFld.Get(&declaredHolder[T]{}))
+
+		dst := &declaredHolder[T]{}
+		valueFld.Set(dst, got)
+		ptrFld.Set(dst, gotPtr)
+		assert.Equal(t, obj, dst)
+	})
+}
+
+func TestField_DeclaredTypes(t *testing.T) {
+	t.Run("Definitions", func(t *testing.T) {
+		testDeclaredType(t, "bool", defBool(true))
+		testDeclaredType(t, "int", defInt(-1))
+		testDeclaredType(t, "int8", defInt8(-8))
+		testDeclaredType(t, "int16", defInt16(-16))
+		testDeclaredType(t, "int32", defInt32(-32))
+		testDeclaredType(t, "int64", defInt64(-64))
+		testDeclaredType(t, "uint", defUint(1))
+		testDeclaredType(t, "uint8", defUint8(8))
+		testDeclaredType(t, "uint16", defUint16(16))
+		testDeclaredType(t, "uint32", defUint32(32))
+		testDeclaredType(t, "uint64", defUint64(64))
+		testDeclaredType(t, "uintptr", defUintptr(0xff))
+		testDeclaredType(t, "float32", defFloat32(0.5))
+		testDeclaredType(t, "float64", defFloat64(-0.5))
+		testDeclaredType(t, "complex64", defComplex64(complex(1, 2)))
+		testDeclaredType(t, "complex128", defComplex128(complex(-1, 2)))
+		testDeclaredType(t, "string", defString("text"))
+		one := 1
+		testDeclaredType(t, "ptr", defIntPtr(&one))
+	})
+	t.Run("Aliases", func(t *testing.T) {
+		testDeclaredType(t, "bool", aliasBool(true))
+		testDeclaredType(t, "int", aliasInt(-1))
+		testDeclaredType(t, "int8", aliasInt8(-8))
+		testDeclaredType(t, "int16", aliasInt16(-16))
+		testDeclaredType(t, "int32", aliasInt32(-32))
+		testDeclaredType(t, "int64", aliasInt64(-64))
+		testDeclaredType(t, "uint", aliasUint(1))
+		testDeclaredType(t, "uint8", aliasUint8(8))
+		testDeclaredType(t, "uint16", aliasUint16(16))
+		testDeclaredType(t, "uint32", aliasUint32(32))
+		testDeclaredType(t, "uint64", aliasUint64(64))
+		testDeclaredType(t, "float32", aliasFloat32(0.5))
+		testDeclaredType(t, "float64", aliasFloat64(-0.5))
+		testDeclaredType(t, "string", aliasString("text"))
+	})
+	t.Run("ByteRune", func(t *testing.T) {
+		testDeclaredType(t, "byte", byte('b'))
+		testDeclaredType(t, "rune", rune('r'))
+
+		type testStruct struct {
+			Byte  byte
+			Rune  rune
+			Bytes []byte
+			Runes []rune
+		}
+		fields, _ := Get[testStruct]()
+		obj := &testStruct{Byte: 'b', Rune: 'ы', Bytes: []byte("b"), Runes: []rune("ы")}
+		for path, expected := range map[string]string{"Byte": "byte", "Rune": "rune", "Bytes": "[]byte", "Runes": "[]rune"} {
+			var kind string
+			switch fields.MustFind(path).Get(obj).(type) {
+			case byte:
+				kind = "byte"
+			case rune:
+				kind = "rune"
+			case []byte:
+				kind = "[]byte"
+			case []rune:
+				kind = "[]rune"
+			}
+			assert.Equal(t, expected, kind, path)
+		}
+		// the alias names aren't kept
+		assert.Equal(t, "uint8", fmt.Sprintf("%T", fields.MustFind("Byte").Get(obj)))
+		assert.Equal(t, "int32", fmt.Sprintf("%T", fields.MustFind("Rune").Get(obj)))
+		assert.Equal(t, reflect.TypeOf(uint8(0)), fields.MustFind("Byte").GetType())
+		assert.Equal(t, reflect.TypeOf(int32(0)), fields.MustFind("Rune").GetType())
+		assert.Equal(t, byte('b'), fields.MustFind("Byte").Get(obj))
+		assert.Equal(t, 'ы', fields.MustFind("Rune").Get(obj))
+	})
+	t.Run("NamedPtr", func(t *testing.T) {
+		type testStruct struct {
+			Ptr defIntPtr
+		}
+		fields, _ := Get[testStruct]()
+		one := 1
+		got := fields.MustFind("Ptr").Get(&testStruct{Ptr: &one})
+		assert.IsType(t, defIntPtr(nil), got)
+		assert.Equal(t, defIntPtr(&one), got)
+		assert.Equal(t, defIntPtr(nil), fields.MustFind("Ptr").Get(&testStruct{}))
+	})
+}
+
+func TestField_IsPromoted(t *testing.T) {
+	fields, _ := Get[promotedModel]()
+	promoted := map[string]bool{}
+	for _, path := range fields.GetAllPaths() {
+		promoted[path] = fields.MustFind(path).IsPromoted()
+	}
+	assert.Equal(t, map[string]bool{
+		"Timestamps":                 false,
+		"Timestamps.CreatedAt":       true,
+		"Timestamps.UpdatedAt":       true,
+		"Timestamps.Version":         true,
+		"Audit":                      false,
+		"Audit.Version":              true,
+		"Audit.Author":               true,
+		"ID":                         false,
+		"UpdatedAt":                  false,
+		"Inner":                      false,
+		"Inner.Timestamps":           false,
+		"Inner.Timestamps.CreatedAt": true,
+		"Inner.Timestamps.UpdatedAt": true,
+		"Inner.Timestamps.Version":   true,
+	}, promoted)
+	assert.True(t, fields.MustFind("Audit.Author").Clone().IsPromoted())
+}
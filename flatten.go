@@ -0,0 +1,123 @@
+package fmap
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Flatten returns the flat key/value representation of the object pointed to by obj, e.g. for the etcd or Consul
+// key/value stores: the scalar leaf fields formatted with Field.GetAsString keyed by the dotted tag paths,
+// e.g. the `database.host` key for the Host field of the Database struct. The missing parent tags are ignored.
+// The fields without the tag and the fields tagged or nested in the fields tagged with "-" are skipped,
+// if the tag is empty, all scalar leaves are keyed by their struct paths instead.
+// The scalars are the primitives, time.Duration, the encoding.TextMarshaler types and pointers to them,
+// i.e. the values that can be parsed back with Field.SetFromString, the composite leaves, e.g. slices and maps,
+// and the nil pointers are skipped.
+func Flatten(obj any, tag string) (map[string]string, error) {
+	kv := map[string]string{}
+	if err := FlattenInto(obj, tag, kv); err != nil {
+		return nil, err
+	}
+	return kv, nil
+}
+
+// FlattenInto is the Flatten variant that writes the key/values into the caller-provided non-nil dst map
+// instead of the new one, e.g. to reuse the map across the metrics scrapes of the same struct.
+// The caller owns clearing the dst: the keys already present in it are overwritten or left as is,
+// e.g. the key of the pointer field that became nil since the previous call isn't removed.
+func FlattenInto(obj any, tag string, dst map[string]string) error {
+	fields, err := getFromPtr(obj)
+	if err != nil {
+		return err
+	}
+	if dst == nil {
+		return fmt.Errorf("fmap: flatten: nil dst map")
+	}
+	for _, path := range fields.paths {
+		fld := fields.asMap[path].(*field)
+		if fld.hasChildren || !isScalarType(fld.Type) {
+			continue
+		}
+		key := tagKey(fld, tag)
+		if key == "" {
+			continue
+		}
+		val := reflect.NewAt(fld.Type, fld.getReadPtr(obj)).Elem()
+		if val.Kind() == reflect.Ptr && val.IsNil() {
+			continue
+		}
+		dst[key] = formatValue(val)
+	}
+	return nil
+}
+
+// Unflatten populates the object pointed to by obj from the flat key/values, the inverse of Flatten.
+// The keys are matched against the Flatten keys of the scalar leaf fields and the values are parsed
+// with Field.SetFromString, the pointer fields and the nil embedded struct pointers on the way are allocated.
+// The keys are applied in the sorted order, the first parse error is returned. The keys without the matching
+// field are returned as the *UnknownKeysError after all known keys are applied.
+func Unflatten(obj any, tag string, kv map[string]string) error {
+	fields, err := getFromPtr(obj)
+	if err != nil {
+		return err
+	}
+	index := make(map[string]*field, len(fields.paths))
+	for _, path := range fields.paths {
+		fld := fields.asMap[path].(*field)
+		if fld.hasChildren || !isScalarType(fld.Type) {
+			continue
+		}
+		if key := tagKey(fld, tag); key != "" {
+			index[key] = fld
+		}
+	}
+	keys := make([]string, 0, len(kv))
+	for key := range kv {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var unknown []string
+	for _, key := range keys {
+		fld, ok := index[key]
+		if !ok {
+			unknown = append(unknown, key)
+			continue
+		}
+		if err = fld.SetFromString(obj, kv[key]); err != nil {
+			return err
+		}
+	}
+	if len(unknown) > 0 {
+		return &UnknownKeysError{Keys: unknown}
+	}
+	return nil
+}
+
+// tagKey returns the tag path of the fld, or the struct path for the empty tag, used as the key by Flatten
+// and GetFromByTag. The empty key means the fld has no tag or is excluded with the "-" tag.
+func tagKey(fld *field, tag string) string {
+	if tag == "" {
+		return fld.structPath
+	}
+	return fld.GetTagPath(tag, true)
+}
+
+// isScalarType reports whether the typeOf values are formatted by formatValue so they can be parsed back by parseString.
+func isScalarType(typeOf reflect.Type) bool {
+	for typeOf.Kind() == reflect.Ptr {
+		typeOf = typeOf.Elem()
+	}
+	if typeOf.Implements(textMarshalerType) || reflect.PointerTo(typeOf).Implements(textMarshalerType) {
+		return reflect.PointerTo(typeOf).Implements(textUnmarshalerType)
+	}
+	switch typeOf.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
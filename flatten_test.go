@@ -0,0 +1,170 @@
+package fmap
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type flatDatabase struct {
+	Host    string        `kv:"host"`
+	Port    int           `kv:"port"`
+	Timeout time.Duration `kv:"timeout"`
+}
+
+type flatConfig struct {
+	Name     string        `kv:"name"`
+	Debug    bool          `kv:"debug"`
+	Ratio    *float64      `kv:"ratio"`
+	Missing  *int          `kv:"missing"`
+	IP       net.IP        `kv:"ip"`
+	Tags     []string      `kv:"tags"`
+	Password string        `kv:"-"`
+	Database flatDatabase  `kv:"database"`
+	Replica  *flatDatabase `kv:"replica"`
+	Internal flatDatabase  `kv:"-"`
+	Untagged string
+	Plain    struct {
+		Zip string `kv:"zip"`
+	}
+}
+
+func newFlatConfig() *flatConfig {
+	ratio := 0.5
+	cfg := &flatConfig{
+		Name:     "app",
+		Debug:    true,
+		Ratio:    &ratio,
+		IP:       net.ParseIP("10.0.0.1"),
+		Tags:     []string{"a"},
+		Password: "secret",
+		Database: flatDatabase{Host: "localhost", Port: 5432, Timeout: 5 * time.Second},
+		Replica:  &flatDatabase{Host: "replica"},
+		Internal: flatDatabase{Host: "internal"},
+		Untagged: "untagged",
+	}
+	cfg.Plain.Zip = "75001"
+	return cfg
+}
+
+func TestFlatten(t *testing.T) {
+	t.Run("Tag", func(t *testing.T) {
+		kv, err := Flatten(newFlatConfig(), "kv")
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			"name":             "app",
+			"debug":            "true",
+			"ratio":            "0.5",
+			"ip":               "10.0.0.1",
+			"database.host":    "localhost",
+			"database.port":    "5432",
+			"database.timeout": "5s",
+			"zip":              "75001",
+		}, kv)
+		// the pointer to struct is the composite leaf, only the embedded ones are expanded
+		assert.NotContains(t, kv, "replica")
+	})
+	t.Run("StructPaths", func(t *testing.T) {
+		kv, err := Flatten(newFlatConfig(), "")
+		assert.NoError(t, err)
+		assert.Equal(t, "secret", kv["Password"])
+		assert.Equal(t, "untagged", kv["Untagged"])
+		assert.Equal(t, "internal", kv["Internal.Host"])
+		assert.Equal(t, "75001", kv["Plain.Zip"])
+		assert.NotContains(t, kv, "Tags")
+		assert.NotContains(t, kv, "Missing")
+	})
+	t.Run("Errors", func(t *testing.T) {
+		_, err := Flatten(flatConfig{}, "kv")
+		assert.Error(t, err)
+	})
+}
+
+type flatEmbedded struct {
+	Region string `kv:"region"`
+}
+
+type flatEmbeddedConfig struct {
+	Name string `kv:"name"`
+	*flatEmbedded
+}
+
+func TestFlattenInto(t *testing.T) {
+	cfg := newFlatConfig()
+	expected, _ := Flatten(cfg, "kv")
+	kv := map[string]string{"stale": "value"}
+	assert.NoError(t, FlattenInto(cfg, "kv", kv))
+	assert.Equal(t, "value", kv["stale"])
+	delete(kv, "stale")
+	assert.Equal(t, expected, kv)
+
+	cfg.Name = "other"
+	assert.NoError(t, FlattenInto(cfg, "kv", kv))
+	assert.Equal(t, "other", kv["name"])
+
+	assert.Error(t, FlattenInto(cfg, "kv", nil))
+	assert.Error(t, FlattenInto(*cfg, "kv", kv))
+
+	allocs := testing.AllocsPerRun(10, func() {
+		_ = FlattenInto(cfg, "kv", kv)
+	})
+	assert.Less(t, allocs, testing.AllocsPerRun(10, func() {
+		_, _ = Flatten(cfg, "kv")
+	}))
+}
+
+func TestUnflatten(t *testing.T) {
+	t.Run("RoundTrip", func(t *testing.T) {
+		src := newFlatConfig()
+		kv, err := Flatten(src, "kv")
+		assert.NoError(t, err)
+		dst := &flatConfig{}
+		assert.NoError(t, Unflatten(dst, "kv", kv))
+		assert.Equal(t, src.Name, dst.Name)
+		assert.Equal(t, src.Debug, dst.Debug)
+		assert.Equal(t, *src.Ratio, *dst.Ratio)
+		assert.Nil(t, dst.Missing)
+		assert.Equal(t, src.IP, dst.IP)
+		assert.Equal(t, src.Database, dst.Database)
+		assert.Equal(t, src.Plain, dst.Plain)
+		assert.Empty(t, dst.Password)
+		assert.Empty(t, dst.Internal)
+	})
+	t.Run("EmbeddedPtr", func(t *testing.T) {
+		dst := &flatEmbeddedConfig{}
+		assert.NoError(t, Unflatten(dst, "kv", map[string]string{"name": "app", "region": "eu"}))
+		assert.Equal(t, "app", dst.Name)
+		assert.NotNil(t, dst.flatEmbedded)
+		assert.Equal(t, "eu", dst.Region)
+	})
+	t.Run("UnknownKeys", func(t *testing.T) {
+		dst := &flatConfig{}
+		err := Unflatten(dst, "kv", map[string]string{
+			"name":     "app",
+			"tags":     "a,b",
+			"Password": "secret",
+			"database": "db",
+			"unknown":  "1",
+		})
+		var unknownErr *UnknownKeysError
+		assert.ErrorAs(t, err, &unknownErr)
+		assert.Equal(t, []string{"Password", "database", "tags", "unknown"}, unknownErr.Keys)
+		assert.Equal(t, "app", dst.Name)
+	})
+	t.Run("ParseError", func(t *testing.T) {
+		dst := &flatConfig{}
+		err := Unflatten(dst, "kv", map[string]string{"database.port": "port", "name": "app"})
+		assert.EqualError(t, err, `fmap: field Database.Port: strconv.ParseInt: parsing "port": invalid syntax`)
+		// the keys are applied in the sorted order up to the first error
+		assert.Empty(t, dst.Name)
+		assert.Error(t, Unflatten(*dst, "kv", nil))
+	})
+	t.Run("StructPaths", func(t *testing.T) {
+		dst := &flatConfig{}
+		assert.NoError(t, Unflatten(dst, "", map[string]string{"Password": "secret", "Internal.Port": "1"}))
+		assert.Equal(t, "secret", dst.Password)
+		assert.Equal(t, 1, dst.Internal.Port)
+	})
+}
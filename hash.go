@@ -0,0 +1,190 @@
+package fmap
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"math"
+	"reflect"
+	"sort"
+	"unsafe"
+)
+
+var binaryMarshalerType = reflect.TypeOf((*encoding.BinaryMarshaler)(nil)).Elem()
+
+// HashFields writes the deterministic serialization of the leaf fields of the object pointed to by obj to the h,
+// e.g. to compute the content hash of the config and detect its changes across the restarts.
+// The fields are written in the declaration order, the serialization doesn't depend on the platform or the run:
+//   - the bools, integers, floats and complexes are written as the fixed-size little-endian values,
+//     int, uint and uintptr as 64-bit ones;
+//   - the strings, slices and maps are prefixed with their length, the nil and empty ones are written the same way;
+//   - the pointers and interfaces are written as the nil marker followed by the pointed value,
+//     the interfaces with the dynamic type name, so the pointer addresses don't affect the hash;
+//   - the map entries are sorted by their serialized keys, as the map iteration order is random;
+//   - the encoding.BinaryMarshaler and encoding.TextMarshaler values, e.g. time.Time, are written as their
+//     marshaled bytes, the other structs field by field, including the unexported ones.
+//
+// The fields behind the nil embedded struct pointers are written as zero values. It returns an error for the
+// chan, func and unsafe.Pointer values, the pointer cycles and the marshaling errors.
+func HashFields(obj any, h hash.Hash) error {
+	fields, err := getFromPtr(obj)
+	if err != nil {
+		return err
+	}
+	if objPointer(obj) == nil {
+		return fmt.Errorf("fmap: hash: can't hash the nil %v", reflect.TypeOf(obj))
+	}
+	hw := &hashWriter{active: map[unsafe.Pointer]bool{}}
+	for _, path := range fields.paths {
+		fld := fields.asMap[path].(*field)
+		if fld.hasChildren {
+			continue
+		}
+		hw.buf = hw.buf[:0]
+		if err = hw.value(reflect.NewAt(fld.Type, fld.getReadPtr(obj)).Elem()); err != nil {
+			return fmt.Errorf("fmap: field %s: %w", fld.structPath, err)
+		}
+		_, _ = h.Write(hw.buf)
+	}
+	return nil
+}
+
+// hashWriter appends the deterministic serialization of the values to the buf.
+type hashWriter struct {
+	buf []byte
+	// active are the pointers being serialized, to detect the cycles.
+	active map[unsafe.Pointer]bool
+}
+
+func (w *hashWriter) uint(v uint64) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], v)
+	w.buf = append(w.buf, b[:]...)
+}
+
+func (w *hashWriter) bytes(b []byte) {
+	w.uint(uint64(len(b)))
+	w.buf = append(w.buf, b...)
+}
+
+func (w *hashWriter) value(v reflect.Value) error {
+	if !v.CanInterface() && v.CanAddr() {
+		// the values of the unexported fields are read through the pointer to call their marshalers
+		v = reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem()
+	}
+	if v.CanInterface() && v.Kind() != reflect.Ptr && v.Kind() != reflect.Interface {
+		if ok, err := w.marshaler(v); ok || err != nil {
+			return err
+		}
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			w.buf = append(w.buf, 1)
+		} else {
+			w.buf = append(w.buf, 0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		w.uint(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		w.uint(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		w.uint(math.Float64bits(v.Float()))
+	case reflect.Complex64, reflect.Complex128:
+		w.uint(math.Float64bits(real(v.Complex())))
+		w.uint(math.Float64bits(imag(v.Complex())))
+	case reflect.String:
+		w.uint(uint64(v.Len()))
+		w.buf = append(w.buf, v.String()...)
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice {
+			w.uint(uint64(v.Len()))
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := w.value(v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		return w.mapValue(v)
+	case reflect.Ptr:
+		if v.IsNil() {
+			w.buf = append(w.buf, 0)
+			return nil
+		}
+		ptr := v.UnsafePointer()
+		if w.active[ptr] {
+			return fmt.Errorf("pointer cycle via %v", v.Type())
+		}
+		w.active[ptr] = true
+		defer delete(w.active, ptr)
+		w.buf = append(w.buf, 1)
+		return w.value(v.Elem())
+	case reflect.Interface:
+		if v.IsNil() {
+			w.buf = append(w.buf, 0)
+			return nil
+		}
+		w.buf = append(w.buf, 1)
+		w.bytes([]byte(v.Elem().Type().String()))
+		return w.value(v.Elem())
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if err := w.value(v.Field(i)); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("not supported type: %v", v.Type())
+	}
+	return nil
+}
+
+// marshaler writes the v implementing encoding.BinaryMarshaler or encoding.TextMarshaler
+// as the marshaled bytes and reports whether it did.
+func (w *hashWriter) marshaler(v reflect.Value) (bool, error) {
+	var data []byte
+	var err error
+	switch {
+	case v.Type().Implements(binaryMarshalerType):
+		data, err = v.Interface().(encoding.BinaryMarshaler).MarshalBinary()
+	case v.Type().Implements(textMarshalerType):
+		data, err = v.Interface().(encoding.TextMarshaler).MarshalText()
+	default:
+		return false, nil
+	}
+	if err != nil {
+		return true, err
+	}
+	w.bytes(data)
+	return true, nil
+}
+
+func (w *hashWriter) mapValue(v reflect.Value) error {
+	type entry struct {
+		key []byte
+		val reflect.Value
+	}
+	entries := make([]entry, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		kw := &hashWriter{active: w.active}
+		if err := kw.value(iter.Key()); err != nil {
+			return err
+		}
+		entries = append(entries, entry{key: kw.buf, val: iter.Value()})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return bytes.Compare(entries[i].key, entries[j].key) < 0
+	})
+	w.uint(uint64(len(entries)))
+	for _, e := range entries {
+		w.buf = append(w.buf, e.key...)
+		if err := w.value(e.val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
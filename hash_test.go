@@ -0,0 +1,105 @@
+package fmap
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type hashDatabase struct {
+	Host    string
+	Port    uint16
+	Timeout time.Duration
+}
+
+type hashConfig struct {
+	Name      string
+	Debug     bool
+	Ratio     *float64
+	Tags      []string
+	Limits    map[string]int
+	Extra     any
+	CreatedAt time.Time
+	Database  hashDatabase
+	Replica   *hashDatabase
+	secret    string
+}
+
+func newHashConfig() *hashConfig {
+	ratio := 0.5
+	return &hashConfig{
+		Name:      "app",
+		Debug:     true,
+		Ratio:     &ratio,
+		Tags:      []string{"a", "b"},
+		Limits:    map[string]int{"a": 1, "b": 2, "c": 3, "d": 4, "e": 5},
+		Extra:     int64(1),
+		CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Database:  hashDatabase{Host: "localhost", Port: 5432, Timeout: time.Second},
+		Replica:   &hashDatabase{Host: "replica"},
+		secret:    "secret",
+	}
+}
+
+func hashOf(t *testing.T, obj any) string {
+	h := sha256.New()
+	assert.NoError(t, HashFields(obj, h))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func TestHashFields(t *testing.T) {
+	t.Run("Deterministic", func(t *testing.T) {
+		expected := hashOf(t, newHashConfig())
+		for i := 0; i < 10; i++ {
+			// the maps are iterated in the random order and the pointers are allocated anew
+			assert.Equal(t, expected, hashOf(t, newHashConfig()))
+		}
+	})
+	t.Run("Changes", func(t *testing.T) {
+		expected := hashOf(t, newHashConfig())
+		for name, update := range map[string]func(cfg *hashConfig){
+			"Name":      func(cfg *hashConfig) { cfg.Name = "app2" },
+			"Ratio":     func(cfg *hashConfig) { *cfg.Ratio = 0.6 },
+			"RatioNil":  func(cfg *hashConfig) { cfg.Ratio = nil },
+			"Tags":      func(cfg *hashConfig) { cfg.Tags = []string{"ab"} },
+			"Limits":    func(cfg *hashConfig) { cfg.Limits["a"] = 0 },
+			"Extra":     func(cfg *hashConfig) { cfg.Extra = int32(1) },
+			"CreatedAt": func(cfg *hashConfig) { cfg.CreatedAt = cfg.CreatedAt.Add(time.Nanosecond) },
+			"Port":      func(cfg *hashConfig) { cfg.Database.Port++ },
+			"Replica":   func(cfg *hashConfig) { cfg.Replica.Port = 1 },
+		} {
+			cfg := newHashConfig()
+			update(cfg)
+			assert.NotEqual(t, expected, hashOf(t, cfg), name)
+		}
+		// the unexported fields aren't in the field map
+		cfg := newHashConfig()
+		cfg.secret = "other"
+		assert.Equal(t, expected, hashOf(t, cfg))
+		// the nil and empty slices are the same
+		cfg.Tags = nil
+		empty := newHashConfig()
+		empty.Tags = []string{}
+		assert.Equal(t, hashOf(t, empty), hashOf(t, cfg))
+	})
+	t.Run("Errors", func(t *testing.T) {
+		type withFunc struct {
+			Func func()
+		}
+		type node struct {
+			Next *node
+		}
+		type withCycle struct {
+			Node node
+		}
+		assert.EqualError(t, HashFields(&withFunc{}, sha256.New()), "fmap: field Func: not supported type: func()")
+		cycle := &withCycle{}
+		cycle.Node.Next = &cycle.Node
+		assert.Error(t, HashFields(cycle, sha256.New()))
+		assert.Error(t, HashFields(hashConfig{}, sha256.New()))
+		assert.Error(t, HashFields((*hashConfig)(nil), sha256.New()))
+	})
+}
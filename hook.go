@@ -0,0 +1,143 @@
+package fmap
+
+import "reflect"
+
+// SetWithHook updates the value of the field in the provided object like Set does and calls the hook
+// with the field values before and after the update, e.g. for the dirty fields tracking.
+// The nil hook is ignored. It panics like Set does, the hook isn't called then.
+func (f *field) SetWithHook(obj any, val any, hook func(old, new any)) {
+	old := f.Get(obj)
+	f.Set(obj, val)
+	if hook != nil {
+		hook(old, f.Get(obj))
+	}
+}
+
+// SetHook is called by the HookField after the successful update of the fld in the obj
+// with the field values before and after the update.
+type SetHook func(fld Field, obj any, old, new any)
+
+// HookField is a Field wrapper that calls the SetHook after every successful update of the field value through
+// Set, TrySet, SetConvert, SetFromString, SetFromJSON, SetReflectValue, TrySetReflectValue, SetReflectValueConvert, SetWithHook and SetDefault.
+// The in place updates, e.g. SetSliceIndex, SetMapKey, SetBit, SetRaw and writes through GetPtr, don't call the hook.
+type HookField struct {
+	Field
+	hook SetHook
+}
+
+// NewHookField returns the HookField calling the hook after the updates of the fld.
+func NewHookField(fld Field, hook SetHook) *HookField {
+	return &HookField{Field: fld, hook: hook}
+}
+
+// set reads the old value, calls the set and fires the hook if the set succeeded.
+// The obj is checked by TryGet before, the invalid one, e.g. nil or of the other type, is passed to the set as is,
+// so the wrapped setter reports it like without the hook, e.g. Set panics and TrySet returns the error.
+func (f *HookField) set(obj any, set func() error) error {
+	old, err := f.Field.TryGet(obj)
+	if err != nil {
+		return set()
+	}
+	if err := set(); err != nil {
+		return err
+	}
+	f.hook(f.Field, obj, old, f.Field.Get(obj))
+	return nil
+}
+
+// Set updates the value of the field in the provided object and calls the hook.
+func (f *HookField) Set(obj any, val any) {
+	_ = f.set(obj, func() error {
+		f.Field.Set(obj, val)
+		return nil
+	})
+}
+
+// TrySet updates the value of the field in the provided object and calls the hook if there is no error.
+func (f *HookField) TrySet(obj any, val any) error {
+	return f.set(obj, func() error {
+		return f.Field.TrySet(obj, val)
+	})
+}
+
+// SetConvert updates the value of the field in the provided object and calls the hook if there is no error.
+func (f *HookField) SetConvert(obj any, val any) error {
+	return f.set(obj, func() error {
+		return f.Field.SetConvert(obj, val)
+	})
+}
+
+// SetFromString updates the value of the field in the provided object and calls the hook if there is no error.
+func (f *HookField) SetFromString(obj any, s string) error {
+	return f.set(obj, func() error {
+		return f.Field.SetFromString(obj, s)
+	})
+}
+
+// SetFromJSON updates the value of the field in the provided object and calls the hook if there is no error.
+func (f *HookField) SetFromJSON(obj any, data []byte) error {
+	return f.set(obj, func() error {
+		return f.Field.SetFromJSON(obj, data)
+	})
+}
+
+// SetReflectValue updates the value of the field in the provided object and calls the hook.
+func (f *HookField) SetReflectValue(obj any, v reflect.Value) {
+	_ = f.set(obj, func() error {
+		f.Field.SetReflectValue(obj, v)
+		return nil
+	})
+}
+
+// TrySetReflectValue updates the value of the field in the provided object and calls the hook if there is no error.
+func (f *HookField) TrySetReflectValue(obj any, v reflect.Value) error {
+	return f.set(obj, func() error {
+		return f.Field.TrySetReflectValue(obj, v)
+	})
+}
+
+// SetReflectValueConvert updates the value of the field in the provided object and calls the hook if there is no error.
+func (f *HookField) SetReflectValueConvert(obj any, v reflect.Value) error {
+	return f.set(obj, func() error {
+		return f.Field.SetReflectValueConvert(obj, v)
+	})
+}
+
+// SetWithHook updates the value of the field in the provided object and calls both the hook and the HookField one.
+func (f *HookField) SetWithHook(obj any, val any, hook func(old, new any)) {
+	f.Field.SetWithHook(obj, val, func(old, new any) {
+		if hook != nil {
+			hook(old, new)
+		}
+		f.hook(f.Field, obj, old, new)
+	})
+}
+
+// SetDefault sets the val to the zero field in the provided object and calls the hook if it was set.
+func (f *HookField) SetDefault(obj any, val any) bool {
+	if !f.Field.IsZero(obj) {
+		return false
+	}
+	f.Set(obj, val)
+	return true
+}
+
+// checkSet checks the update of the wrapped field, see SetAll.
+func (f *HookField) checkSet(obj any, val any) error {
+	return checkSet(f.Field, obj, val)
+}
+
+// Clone returns the HookField calling the same hook after the updates of the clone of the wrapped field.
+func (f *HookField) Clone() Field {
+	return NewHookField(f.Field.Clone(), f.hook)
+}
+
+// WithSetHook returns the Storage which fields are HookField calling the hook after every update through them.
+// It's useful for the change tracking, e.g. collecting the dirty fields, without wrapping each setter.
+// The wrapped Storage is not modified, so the cached Storage can still be used without the hook.
+// The Synchronized storage can be wrapped too, the old value is read and the new one is written under separate locks then.
+func WithSetHook(s Storage, hook SetHook) Storage {
+	return wrapStorage(s, func(fld Field) Field {
+		return NewHookField(fld, hook)
+	})
+}
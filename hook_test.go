@@ -0,0 +1,133 @@
+package fmap
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type hookUser struct {
+	Name    string
+	Age     int
+	Nick    *string
+	Address struct {
+		City string
+	}
+}
+
+func TestField_SetWithHook(t *testing.T) {
+	fields, _ := Get[hookUser]()
+	user := &hookUser{Name: "old"}
+	var calls [][2]any
+	fields.MustFind("Name").SetWithHook(user, "new", func(old, new any) {
+		calls = append(calls, [2]any{old, new})
+	})
+	assert.Equal(t, "new", user.Name)
+	assert.Equal(t, [][2]any{{"old", "new"}}, calls)
+
+	fields.MustFind("Age").SetWithHook(user, 5, nil)
+	assert.Equal(t, 5, user.Age)
+
+	assert.Panics(t, func() {
+		fields.MustFind("Age").SetWithHook(user, "5", func(old, new any) {
+			t.Error("hook must not be called")
+		})
+	})
+}
+
+func TestWithSetHook(t *testing.T) {
+	fields, _ := Get[hookUser]()
+	type change struct {
+		path     string
+		old, new any
+	}
+	var changes []change
+	hooked := WithSetHook(fields, func(fld Field, obj any, old, new any) {
+		changes = append(changes, change{fld.GetStructPath(), old, new})
+	})
+
+	t.Run("Setters", func(t *testing.T) {
+		changes = nil
+		user := &hookUser{}
+		hooked.MustFind("Name").Set(user, "John")
+		assert.NoError(t, hooked.MustFind("Age").TrySet(user, 1))
+		assert.NoError(t, hooked.MustFind("Age").SetConvert(user, int64(2)))
+		assert.NoError(t, hooked.MustFind("Age").SetFromString(user, "3"))
+		hooked.MustFind("Address.City").SetReflectValue(user, reflect.ValueOf("Paris"))
+		assert.NoError(t, hooked.MustFind("Address.City").TrySetReflectValue(user, reflect.ValueOf("Rome")))
+		assert.NoError(t, hooked.MustFind("Age").SetReflectValueConvert(user, reflect.ValueOf(uint8(4))))
+		var fieldHook [2]any
+		hooked.MustFind("Name").SetWithHook(user, "Jane", func(old, new any) {
+			fieldHook = [2]any{old, new}
+		})
+		assert.Equal(t, [2]any{"John", "Jane"}, fieldHook)
+		assert.Equal(t, []change{
+			{"Name", "", "John"},
+			{"Age", 0, 1},
+			{"Age", 1, 2},
+			{"Age", 2, 3},
+			{"Address.City", "", "Paris"},
+			{"Address.City", "Paris", "Rome"},
+			{"Age", 3, 4},
+			{"Name", "John", "Jane"},
+		}, changes)
+	})
+	t.Run("Errors", func(t *testing.T) {
+		changes = nil
+		user := &hookUser{}
+		assert.Error(t, hooked.MustFind("Age").TrySet(user, "1"))
+		assert.Error(t, hooked.MustFind("Age").SetConvert(user, 1.5))
+		assert.Error(t, hooked.MustFind("Age").SetFromString(user, "x"))
+		assert.Empty(t, changes)
+	})
+	t.Run("InvalidObj", func(t *testing.T) {
+		changes = nil
+		age := hooked.MustFind("Age")
+		for _, obj := range []any{(*hookUser)(nil), &struct{ Age int }{}, hookUser{}, nil} {
+			assert.Error(t, age.TrySet(obj, 1))
+			assert.Error(t, age.SetConvert(obj, 1))
+			assert.Error(t, age.SetFromString(obj, "1"))
+			assert.Error(t, age.SetFromJSON(obj, []byte("1")))
+			assert.Error(t, age.TrySetReflectValue(obj, reflect.ValueOf(1)))
+			assert.Error(t, age.SetReflectValueConvert(obj, reflect.ValueOf(1)))
+			assert.Panics(t, func() { age.Set(obj, 1) })
+			assert.Panics(t, func() { age.SetReflectValue(obj, reflect.ValueOf(1)) })
+		}
+		assert.Empty(t, changes)
+	})
+	t.Run("Pointer", func(t *testing.T) {
+		changes = nil
+		user := &hookUser{}
+		nick := "nick"
+		hooked.MustFind("Nick").Set(user, &nick)
+		assert.Equal(t, []change{{"Nick", (*string)(nil), &nick}}, changes)
+	})
+	t.Run("Storage", func(t *testing.T) {
+		fld, ok := hooked.Find("Name")
+		assert.True(t, ok)
+		assert.IsType(t, &HookField{}, fld)
+		_, ok = hooked.Get("Unknown")
+		assert.False(t, ok)
+		assert.Panics(t, func() { hooked.MustGet("Unknown") })
+		assert.Equal(t, fields.GetAllPaths(), hooked.GetAllPaths())
+		user := &hookUser{}
+		fld, err := hooked.GetFieldByPtr(user, &user.Age)
+		assert.NoError(t, err)
+		assert.IsType(t, &HookField{}, fld)
+		assert.IsType(t, &HookField{}, hooked.Leaves().MustFind("Address.City"))
+		filtered := hooked.Filter(func(f Field) bool {
+			_, ok := f.(*HookField)
+			return ok && f.GetName() == "Age"
+		})
+		assert.Equal(t, []string{"Age"}, filtered.GetAllPaths())
+		assert.IsType(t, &HookField{}, filtered.MustFind("Age"))
+
+		changes = nil
+		syncHooked := WithSetHook(Synchronized(fields), func(fld Field, obj any, old, new any) {
+			changes = append(changes, change{fld.GetStructPath(), old, new})
+		})
+		syncHooked.MustFind("Age").Set(user, 7)
+		assert.Equal(t, []change{{"Age", 0, 7}}, changes)
+	})
+}
@@ -0,0 +1,670 @@
+package fmap
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+	"unsafe"
+)
+
+// jsonPlans caches the jsonObject of the ptr to struct types.
+var jsonPlans sync.Map
+
+// jsonObject is the JSON object layout of the struct built from the field map.
+type jsonObject struct {
+	fields []*jsonField
+	byName map[string]*jsonField
+	// std is set if any member has the string option, the whole struct is passed to encoding/json then.
+	std bool
+}
+
+// jsonField is the JSON object member of the struct field.
+type jsonField struct {
+	fld       *field
+	name      []byte
+	key       []byte
+	omitEmpty bool
+	// fast is set for the primitive types and pointers to them, which are encoded and decoded in place,
+	// all other types are passed to encoding/json.
+	fast bool
+	// object is set for the nested struct fields, which are encoded member by member.
+	object *jsonObject
+}
+
+// MarshalJSON returns the JSON encoding of the struct pointed to by obj.
+// It's the faster alternative to encoding/json for the flat and nested structs: the members are taken
+// from the field map by the json tag names, or the field names if there is no tag, the fields with the "-" tag
+// are skipped and the omitempty option is supported. The primitives and pointers to them are encoded in place,
+// the other values, e.g. slices, maps, time.Time and the types implementing json.Marshaler, by encoding/json.
+// The embedded structs without the tag are flattened, but the name conflicts aren't resolved like encoding/json does.
+// The structs with the string option on any field are encoded by encoding/json as a whole.
+func MarshalJSON(obj any) ([]byte, error) {
+	fields, err := getFromPtr(obj)
+	if err != nil {
+		return nil, err
+	}
+	root := objPointer(obj)
+	if root == nil {
+		return []byte("null"), nil
+	}
+	plan := jsonPlan(reflect.TypeOf(obj), fields)
+	if plan.std {
+		data, err := json.Marshal(obj)
+		if err != nil {
+			return nil, fmt.Errorf("fmap: %w", err)
+		}
+		return data, nil
+	}
+	return plan.appendObject(make([]byte, 0, 256), root)
+}
+
+// UnmarshalJSON parses the JSON object from the data into the struct pointed to by obj, the inverse of MarshalJSON.
+// The members are matched to the fields by the json tag names or the field names, the exact match is preferred
+// over the case-insensitive one. The unknown members are ignored, the null values leave the fields unchanged,
+// except the pointers, which are set to nil. The data is validated by json.Valid before any field is set,
+// so the syntax errors are reported like encoding/json does and the object is left unchanged then.
+func UnmarshalJSON(obj any, data []byte) error {
+	fields, err := getFromPtr(obj)
+	if err != nil {
+		return err
+	}
+	root := objPointer(obj)
+	if root == nil {
+		return fmt.Errorf("fmap: json: can't unmarshal into the nil %v", reflect.TypeOf(obj))
+	}
+	if !json.Valid(data) {
+		// json.Unmarshal checks the syntax before the decoding, so it only returns the *json.SyntaxError here
+		return fmt.Errorf("fmap: json: %w", json.Unmarshal(data, &struct{}{}))
+	}
+	plan := jsonPlan(reflect.TypeOf(obj), fields)
+	if plan.std {
+		if err = json.Unmarshal(data, obj); err != nil {
+			return fmt.Errorf("fmap: %w", err)
+		}
+		return nil
+	}
+	d := &jsonDecoder{data: data}
+	d.skipSpace()
+	switch d.peek() {
+	case 'n':
+		// null
+		return nil
+	case '{':
+		return plan.decodeObject(d, root)
+	default:
+		return fmt.Errorf("fmap: json: expected object, got %q at offset %d", d.peek(), d.pos)
+	}
+}
+
+// SetFromJSON unmarshals the data with encoding/json into the new zero value of the field type
+// and assigns it to the field in the provided object, so the field is replaced, not merged with the data.
+// The JSON null sets the pointer, slice, map and interface fields to nil.
+func (f *field) SetFromJSON(obj any, data []byte) error {
+	ptr, err := f.tryGetPtr(obj, true)
+	if err != nil {
+		return err
+	}
+	val := reflect.New(f.Type)
+	if err = json.Unmarshal(data, val.Interface()); err != nil {
+		return fmt.Errorf("fmap: field %s: %w", f.structPath, err)
+	}
+	reflect.NewAt(f.Type, ptr).Elem().Set(val.Elem())
+	return nil
+}
+
+// jsonPlan returns the cached jsonObject of the typeOf ptr to struct.
+func jsonPlan(typeOf reflect.Type, fields *storage) *jsonObject {
+	if plan, ok := jsonPlans.Load(typeOf); ok {
+		return plan.(*jsonObject)
+	}
+	plan, _ := jsonPlans.LoadOrStore(typeOf, newJSONObject(fields, nil))
+	return plan.(*jsonObject)
+}
+
+// newJSONObject returns the jsonObject of the parent fields, the nil parent is the owner struct.
+func newJSONObject(fields *storage, parent *field) *jsonObject {
+	obj := &jsonObject{byName: map[string]*jsonField{}}
+	for _, path := range fields.paths {
+		fld := fields.asMap[path].(*field)
+		if fld.parent != parent || fld.readOnly {
+			continue
+		}
+		tag := fld.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" && fld.Anonymous && fld.hasChildren {
+			embedded := newJSONObject(fields, fld)
+			for _, child := range embedded.fields {
+				obj.add(child)
+			}
+			obj.std = obj.std || embedded.std
+			continue
+		}
+		if name == "" {
+			name = fld.Name
+		}
+		jf := &jsonField{
+			fld:       fld,
+			name:      []byte(name),
+			key:       append(appendJSONString(nil, name), ':'),
+			omitEmpty: hasTagOption(opts, "omitempty"),
+			fast:      isFastJSONType(fld.Type),
+		}
+		obj.std = obj.std || hasTagOption(opts, "string")
+		if fld.Type.Kind() == reflect.Struct && fld.hasChildren && !hasJSONMethods(fld.Type) {
+			jf.object = newJSONObject(fields, fld)
+			obj.std = obj.std || jf.object.std
+		}
+		obj.add(jf)
+	}
+	return obj
+}
+
+func (o *jsonObject) add(jf *jsonField) {
+	o.fields = append(o.fields, jf)
+	if _, ok := o.byName[string(jf.name)]; !ok {
+		o.byName[string(jf.name)] = jf
+	}
+}
+
+// find returns the member by the exact name or the case-insensitive one.
+func (o *jsonObject) find(name []byte) *jsonField {
+	if jf, ok := o.byName[string(name)]; ok {
+		return jf
+	}
+	for _, jf := range o.fields {
+		if bytes.EqualFold(jf.name, name) {
+			return jf
+		}
+	}
+	return nil
+}
+
+var (
+	jsonMarshalerType   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// hasJSONMethods reports whether the typeOf or the pointer to it customizes its JSON encoding.
+func hasJSONMethods(typeOf reflect.Type) bool {
+	ptrType := reflect.PointerTo(typeOf)
+	for _, iface := range []reflect.Type{jsonMarshalerType, jsonUnmarshalerType, textMarshalerType, textUnmarshalerType} {
+		if ptrType.Implements(iface) {
+			return true
+		}
+	}
+	return false
+}
+
+// isFastJSONType reports whether the typeOf is the primitive or the pointer to the primitive with the default encoding.
+func isFastJSONType(typeOf reflect.Type) bool {
+	if typeOf.Kind() == reflect.Ptr {
+		if hasJSONMethods(typeOf) {
+			return false
+		}
+		typeOf = typeOf.Elem()
+	}
+	switch typeOf.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return !hasJSONMethods(typeOf)
+	default:
+		return false
+	}
+}
+
+func (o *jsonObject) appendObject(buf []byte, root unsafe.Pointer) ([]byte, error) {
+	var err error
+	buf = append(buf, '{')
+	first := true
+	for _, jf := range o.fields {
+		base := jf.fld.basePtr(root, false)
+		if base == nil {
+			// behind the nil embedded struct pointer
+			continue
+		}
+		ptr := unsafe.Add(base, jf.fld.Offset)
+		if jf.omitEmpty && isEmptyJSON(jf.fld.Type, ptr) {
+			continue
+		}
+		if !first {
+			buf = append(buf, ',')
+		}
+		first = false
+		buf = append(buf, jf.key...)
+		if jf.object != nil {
+			if buf, err = jf.object.appendObject(buf, root); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if jf.fast {
+			buf, err = appendJSONPrimitive(buf, jf.fld.Type, ptr)
+		} else {
+			var data []byte
+			data, err = json.Marshal(reflect.NewAt(jf.fld.Type, ptr).Interface())
+			buf = append(buf, data...)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("fmap: field %s: %w", jf.fld.structPath, err)
+		}
+	}
+	return append(buf, '}'), nil
+}
+
+// isEmptyJSON reports whether the value at the ptr is empty in terms of the omitempty option.
+func isEmptyJSON(typeOf reflect.Type, ptr unsafe.Pointer) bool {
+	switch typeOf.Kind() {
+	case reflect.Bool:
+		return isZeroPtr[bool](ptr)
+	case reflect.String:
+		return isZeroPtr[string](ptr)
+	case reflect.Int:
+		return isZeroPtr[int](ptr)
+	case reflect.Int8:
+		return isZeroPtr[int8](ptr)
+	case reflect.Int16:
+		return isZeroPtr[int16](ptr)
+	case reflect.Int32:
+		return isZeroPtr[int32](ptr)
+	case reflect.Int64:
+		return isZeroPtr[int64](ptr)
+	case reflect.Uint:
+		return isZeroPtr[uint](ptr)
+	case reflect.Uint8:
+		return isZeroPtr[uint8](ptr)
+	case reflect.Uint16:
+		return isZeroPtr[uint16](ptr)
+	case reflect.Uint32:
+		return isZeroPtr[uint32](ptr)
+	case reflect.Uint64:
+		return isZeroPtr[uint64](ptr)
+	case reflect.Uintptr:
+		return isZeroPtr[uintptr](ptr)
+	case reflect.Float32:
+		return isZeroPtr[float32](ptr)
+	case reflect.Float64:
+		return isZeroPtr[float64](ptr)
+	case reflect.Ptr:
+		return isZeroPtr[unsafe.Pointer](ptr)
+	case reflect.Array, reflect.Slice, reflect.Map:
+		return reflect.NewAt(typeOf, ptr).Elem().Len() == 0
+	case reflect.Interface:
+		return reflect.NewAt(typeOf, ptr).Elem().IsNil()
+	default:
+		return false
+	}
+}
+
+func isZeroPtr[T comparable](ptr unsafe.Pointer) bool {
+	var zero T
+	return *(*T)(ptr) == zero
+}
+
+// appendJSONPrimitive appends the JSON encoding of the primitive or the pointer to the primitive at the ptr.
+func appendJSONPrimitive(buf []byte, typeOf reflect.Type, ptr unsafe.Pointer) ([]byte, error) {
+	if typeOf.Kind() == reflect.Ptr {
+		ptr = *(*unsafe.Pointer)(ptr)
+		if ptr == nil {
+			return append(buf, "null"...), nil
+		}
+		typeOf = typeOf.Elem()
+	}
+	switch typeOf.Kind() {
+	case reflect.Bool:
+		return strconv.AppendBool(buf, *(*bool)(ptr)), nil
+	case reflect.String:
+		return appendJSONString(buf, *(*string)(ptr)), nil
+	case reflect.Int:
+		return strconv.AppendInt(buf, int64(*(*int)(ptr)), 10), nil
+	case reflect.Int8:
+		return strconv.AppendInt(buf, int64(*(*int8)(ptr)), 10), nil
+	case reflect.Int16:
+		return strconv.AppendInt(buf, int64(*(*int16)(ptr)), 10), nil
+	case reflect.Int32:
+		return strconv.AppendInt(buf, int64(*(*int32)(ptr)), 10), nil
+	case reflect.Int64:
+		return strconv.AppendInt(buf, *(*int64)(ptr), 10), nil
+	case reflect.Uint:
+		return strconv.AppendUint(buf, uint64(*(*uint)(ptr)), 10), nil
+	case reflect.Uint8:
+		return strconv.AppendUint(buf, uint64(*(*uint8)(ptr)), 10), nil
+	case reflect.Uint16:
+		return strconv.AppendUint(buf, uint64(*(*uint16)(ptr)), 10), nil
+	case reflect.Uint32:
+		return strconv.AppendUint(buf, uint64(*(*uint32)(ptr)), 10), nil
+	case reflect.Uint64:
+		return strconv.AppendUint(buf, *(*uint64)(ptr), 10), nil
+	case reflect.Uintptr:
+		return strconv.AppendUint(buf, uint64(*(*uintptr)(ptr)), 10), nil
+	case reflect.Float32:
+		return appendJSONFloat(buf, float64(*(*float32)(ptr)), 32)
+	default:
+		return appendJSONFloat(buf, *(*float64)(ptr), 64)
+	}
+}
+
+// appendJSONFloat appends the float like encoding/json does: the exponent format is used for the very small
+// and the very large values only.
+func appendJSONFloat(buf []byte, f float64, bits int) ([]byte, error) {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return nil, fmt.Errorf("unsupported value: %s", strconv.FormatFloat(f, 'g', -1, bits))
+	}
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+	buf = strconv.AppendFloat(buf, f, format, -1, bits)
+	if format == 'e' {
+		// clean up e-09 to e-9
+		n := len(buf)
+		if n >= 4 && buf[n-4] == 'e' && buf[n-3] == '-' && buf[n-2] == '0' {
+			buf[n-2] = buf[n-1]
+			buf = buf[:n-1]
+		}
+	}
+	return buf, nil
+}
+
+const jsonHex = "0123456789abcdef"
+
+// appendJSONString appends the quoted s escaped like encoding/json does, including the HTML characters,
+// the invalid UTF-8 bytes are replaced with U+FFFD.
+func appendJSONString(buf []byte, s string) []byte {
+	buf = append(buf, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' && b != '<' && b != '>' && b != '&' {
+				i++
+				continue
+			}
+			buf = append(buf, s[start:i]...)
+			switch b {
+			case '"', '\\':
+				buf = append(buf, '\\', b)
+			case '\n':
+				buf = append(buf, '\\', 'n')
+			case '\r':
+				buf = append(buf, '\\', 'r')
+			case '\t':
+				buf = append(buf, '\\', 't')
+			default:
+				buf = append(buf, '\\', 'u', '0', '0', jsonHex[b>>4], jsonHex[b&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, "\\ufffd"...)
+			i += size
+			start = i
+			continue
+		}
+		// U+2028 LINE SEPARATOR and U+2029 PARAGRAPH SEPARATOR are escaped for JSONP
+		if r == 0x2028 || r == 0x2029 {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, '\\', 'u', '2', '0', '2', jsonHex[r&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	buf = append(buf, s[start:]...)
+	return append(buf, '"')
+}
+
+// jsonDecoder is the minimal JSON scanner of the data validated by json.Valid, so it only finds the bounds
+// of the values, the value types are checked by the field decoders.
+type jsonDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *jsonDecoder) skipSpace() {
+	for d.pos < len(d.data) {
+		switch d.data[d.pos] {
+		case ' ', '\t', '\n', '\r':
+			d.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (d *jsonDecoder) peek() byte {
+	if d.pos < len(d.data) {
+		return d.data[d.pos]
+	}
+	return 0
+}
+
+// skipString skips the string starting at the current position and returns it with the quotes.
+func (d *jsonDecoder) skipString() []byte {
+	start := d.pos
+	for d.pos++; d.data[d.pos] != '"'; d.pos++ {
+		if d.data[d.pos] == '\\' {
+			d.pos++
+		}
+	}
+	d.pos++
+	return d.data[start:d.pos]
+}
+
+// skipValue skips the value starting at the current position and returns it.
+func (d *jsonDecoder) skipValue() []byte {
+	start := d.pos
+	switch d.data[d.pos] {
+	case '"':
+		return d.skipString()
+	case '{', '[':
+		for depth := 0; ; {
+			switch d.data[d.pos] {
+			case '"':
+				d.skipString()
+				continue
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+			}
+			d.pos++
+			if depth == 0 {
+				return d.data[start:d.pos]
+			}
+		}
+	}
+	// the number or the literal ends with the delimiter
+	for ; d.pos < len(d.data); d.pos++ {
+		switch d.data[d.pos] {
+		case ',', '}', ']', ' ', '\t', '\n', '\r':
+			return d.data[start:d.pos]
+		}
+	}
+	return d.data[start:d.pos]
+}
+
+// decodeObject decodes the object starting at the current position, the object members are separated
+// by the commas only, as the data is valid.
+func (o *jsonObject) decodeObject(d *jsonDecoder, root unsafe.Pointer) error {
+	for d.pos++; ; d.pos++ {
+		d.skipSpace()
+		if d.peek() == '}' {
+			d.pos++
+			return nil
+		}
+		name := unquoteJSONString(d.skipString())
+		d.skipSpace()
+		d.pos++ // the colon
+		d.skipSpace()
+		jf := o.find(name)
+		if jf != nil && jf.object != nil && d.peek() == '{' {
+			if err := jf.object.decodeObject(d, root); err != nil {
+				return err
+			}
+		} else if raw := d.skipValue(); jf != nil {
+			if err := jf.decode(root, raw); err != nil {
+				return err
+			}
+		}
+		d.skipSpace()
+		if d.peek() == '}' {
+			d.pos++
+			return nil
+		}
+	}
+}
+
+// decode sets the raw value to the field in the object at the root.
+func (jf *jsonField) decode(root unsafe.Pointer, raw []byte) error {
+	isNull := string(raw) == "null"
+	if jf.object != nil && isNull {
+		return nil
+	}
+	var err error
+	ptr := unsafe.Add(jf.fld.basePtr(root, true), jf.fld.Offset)
+	if jf.fast {
+		err = decodeJSONPrimitive(jf.fld.Type, ptr, raw, isNull)
+	} else {
+		err = json.Unmarshal(raw, reflect.NewAt(jf.fld.Type, ptr).Interface())
+	}
+	if err != nil {
+		return fmt.Errorf("fmap: field %s: %w", jf.fld.structPath, err)
+	}
+	return nil
+}
+
+// decodeJSONPrimitive sets the raw value to the primitive or the pointer to the primitive at the ptr.
+func decodeJSONPrimitive(typeOf reflect.Type, ptr unsafe.Pointer, raw []byte, isNull bool) error {
+	if typeOf.Kind() == reflect.Ptr {
+		valPtr := (*unsafe.Pointer)(ptr)
+		if isNull {
+			*valPtr = nil
+			return nil
+		}
+		if *valPtr == nil {
+			*valPtr = reflect.New(typeOf.Elem()).UnsafePointer()
+		}
+		typeOf = typeOf.Elem()
+		ptr = *valPtr
+	}
+	if isNull {
+		return nil
+	}
+	kind := typeOf.Kind()
+	mismatch := func() error {
+		return fmt.Errorf("cannot unmarshal %s into %v", raw, typeOf)
+	}
+	switch kind {
+	case reflect.String:
+		if raw[0] != '"' {
+			return mismatch()
+		}
+		*(*string)(ptr) = string(unquoteJSONString(raw))
+		return nil
+	case reflect.Bool:
+		switch string(raw) {
+		case "true":
+			*(*bool)(ptr) = true
+		case "false":
+			*(*bool)(ptr) = false
+		default:
+			return mismatch()
+		}
+		return nil
+	}
+	if raw[0] != '-' && (raw[0] < '0' || raw[0] > '9') {
+		// strconv accepts more than the JSON numbers, e.g. "+1" or "Inf"
+		return mismatch()
+	}
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(string(raw), 10, typeOf.Bits())
+		if err != nil {
+			return mismatch()
+		}
+		setIntPtr(kind, ptr, i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := strconv.ParseUint(string(raw), 10, typeOf.Bits())
+		if err != nil {
+			return mismatch()
+		}
+		setUintPtr(kind, ptr, u)
+	case reflect.Float32:
+		f, err := strconv.ParseFloat(string(raw), 32)
+		if err != nil {
+			return mismatch()
+		}
+		*(*float32)(ptr) = float32(f)
+	default:
+		f, err := strconv.ParseFloat(string(raw), 64)
+		if err != nil {
+			return mismatch()
+		}
+		*(*float64)(ptr) = f
+	}
+	return nil
+}
+
+func setIntPtr(kind reflect.Kind, ptr unsafe.Pointer, i int64) {
+	switch kind {
+	case reflect.Int:
+		*(*int)(ptr) = int(i)
+	case reflect.Int8:
+		*(*int8)(ptr) = int8(i)
+	case reflect.Int16:
+		*(*int16)(ptr) = int16(i)
+	case reflect.Int32:
+		*(*int32)(ptr) = int32(i)
+	default:
+		*(*int64)(ptr) = i
+	}
+}
+
+func setUintPtr(kind reflect.Kind, ptr unsafe.Pointer, u uint64) {
+	switch kind {
+	case reflect.Uint:
+		*(*uint)(ptr) = uint(u)
+	case reflect.Uint8:
+		*(*uint8)(ptr) = uint8(u)
+	case reflect.Uint16:
+		*(*uint16)(ptr) = uint16(u)
+	case reflect.Uint32:
+		*(*uint32)(ptr) = uint32(u)
+	case reflect.Uintptr:
+		*(*uintptr)(ptr) = uintptr(u)
+	default:
+		*(*uint64)(ptr) = u
+	}
+}
+
+// unquoteJSONString returns the content of the valid quoted JSON string. The strings with the escapes
+// or the invalid UTF-8 bytes are unquoted by encoding/json, which replaces the invalid bytes with U+FFFD.
+func unquoteJSONString(quoted []byte) []byte {
+	content := quoted[1 : len(quoted)-1]
+	if bytes.IndexByte(content, '\\') < 0 && utf8.Valid(content) {
+		return content
+	}
+	var s string
+	_ = json.Unmarshal(quoted, &s)
+	return []byte(s)
+}
@@ -0,0 +1,334 @@
+package fmap
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type jsonAddress struct {
+	City string `json:"city"`
+	Zip  string `json:"zip,omitempty"`
+}
+
+type jsonBase struct {
+	ID      int64  `json:"id"`
+	Version uint16 `json:"version"`
+}
+
+type jsonUser struct {
+	jsonBase
+	Name      string         `json:"name"`
+	Nickname  *string        `json:"nickname"`
+	Age       int            `json:"age,omitempty"`
+	Score     float64        `json:"score"`
+	Ratio     float32        `json:"ratio"`
+	Active    bool           `json:"active"`
+	Level     Level          `json:"level"`
+	Password  string         `json:"-"`
+	Tags      []string       `json:"tags"`
+	Meta      map[string]int `json:"meta,omitempty"`
+	Address   jsonAddress    `json:"address"`
+	CreatedAt time.Time      `json:"created_at"`
+	Untagged  string
+	Extra     *jsonAddress      `json:"extra,omitempty"`
+	Labels    map[string]string `json:"labels"`
+}
+
+func newJSONUser() *jsonUser {
+	nick := "<jo\"hn>\n"
+	return &jsonUser{
+		jsonBase:  jsonBase{ID: 42, Version: 3},
+		Name:      "John \u2028 Doe\x01",
+		Nickname:  &nick,
+		Age:       30,
+		Score:     1e-7,
+		Ratio:     0.1,
+		Active:    true,
+		Level:     LevelHigh,
+		Password:  "secret",
+		Tags:      []string{"a", "b"},
+		Address:   jsonAddress{City: "Paris"},
+		CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Untagged:  "untagged",
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	t.Run("SameAsEncodingJSON", func(t *testing.T) {
+		for _, user := range []*jsonUser{newJSONUser(), {}, {Score: 1e21, Ratio: -2.5, Extra: &jsonAddress{Zip: "1"}, Meta: map[string]int{}}} {
+			expected, err := json.Marshal(user)
+			assert.NoError(t, err)
+			actual, err := MarshalJSON(user)
+			assert.NoError(t, err)
+			assert.Equal(t, string(expected), string(actual))
+		}
+	})
+	t.Run("Skipped", func(t *testing.T) {
+		data, err := MarshalJSON(&jsonUser{})
+		assert.NoError(t, err)
+		assert.NotContains(t, string(data), "Password")
+		assert.NotContains(t, string(data), `"age"`)
+		assert.NotContains(t, string(data), `"meta"`)
+		assert.Contains(t, string(data), `"nickname":null`)
+	})
+	t.Run("EmbeddedPtr", func(t *testing.T) {
+		data, err := MarshalJSON(&embeddedRoot{Name: "root"})
+		assert.NoError(t, err)
+		assert.Equal(t, `{"Name":"root"}`, string(data))
+		obj := &embeddedRoot{Name: "root", embeddedMiddle: &embeddedMiddle{ID: 1}}
+		expected, _ := json.Marshal(obj)
+		data, err = MarshalJSON(obj)
+		assert.NoError(t, err)
+		assert.Equal(t, string(expected), string(data))
+	})
+	t.Run("Errors", func(t *testing.T) {
+		_, err := MarshalJSON(&jsonUser{Score: math.NaN()})
+		assert.EqualError(t, err, "fmap: field Score: unsupported value: NaN")
+		_, err = MarshalJSON(jsonUser{})
+		assert.Error(t, err)
+		data, err := MarshalJSON((*jsonUser)(nil))
+		assert.NoError(t, err)
+		assert.Equal(t, "null", string(data))
+	})
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	t.Run("RoundTrip", func(t *testing.T) {
+		user := newJSONUser()
+		data, err := MarshalJSON(user)
+		assert.NoError(t, err)
+		actual := &jsonUser{}
+		assert.NoError(t, UnmarshalJSON(actual, data))
+		user.Password = ""
+		assert.Equal(t, user, actual)
+	})
+	t.Run("SameAsEncodingJSON", func(t *testing.T) {
+		data := []byte(` {
+			"ID": 7, "NAME": "john", "nickname": null, "age": -5, "score": 1.5e3, "ratio": 2,
+			"active": false, "level": 1, "Password": "secret", "tags": ["x", "y"], "meta": {"a": 1},
+			"address": {"city": "Paris", "zip": "75001", "unknown": [1, {"a": "}"}]},
+			"created_at": "2024-01-02T03:04:05Z", "untagged": "u", "extra": {"city": "Rome"},
+			"unknown": {"nested": "\"}"}, "labels": null
+		} `)
+		expected := &jsonUser{Nickname: new(string), Active: true, Labels: map[string]string{"a": "b"}}
+		actual := &jsonUser{Nickname: new(string), Active: true, Labels: map[string]string{"a": "b"}}
+		assert.NoError(t, json.Unmarshal(data, expected))
+		assert.NoError(t, UnmarshalJSON(actual, data))
+		assert.Equal(t, expected, actual)
+	})
+	t.Run("EscapedStrings", func(t *testing.T) {
+		actual := &jsonUser{}
+		assert.NoError(t, UnmarshalJSON(actual, []byte(`{"name": "a\"bé\n"}`)))
+		assert.Equal(t, "a\"bé\n", actual.Name)
+	})
+	t.Run("InvalidUTF8", func(t *testing.T) {
+		for _, data := range []string{"{\"name\": \"a\xffb\"}", "{\"name\": \"a\xff\\nb\"}", "{\"na\xffme\": \"a\"}"} {
+			expected, actual := &jsonUser{}, &jsonUser{}
+			assert.NoError(t, json.Unmarshal([]byte(data), expected))
+			assert.NoError(t, UnmarshalJSON(actual, []byte(data)))
+			assert.Equal(t, expected, actual, data)
+		}
+		actual := &jsonUser{}
+		assert.NoError(t, UnmarshalJSON(actual, []byte("{\"name\": \"a\xffb\"}")))
+		assert.Equal(t, "a\ufffdb", actual.Name)
+	})
+	t.Run("NullObject", func(t *testing.T) {
+		actual := &jsonUser{Address: jsonAddress{City: "Paris"}}
+		assert.NoError(t, UnmarshalJSON(actual, []byte(`{"address": null}`)))
+		assert.Equal(t, "Paris", actual.Address.City)
+		assert.NoError(t, UnmarshalJSON(actual, []byte(`null`)))
+	})
+	t.Run("EmbeddedPtr", func(t *testing.T) {
+		actual := &embeddedRoot{}
+		assert.NoError(t, UnmarshalJSON(actual, []byte(`{"Name": "root", "Value": "v", "ID": 5}`)))
+		assert.Equal(t, "root", actual.Name)
+		assert.Equal(t, 5, actual.ID)
+		assert.Equal(t, "v", actual.Value)
+	})
+	t.Run("TypeErrors", func(t *testing.T) {
+		for data, msg := range map[string]string{
+			`{"age": "1"}`:        `fmap: field Age: cannot unmarshal "1" into int`,
+			`{"age": 1.5}`:        `fmap: field Age: cannot unmarshal 1.5 into int`,
+			`{"version": 70000}`:  `fmap: field jsonBase.Version: cannot unmarshal 70000 into uint16`,
+			`{"active": 1}`:       `fmap: field Active: cannot unmarshal 1 into bool`,
+			`{"name": 1}`:         `fmap: field Name: cannot unmarshal 1 into string`,
+			`{"score": "NaN"}`:    `fmap: field Score: cannot unmarshal "NaN" into float64`,
+			`{"address": "city"}`: `fmap: field Address: json: cannot unmarshal string into Go value of type fmap.jsonAddress`,
+		} {
+			assert.EqualError(t, UnmarshalJSON(&jsonUser{}, []byte(data)), msg, data)
+		}
+	})
+	t.Run("SyntaxErrors", func(t *testing.T) {
+		for _, data := range []string{``, `[]`, `{`, `{"name"}`, `{"name": }`, `{"name": "a"`, `{"name": "a"}}`, `{"name": "a",}`} {
+			assert.Error(t, UnmarshalJSON(&jsonUser{}, []byte(data)), data)
+		}
+		// the unknown members are validated too
+		for _, data := range []string{
+			`{"unknown": tru, "name": "a"}`, `{"unknown": [1}, "name": "a"}`, `{"unknown": {"a": 1]], "name": "a"}`,
+			"{\"unknown\": \"a\tb\", \"name\": \"a\"}", `{"unknown": "\x", "name": "a"}`, `{"unknown": "\u12", "name": "a"}`,
+			`{"unknown": 01, "name": "a"}`, `{"unknown": +1, "name": "a"}`, `{"unknown": 1., "name": "a"}`, `{"unknown": 1e, "name": "a"}`,
+			`{"unknown": [1 2], "name": "a"}`, `{"unknown": {"a" 1}, "name": "a"}`, `{"unknown": {1: 1}, "name": "a"}`, `{"score": Inf}`,
+			"{\"name\": \"a\nb\"}",
+		} {
+			assert.Error(t, json.Unmarshal([]byte(data), &jsonUser{}), data)
+			assert.Error(t, UnmarshalJSON(&jsonUser{}, []byte(data)), data)
+		}
+		data := []byte(`{"unknown": tru, "name": "a"}`)
+		assert.EqualError(t, UnmarshalJSON(&jsonUser{}, data), "fmap: json: "+json.Unmarshal(data, &jsonUser{}).Error())
+		user := &jsonUser{Name: "old"}
+		assert.Error(t, UnmarshalJSON(user, []byte(`{"name": "new", "age": 1, "tags": [}`)))
+		assert.Equal(t, &jsonUser{Name: "old"}, user)
+		assert.EqualError(t, UnmarshalJSON(&jsonUser{}, []byte(` [{"name": "a"}]`)), `fmap: json: expected object, got '[' at offset 1`)
+		assert.Error(t, UnmarshalJSON(jsonUser{}, []byte(`{}`)))
+		assert.Error(t, UnmarshalJSON((*jsonUser)(nil), []byte(`{}`)))
+	})
+}
+
+func TestJSON_StringOption(t *testing.T) {
+	type testStruct struct {
+		ID      int64    `json:"id,string"`
+		Count   *uint8   `json:"count,string"`
+		Nil     *int     `json:"nil,string"`
+		Score   float64  `json:"score,string"`
+		Active  bool     `json:"active,string"`
+		Name    string   `json:"name,string"`
+		Tags    []string `json:"tags,string"`
+		Created time.Time
+	}
+	count := uint8(7)
+	obj := &testStruct{ID: 5, Count: &count, Score: 1.5, Active: true, Name: `a"<b>`, Tags: []string{"a"}}
+	data, err := MarshalJSON(obj)
+	assert.NoError(t, err)
+	expected, _ := json.Marshal(obj)
+	assert.JSONEq(t, string(expected), string(data))
+	assert.Contains(t, string(data), `"id":"5"`)
+
+	actual := &testStruct{Nil: new(int)}
+	assert.NoError(t, UnmarshalJSON(actual, data))
+	assert.Equal(t, obj, actual)
+	assert.NoError(t, UnmarshalJSON(actual, []byte(`{"id": "null", "nil": "null", "count": null}`)))
+	assert.Equal(t, int64(5), actual.ID)
+	assert.Nil(t, actual.Count)
+
+	for _, data := range []string{`{"id": 5}`, `{"id": "5x"}`, `{"id": "\"5\""}`, `{"name": "a"}`, `{"active": "1"}`, `{"id": ""}`} {
+		assert.Error(t, json.Unmarshal([]byte(data), &testStruct{}), data)
+		assert.Error(t, UnmarshalJSON(&testStruct{}, []byte(data)), data)
+	}
+	unquoted := []byte(`{"id": 5}`)
+	assert.EqualError(t, UnmarshalJSON(&testStruct{}, unquoted), "fmap: "+json.Unmarshal(unquoted, &testStruct{}).Error())
+	_, err = MarshalJSON(&testStruct{Score: math.Inf(1)})
+	assert.EqualError(t, err, "fmap: json: unsupported value: +Inf")
+}
+
+func TestField_SetFromJSON(t *testing.T) {
+	fields, _ := Get[jsonUser]()
+	t.Run("Struct", func(t *testing.T) {
+		user := &jsonUser{Address: jsonAddress{City: "Rome", Zip: "00100"}}
+		assert.NoError(t, fields.MustFind("Address").SetFromJSON(user, []byte(`{"city":"Paris"}`)))
+		// the field is replaced, not merged
+		assert.Equal(t, jsonAddress{City: "Paris"}, user.Address)
+	})
+	t.Run("Pointer", func(t *testing.T) {
+		user := &jsonUser{}
+		extra := fields.MustFind("Extra")
+		assert.NoError(t, extra.SetFromJSON(user, []byte(`{"city":"Paris"}`)))
+		assert.Equal(t, &jsonAddress{City: "Paris"}, user.Extra)
+		assert.NoError(t, extra.SetFromJSON(user, []byte(`null`)))
+		assert.Nil(t, user.Extra)
+	})
+	t.Run("Composite", func(t *testing.T) {
+		user := &jsonUser{Tags: []string{"a"}}
+		assert.NoError(t, fields.MustFind("Tags").SetFromJSON(user, []byte(`["x", "y"]`)))
+		assert.Equal(t, []string{"x", "y"}, user.Tags)
+		assert.NoError(t, fields.MustFind("CreatedAt").SetFromJSON(user, []byte(`"2024-01-02T03:04:05Z"`)))
+		assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), user.CreatedAt)
+		assert.NoError(t, fields.MustFind("jsonBase.ID").SetFromJSON(user, []byte(`7`)))
+		assert.Equal(t, int64(7), user.ID)
+	})
+	t.Run("Errors", func(t *testing.T) {
+		user := &jsonUser{Address: jsonAddress{City: "Rome"}}
+		err := fields.MustFind("Address").SetFromJSON(user, []byte(`{"city":1}`))
+		assert.ErrorContains(t, err, "fmap: field Address: json: cannot unmarshal number")
+		assert.Equal(t, "Rome", user.Address.City)
+		assert.Error(t, fields.MustFind("Address").SetFromJSON(user, []byte(`{`)))
+		assert.Error(t, fields.MustFind("Address").SetFromJSON(*user, []byte(`{}`)))
+	})
+}
+
+type jsonFlat struct {
+	ID      int64   `json:"id"`
+	Name    string  `json:"name"`
+	Email   string  `json:"email"`
+	Age     int     `json:"age,omitempty"`
+	Score   float64 `json:"score"`
+	Active  bool    `json:"active"`
+	Comment *string `json:"comment"`
+	Address struct {
+		City    string `json:"city"`
+		Country string `json:"country"`
+	} `json:"address"`
+}
+
+func newJSONFlat() *jsonFlat {
+	comment := "comment"
+	obj := &jsonFlat{ID: 1, Name: "John Doe", Email: "john@example.com", Age: 30, Score: 99.5, Active: true, Comment: &comment}
+	obj.Address.City = "Paris"
+	obj.Address.Country = "France"
+	return obj
+}
+
+func BenchmarkMarshalJSON(b *testing.B) {
+	obj := newJSONFlat()
+	b.Run("fmap", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = MarshalJSON(obj)
+		}
+	})
+	b.Run("encoding/json", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = json.Marshal(obj)
+		}
+	})
+}
+
+func BenchmarkUnmarshalJSON_SkipUnknown(b *testing.B) {
+	data, _ := json.Marshal(newJSONFlat())
+	data = append(data[:len(data)-1], `,"unknown":{"ids":[1,2,3.5e-3],"names":["a\"b","\u00e9"],"ok":true,"none":null}}`...)
+	if err := UnmarshalJSON(&jsonFlat{}, data); err != nil {
+		b.Fatal(err)
+	}
+	b.Run("fmap", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = UnmarshalJSON(&jsonFlat{}, data)
+		}
+	})
+	b.Run("encoding/json", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = json.Unmarshal(data, &jsonFlat{})
+		}
+	})
+}
+
+func BenchmarkUnmarshalJSON(b *testing.B) {
+	data, _ := json.Marshal(newJSONFlat())
+	b.Run("fmap", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = UnmarshalJSON(&jsonFlat{}, data)
+		}
+	})
+	b.Run("encoding/json", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = json.Unmarshal(data, &jsonFlat{})
+		}
+	})
+}
@@ -0,0 +1,287 @@
+package fmap
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// GetPointer returns the value from the object pointed to by obj at the RFC 6901 JSON Pointer ptr.
+// Struct fields are resolved by the json tag paths, slices and arrays by the element index,
+// maps with string keys by the key.
+func GetPointer(obj any, ptr string) (any, error) {
+	val, err := getPointer(obj, ptr)
+	if err != nil {
+		return nil, fmt.Errorf("fmap: json pointer %q: %w", ptr, err)
+	}
+	return val, nil
+}
+
+func getPointer(obj any, ptr string) (any, error) {
+	tokens, err := parsePointer(ptr)
+	if err != nil {
+		return nil, err
+	}
+	root, err := pointerRoot(obj)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return root.Interface(), nil
+	}
+	parent, last, err := resolvePointer(root, tokens)
+	if err != nil {
+		return nil, err
+	}
+	val, err := pointerChild(parent, last, false)
+	if err != nil {
+		return nil, err
+	}
+	return val.Interface(), nil
+}
+
+// SetPointer sets the val into the object pointed to by obj at the RFC 6901 JSON Pointer ptr.
+// Nil pointers on the way are allocated. The "-" token as the last slice reference appends the val to the slice.
+func SetPointer(obj any, ptr string, val any) error {
+	if err := setPointer(obj, ptr, val); err != nil {
+		return fmt.Errorf("fmap: json pointer %q: %w", ptr, err)
+	}
+	return nil
+}
+
+func setPointer(obj any, ptr string, val any) error {
+	tokens, err := parsePointer(ptr)
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		return errors.New("the root can't be replaced")
+	}
+	root, err := pointerRoot(obj)
+	if err != nil {
+		return err
+	}
+	return setPointerAt(root, tokens, val)
+}
+
+// setPointerAt sets the v at the tokens in the settable val. The map elements aren't addressable,
+// so the element is copied, the rest of the tokens are set in the copy, and the copy is stored back to the map.
+func setPointerAt(val reflect.Value, tokens []string, v any) error {
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			val.Set(reflect.New(val.Type().Elem()))
+		}
+		val = val.Elem()
+	}
+	if len(tokens) == 1 {
+		return setPointerChild(val, tokens[0], v)
+	}
+	switch val.Kind() {
+	case reflect.Struct:
+		fld, n := findByTagTokens(val.Type(), tokens[:len(tokens)-1])
+		if fld == nil {
+			return fmt.Errorf("field %q not found in %v", tokens[0], val.Type())
+		}
+		ptr, err := fld.tryGetPtr(val.Addr().Interface(), true)
+		if err != nil {
+			return err
+		}
+		return setPointerAt(reflect.NewAt(fld.Type, ptr).Elem(), tokens[n:], v)
+	case reflect.Map:
+		item, err := pointerChild(val, tokens[0], true)
+		if err != nil {
+			return err
+		}
+		elem := reflect.New(item.Type()).Elem()
+		elem.Set(item)
+		if err = setPointerAt(elem, tokens[1:], v); err != nil {
+			return err
+		}
+		val.SetMapIndex(reflect.ValueOf(tokens[0]).Convert(val.Type().Key()), elem)
+		return nil
+	default:
+		next, err := pointerChild(val, tokens[0], true)
+		if err != nil {
+			return err
+		}
+		return setPointerAt(next, tokens[1:], v)
+	}
+}
+
+// parsePointer splits the JSON Pointer to the unescaped reference tokens.
+func parsePointer(ptr string) ([]string, error) {
+	if ptr == "" {
+		return nil, nil
+	}
+	if ptr[0] != '/' {
+		return nil, errors.New("must start with /")
+	}
+	tokens := strings.Split(ptr[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func pointerRoot(obj any) (reflect.Value, error) {
+	valOf := reflect.ValueOf(obj)
+	if valOf.Kind() != reflect.Ptr || valOf.IsNil() || valOf.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("not supported type: %v, only ptr to struct is supported", reflect.TypeOf(obj))
+	}
+	return valOf.Elem(), nil
+}
+
+// resolvePointer walks through the tokens except the last one and returns the value containing the last token.
+// Struct tokens are matched greedily against the json tag paths, so the nested struct fields are reached by offset.
+func resolvePointer(val reflect.Value, tokens []string) (reflect.Value, string, error) {
+	for len(tokens) > 1 {
+		if val.Kind() == reflect.Struct && val.CanAddr() {
+			fld, n := findByTagTokens(val.Type(), tokens[:len(tokens)-1])
+			if fld == nil {
+				return reflect.Value{}, "", fmt.Errorf("field %q not found in %v", tokens[0], val.Type())
+			}
+			ptr, err := fld.tryGetPtr(val.Addr().Interface(), false)
+			if err != nil {
+				return reflect.Value{}, "", err
+			}
+			val = reflect.NewAt(fld.Type, ptr).Elem()
+			tokens = tokens[n:]
+			continue
+		}
+		next, err := pointerChild(val, tokens[0], false)
+		if err != nil {
+			return reflect.Value{}, "", err
+		}
+		val = next
+		tokens = tokens[1:]
+	}
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return reflect.Value{}, "", fmt.Errorf("nil pointer before %q", tokens[0])
+		}
+		val = val.Elem()
+	}
+	return val, tokens[0], nil
+}
+
+// findByTagTokens returns the field of the struct type with the longest json tag path matching the tokens prefix
+// and the count of the matched tokens. The fields are looked up in the cached json TagIndex of the type.
+func findByTagTokens(typeOf reflect.Type, tokens []string) (*field, int) {
+	fields, err := getFrom(typeOf)
+	if err != nil {
+		return nil, 0
+	}
+	index := fields.tagIndex("json")
+	for n := len(tokens); n > 0; n-- {
+		if fld, ok := index[strings.Join(tokens[:n], ".")]; ok {
+			return fld.(*field), n
+		}
+	}
+	return nil, 0
+}
+
+// pointerChild returns the value referenced by the token in the val.
+func pointerChild(val reflect.Value, token string, alloc bool) (reflect.Value, error) {
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			if !alloc {
+				return reflect.Value{}, fmt.Errorf("nil pointer at %q", token)
+			}
+			val.Set(reflect.New(val.Type().Elem()))
+		}
+		val = val.Elem()
+	}
+	switch val.Kind() {
+	case reflect.Struct:
+		if !val.CanAddr() {
+			return reflect.Value{}, fmt.Errorf("can't reference %q in not addressable %v", token, val.Type())
+		}
+		fld, _ := findByTagTokens(val.Type(), []string{token})
+		if fld == nil {
+			return reflect.Value{}, fmt.Errorf("field %q not found in %v", token, val.Type())
+		}
+		ptr, err := fld.tryGetPtr(val.Addr().Interface(), alloc)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.NewAt(fld.Type, ptr).Elem(), nil
+	case reflect.Slice, reflect.Array:
+		i, err := pointerIndex(token, val.Len())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return val.Index(i), nil
+	case reflect.Map:
+		if val.Type().Key().Kind() != reflect.String {
+			return reflect.Value{}, fmt.Errorf("not supported map key type: %v", val.Type().Key())
+		}
+		item := val.MapIndex(reflect.ValueOf(token).Convert(val.Type().Key()))
+		if !item.IsValid() {
+			return reflect.Value{}, fmt.Errorf("key %q not found", token)
+		}
+		return item, nil
+	default:
+		return reflect.Value{}, fmt.Errorf("can't reference %q in %v", token, val.Type())
+	}
+}
+
+func setPointerChild(val reflect.Value, token string, v any) error {
+	switch val.Kind() {
+	case reflect.Map:
+		if val.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("not supported map key type: %v", val.Type().Key())
+		}
+		newVal, err := pointerValue(v, val.Type().Elem())
+		if err != nil {
+			return err
+		}
+		if val.IsNil() {
+			val.Set(reflect.MakeMap(val.Type()))
+		}
+		val.SetMapIndex(reflect.ValueOf(token).Convert(val.Type().Key()), newVal)
+		return nil
+	case reflect.Slice:
+		if token == "-" {
+			newVal, err := pointerValue(v, val.Type().Elem())
+			if err != nil {
+				return err
+			}
+			val.Set(reflect.Append(val, newVal))
+			return nil
+		}
+	}
+	target, err := pointerChild(val, token, true)
+	if err != nil {
+		return err
+	}
+	newVal, err := pointerValue(v, target.Type())
+	if err != nil {
+		return err
+	}
+	target.Set(newVal)
+	return nil
+}
+
+func pointerIndex(token string, length int) (int, error) {
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	if i >= length {
+		return 0, fmt.Errorf("array index %d out of range [0:%d]", i, length)
+	}
+	return i, nil
+}
+
+func pointerValue(v any, typeOf reflect.Type) (reflect.Value, error) {
+	if v == nil {
+		return reflect.Zero(typeOf), nil
+	}
+	valOf := reflect.ValueOf(v)
+	if !valOf.Type().AssignableTo(typeOf) {
+		return reflect.Value{}, fmt.Errorf("value of type %v is not assignable to %v", valOf.Type(), typeOf)
+	}
+	return valOf, nil
+}
@@ -0,0 +1,126 @@
+package fmap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type pointerItem struct {
+	Name string `json:"name"`
+}
+
+type pointerAddress struct {
+	City string `json:"city"`
+}
+
+type pointerOrder struct {
+	ID      int               `json:"id"`
+	Address pointerAddress    `json:"address"`
+	Items   []pointerItem     `json:"items"`
+	Labels  map[string]string `json:"labels"`
+	Owner   *pointerItem      `json:"owner"`
+	Slash   string            `json:"a/b"`
+}
+
+func TestGetPointer(t *testing.T) {
+	order := &pointerOrder{
+		ID:      1,
+		Address: pointerAddress{City: "Paris"},
+		Items:   []pointerItem{{Name: "first"}, {Name: "second"}},
+		Labels:  map[string]string{"env": "prod"},
+		Slash:   "slash",
+	}
+	tests := []struct {
+		ptr     string
+		want    any
+		wantErr bool
+	}{
+		{ptr: "/id", want: 1},
+		{ptr: "/address/city", want: "Paris"},
+		{ptr: "/address", want: pointerAddress{City: "Paris"}},
+		{ptr: "/items/0/name", want: "first"},
+		{ptr: "/items/1", want: pointerItem{Name: "second"}},
+		{ptr: "/labels/env", want: "prod"},
+		{ptr: "/a~1b", want: "slash"},
+		{ptr: "", want: *order},
+		{ptr: "/items/2/name", wantErr: true},
+		{ptr: "/items/01/name", wantErr: true},
+		{ptr: "/owner/name", wantErr: true},
+		{ptr: "/unknown", wantErr: true},
+		{ptr: "id", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ptr, func(t *testing.T) {
+			got, err := GetPointer(order, tt.ptr)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSetPointer(t *testing.T) {
+	order := &pointerOrder{
+		Items: []pointerItem{{Name: "first"}},
+	}
+	assert.NoError(t, SetPointer(order, "/address/city", "Berlin"))
+	assert.Equal(t, "Berlin", order.Address.City)
+
+	assert.NoError(t, SetPointer(order, "/items/0/name", "changed"))
+	assert.Equal(t, "changed", order.Items[0].Name)
+
+	assert.NoError(t, SetPointer(order, "/items/-", pointerItem{Name: "appended"}))
+	assert.Equal(t, []pointerItem{{Name: "changed"}, {Name: "appended"}}, order.Items)
+
+	assert.NoError(t, SetPointer(order, "/labels/env", "dev"))
+	assert.Equal(t, map[string]string{"env": "dev"}, order.Labels)
+
+	assert.NoError(t, SetPointer(order, "/owner/name", "owner"))
+	assert.Equal(t, "owner", order.Owner.Name)
+
+	assert.Error(t, SetPointer(order, "/id", "not an int"))
+	assert.Error(t, SetPointer(order, "", order))
+	assert.Error(t, SetPointer(*order, "/id", 1))
+	assert.EqualError(t, SetPointer(order, "", order), `fmap: json pointer "": the root can't be replaced`)
+	assert.EqualError(t, SetPointer(order, "/missing/name", 1), `fmap: json pointer "/missing/name": field "missing" not found in fmap.pointerOrder`)
+	_, err := GetPointer(order, "id")
+	assert.EqualError(t, err, `fmap: json pointer "id": must start with /`)
+	_, err = GetPointer(&pointerOrder{}, "/owner/name")
+	assert.EqualError(t, err, `fmap: json pointer "/owner/name": nil pointer before "name"`)
+}
+
+func TestSetPointer_MapElements(t *testing.T) {
+	type maps struct {
+		M  map[string][]int                  `json:"m"`
+		MP map[string]*pointerAddress        `json:"mp"`
+		MS map[string]pointerAddress         `json:"ms"`
+		MM map[string]map[string]pointerItem `json:"mm"`
+	}
+	obj := &maps{
+		M:  map[string][]int{"a": {1}},
+		MP: map[string]*pointerAddress{"a": nil},
+		MS: map[string]pointerAddress{"a": {City: "Paris"}},
+		MM: map[string]map[string]pointerItem{"a": {"b": {Name: "old"}}},
+	}
+	assert.NoError(t, SetPointer(obj, "/m/a/-", 2))
+	assert.Equal(t, []int{1, 2}, obj.M["a"])
+	assert.NoError(t, SetPointer(obj, "/m/a/0", 5))
+	assert.Equal(t, []int{5, 2}, obj.M["a"])
+
+	assert.NoError(t, SetPointer(obj, "/mp/a/city", "Rome"))
+	assert.Equal(t, &pointerAddress{City: "Rome"}, obj.MP["a"])
+
+	assert.NoError(t, SetPointer(obj, "/ms/a/city", "Berlin"))
+	assert.Equal(t, pointerAddress{City: "Berlin"}, obj.MS["a"])
+
+	assert.NoError(t, SetPointer(obj, "/mm/a/b/name", "new"))
+	assert.Equal(t, "new", obj.MM["a"]["b"].Name)
+
+	assert.Error(t, SetPointer(obj, "/m/missing/-", 1))
+	assert.Error(t, SetPointer(obj, "/m/a/5", 1))
+	assert.Equal(t, []int{5, 2}, obj.M["a"])
+}
@@ -0,0 +1,52 @@
+package fmap
+
+import "reflect"
+
+// LayoutEntry is the memory layout of the field reported by LayoutReport.
+type LayoutEntry struct {
+	// Path is the struct path of the field, empty for the trailing padding entry.
+	Path string
+	// Offset is the field offset in the struct, the struct size for the trailing padding entry.
+	Offset uintptr
+	// Size is the field size, zero for the trailing padding entry.
+	Size uintptr
+	// Align is the field alignment in the struct, the struct alignment for the trailing padding entry.
+	Align uintptr
+	// Padding is the number of the padding bytes before the field, or the struct end for the trailing padding entry.
+	Padding uintptr
+}
+
+// LayoutReport returns the memory layout of the top-level fields of the struct or ptr to struct obj, including
+// the unexported ones, in the struct definition order, e.g. to reorder the fields to reduce the padding.
+// The last entry with the empty Path is the trailing padding of the struct, its Offset is the total struct size.
+// The nested structs are reported as single fields, call LayoutReport for their types to see their layout.
+// It returns nil for the unsupported obj.
+func LayoutReport(obj any) []LayoutEntry {
+	typeOf, err := checkStructType(reflect.TypeOf(obj))
+	if err != nil {
+		return nil
+	}
+	fields, err := getFromCachedWithOptions(typeOf, Options{IncludeUnexported: true, MaxDepth: 1})
+	if err != nil {
+		return nil
+	}
+	entries := make([]LayoutEntry, 0, len(fields.paths)+1)
+	end := uintptr(0)
+	for _, path := range fields.paths {
+		fld := fields.asMap[path].(*field)
+		entries = append(entries, LayoutEntry{
+			Path:    path,
+			Offset:  fld.Offset,
+			Size:    fld.Type.Size(),
+			Align:   uintptr(fld.Type.FieldAlign()),
+			Padding: fld.Offset - end,
+		})
+		end = fld.Offset + fld.Type.Size()
+	}
+	structType := typeOf.Elem()
+	return append(entries, LayoutEntry{
+		Offset:  structType.Size(),
+		Align:   uintptr(structType.Align()),
+		Padding: structType.Size() - end,
+	})
+}
@@ -0,0 +1,59 @@
+package fmap
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type layoutPadded struct {
+	Flag    bool
+	Count   int64
+	enabled bool
+	Inner   struct {
+		A int32
+		B bool
+	}
+	_    int16
+	Last bool
+}
+
+func TestLayoutReport(t *testing.T) {
+	var obj layoutPadded
+	report := LayoutReport(&obj)
+	assert.Equal(t, []string{"Flag", "Count", "enabled", "Inner", "_", "Last", ""}, func() []string {
+		paths := make([]string, len(report))
+		for i, entry := range report {
+			paths[i] = entry.Path
+		}
+		return paths
+	}())
+	assert.Equal(t, LayoutEntry{Path: "Flag", Offset: 0, Size: 1, Align: 1}, report[0])
+	assert.Equal(t, LayoutEntry{
+		Path:    "Count",
+		Offset:  unsafe.Offsetof(obj.Count),
+		Size:    8,
+		Align:   unsafe.Alignof(obj.Count),
+		Padding: unsafe.Offsetof(obj.Count) - 1,
+	}, report[1])
+	assert.Equal(t, unsafe.Offsetof(obj.enabled), report[2].Offset)
+	assert.Equal(t, unsafe.Offsetof(obj.Inner)-unsafe.Offsetof(obj.enabled)-1, report[3].Padding)
+	assert.Equal(t, unsafe.Sizeof(obj.Inner), report[3].Size)
+
+	trailing := report[len(report)-1]
+	assert.Equal(t, unsafe.Sizeof(obj), trailing.Offset)
+	assert.Equal(t, unsafe.Alignof(obj), trailing.Align)
+	assert.Equal(t, unsafe.Sizeof(obj)-unsafe.Offsetof(obj.Last)-1, trailing.Padding)
+
+	// the sizes and paddings add up to the struct size
+	total := uintptr(0)
+	for _, entry := range report {
+		total += entry.Padding + entry.Size
+	}
+	assert.Equal(t, unsafe.Sizeof(obj), total)
+
+	assert.Equal(t, report, LayoutReport(obj))
+	assert.Nil(t, LayoutReport(1))
+	assert.Equal(t, []LayoutEntry{{Align: 1}}, LayoutReport(struct{}{}))
+}
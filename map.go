@@ -0,0 +1,58 @@
+package fmap
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// GetMapKey returns the value stored by the key in the map field of the provided object
+// and a boolean value indicating if the key was found.
+// It panics if the field is not a map or if the key is not assignable to the map key type.
+func (f *field) GetMapKey(obj any, key any) (any, bool) {
+	m := f.mapValue(obj, false)
+	keyOf := f.elemValue(key, m.Type().Key())
+	if m.IsNil() {
+		return nil, false
+	}
+	val := m.MapIndex(keyOf)
+	if !val.IsValid() {
+		return nil, false
+	}
+	return val.Interface(), true
+}
+
+// SetMapKey stores the val by the key in the map field of the provided object, the nil map is allocated.
+// It panics if the field is not a map or if the key or val are not assignable to the map key or value types.
+func (f *field) SetMapKey(obj any, key any, val any) {
+	m := f.mapValue(obj, true)
+	keyOf := f.elemValue(key, m.Type().Key())
+	valOf := f.elemValue(val, m.Type().Elem())
+	if m.IsNil() {
+		m.Set(reflect.MakeMap(m.Type()))
+	}
+	m.SetMapIndex(keyOf, valOf)
+}
+
+// mapValue returns the addressable map field value in the provided object.
+// The pointers to map are dereferenced, the nil ones are allocated if alloc is true.
+func (f *field) mapValue(obj any, alloc bool) reflect.Value {
+	if f.GetDereferencedType().Kind() != reflect.Map {
+		panic(fmt.Errorf("fmap: field %s: not supported type: %v, only map is supported", f.structPath, f.Type))
+	}
+	var val reflect.Value
+	if alloc {
+		val = reflect.NewAt(f.Type, f.getPtr(obj)).Elem()
+	} else {
+		val = reflect.NewAt(f.Type, f.getReadPtr(obj)).Elem()
+	}
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			if !alloc {
+				return reflect.New(f.GetDereferencedType()).Elem()
+			}
+			val.Set(reflect.New(val.Type().Elem()))
+		}
+		val = val.Elem()
+	}
+	return val
+}
@@ -0,0 +1,53 @@
+package fmap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestField_MapKey(t *testing.T) {
+	type Config struct {
+		Value int
+	}
+	type testStruct struct {
+		Configs    map[string]Config
+		PtrConfigs *map[string]Config
+		Slice      []string
+	}
+	fields, _ := Get[testStruct]()
+	configs := fields.MustFind("Configs")
+	ptrConfigs := fields.MustFind("PtrConfigs")
+
+	t.Run("Get", func(t *testing.T) {
+		obj := &testStruct{Configs: map[string]Config{"a": {Value: 1}}}
+		val, ok := configs.GetMapKey(obj, "a")
+		assert.True(t, ok)
+		assert.Equal(t, Config{Value: 1}, val)
+		_, ok = configs.GetMapKey(obj, "b")
+		assert.False(t, ok)
+		assert.Equal(t, obj.Configs, configs.Get(obj))
+	})
+	t.Run("SetNil", func(t *testing.T) {
+		obj := &testStruct{}
+		_, ok := configs.GetMapKey(obj, "a")
+		assert.False(t, ok)
+		configs.SetMapKey(obj, "a", Config{Value: 2})
+		assert.Equal(t, map[string]Config{"a": {Value: 2}}, obj.Configs)
+		ptrConfigs.SetMapKey(obj, "b", Config{Value: 3})
+		assert.Equal(t, map[string]Config{"b": {Value: 3}}, *obj.PtrConfigs)
+		val, ok := ptrConfigs.GetMapKey(obj, "b")
+		assert.True(t, ok)
+		assert.Equal(t, Config{Value: 3}, val)
+	})
+	t.Run("WrongType", func(t *testing.T) {
+		obj := &testStruct{}
+		assert.PanicsWithError(t, "fmap: field Configs: value of type int is not assignable to string", func() {
+			configs.SetMapKey(obj, 1, Config{})
+		})
+		assert.Panics(t, func() { configs.SetMapKey(obj, "a", 1) })
+		assert.Nil(t, obj.Configs)
+		assert.Panics(t, func() { configs.GetMapKey(obj, 1) })
+		assert.Panics(t, func() { fields.MustFind("Slice").GetMapKey(obj, "a") })
+	})
+}
@@ -0,0 +1,86 @@
+package fmap
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// MergeOpts configures the DeepMerge behavior for the slice and map fields.
+type MergeOpts struct {
+	// AppendSlices concatenates the src slices to the dst slices instead of replacing them.
+	AppendSlices bool
+	// UnionMaps adds the src map entries to the dst maps instead of replacing them, the src values win on conflicts.
+	UnionMaps bool
+}
+
+// DeepMerge merges the src object into the dst object, both must be non-nil pointers to the same struct type.
+// It walks through the leaf fields of the nested structs and copies every non-zero src value to the dst,
+// so the zero src values never override the dst ones. The slices and maps are replaced, concatenated or unioned
+// according to the opts. The non-nil pointers to the structs with the exported fields are merged the same way,
+// the nil dst ones are allocated, so the dst never shares the nested structs with the src.
+func DeepMerge(dst, src any, opts MergeOpts) error {
+	dstType, srcType := reflect.TypeOf(dst), reflect.TypeOf(src)
+	if dstType == nil || dstType.Kind() != reflect.Ptr {
+		return fmt.Errorf("fmap: merge: not supported dst type: %v, only ptr to struct is supported", dstType)
+	}
+	if dstType != srcType {
+		return fmt.Errorf("fmap: merge: src type %v doesn't match dst type %v", srcType, dstType)
+	}
+	fields, err := getFrom(dstType)
+	if err != nil {
+		return fmt.Errorf("fmap: merge: %w", err)
+	}
+	if reflect.ValueOf(dst).IsNil() || reflect.ValueOf(src).IsNil() {
+		return fmt.Errorf("fmap: merge: can't merge the nil %v", dstType)
+	}
+	deepMerge(fields, reflect.ValueOf(dst), reflect.ValueOf(src), opts, map[uintptr]reflect.Value{})
+	return nil
+}
+
+// deepMerge merges the non-nil src pointer into the dst one. The seen maps the merged src pointers to their dst ones,
+// so the cyclic structures are merged once and the nil dst pointers to them are set to the merged dst.
+func deepMerge(fields *storage, dst, src reflect.Value, opts MergeOpts, seen map[uintptr]reflect.Value) {
+	seen[src.Pointer()] = dst
+	for _, path := range fields.paths {
+		fld := fields.asMap[path].(*field)
+		if fld.hasChildren {
+			continue
+		}
+		srcVal := reflect.NewAt(fld.Type, fld.getReadPtr(src.Interface())).Elem()
+		if srcVal.IsZero() {
+			continue
+		}
+		dstVal := reflect.NewAt(fld.Type, fld.getPtr(dst.Interface())).Elem()
+		switch {
+		case fld.Type.Kind() == reflect.Slice && opts.AppendSlices:
+			dstVal.Set(reflect.AppendSlice(dstVal, srcVal))
+		case fld.Type.Kind() == reflect.Map && opts.UnionMaps:
+			if dstVal.IsNil() {
+				dstVal.Set(reflect.MakeMapWithSize(fld.Type, srcVal.Len()))
+			}
+			iter := srcVal.MapRange()
+			for iter.Next() {
+				dstVal.SetMapIndex(iter.Key(), iter.Value())
+			}
+		case fld.Type.Kind() == reflect.Ptr && fld.Type.Elem().Kind() == reflect.Struct:
+			elemFields, err := getFrom(fld.Type)
+			if err != nil || len(elemFields.paths) == 0 {
+				// the struct without the exported fields, e.g. time.Time, is opaque for the field map
+				dstVal.Set(srcVal)
+				continue
+			}
+			if merged, ok := seen[srcVal.Pointer()]; ok {
+				if dstVal.IsNil() {
+					dstVal.Set(merged)
+				}
+				continue
+			}
+			if dstVal.IsNil() {
+				dstVal.Set(reflect.New(fld.Type.Elem()))
+			}
+			deepMerge(elemFields, dstVal, srcVal, opts, seen)
+		default:
+			dstVal.Set(srcVal)
+		}
+	}
+}
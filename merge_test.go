@@ -0,0 +1,106 @@
+package fmap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mergeDatabase struct {
+	Host    string
+	Port    int
+	Options map[string]string
+}
+
+type mergeConfig struct {
+	Name     string
+	Debug    bool
+	Plugins  []string
+	Limits   map[string]int
+	Database mergeDatabase
+}
+
+func newMergeConfigs() (*mergeConfig, *mergeConfig) {
+	base := &mergeConfig{
+		Name:    "base",
+		Debug:   true,
+		Plugins: []string{"auth"},
+		Limits:  map[string]int{"cpu": 1, "mem": 512},
+		Database: mergeDatabase{
+			Host:    "localhost",
+			Port:    5432,
+			Options: map[string]string{"sslmode": "disable"},
+		},
+	}
+	override := &mergeConfig{
+		Name:    "prod",
+		Plugins: []string{"metrics"},
+		Limits:  map[string]int{"mem": 2048, "disk": 10},
+		Database: mergeDatabase{
+			Host:    "db",
+			Options: map[string]string{"sslmode": "require", "timeout": "5s"},
+		},
+	}
+	return base, override
+}
+
+func TestDeepMerge(t *testing.T) {
+	t.Run("Replace", func(t *testing.T) {
+		dst, src := newMergeConfigs()
+		assert.NoError(t, DeepMerge(dst, src, MergeOpts{}))
+		assert.Equal(t, "prod", dst.Name)
+		assert.Equal(t, true, dst.Debug)
+		assert.Equal(t, []string{"metrics"}, dst.Plugins)
+		assert.Equal(t, map[string]int{"mem": 2048, "disk": 10}, dst.Limits)
+		assert.Equal(t, "db", dst.Database.Host)
+		assert.Equal(t, 5432, dst.Database.Port)
+		assert.Equal(t, map[string]string{"sslmode": "require", "timeout": "5s"}, dst.Database.Options)
+	})
+	t.Run("AppendAndUnion", func(t *testing.T) {
+		dst, src := newMergeConfigs()
+		assert.NoError(t, DeepMerge(dst, src, MergeOpts{AppendSlices: true, UnionMaps: true}))
+		assert.Equal(t, []string{"auth", "metrics"}, dst.Plugins)
+		assert.Equal(t, map[string]int{"cpu": 1, "mem": 2048, "disk": 10}, dst.Limits)
+		assert.Equal(t, map[string]string{"sslmode": "require", "timeout": "5s"}, dst.Database.Options)
+	})
+	t.Run("UnionIntoNilMap", func(t *testing.T) {
+		dst := &mergeConfig{}
+		_, src := newMergeConfigs()
+		assert.NoError(t, DeepMerge(dst, src, MergeOpts{UnionMaps: true}))
+		assert.Equal(t, map[string]int{"mem": 2048, "disk": 10}, dst.Limits)
+	})
+	t.Run("TypeMismatch", func(t *testing.T) {
+		dst, _ := newMergeConfigs()
+		assert.Error(t, DeepMerge(dst, &mergeDatabase{}, MergeOpts{}))
+		assert.Error(t, DeepMerge(*dst, *dst, MergeOpts{}))
+	})
+	t.Run("NilPointers", func(t *testing.T) {
+		dst, _ := newMergeConfigs()
+		assert.EqualError(t, DeepMerge(dst, (*mergeConfig)(nil), MergeOpts{}), "fmap: merge: can't merge the nil *fmap.mergeConfig")
+		assert.Error(t, DeepMerge((*mergeConfig)(nil), dst, MergeOpts{}))
+		assert.Equal(t, "base", dst.Name)
+	})
+	t.Run("StructPointers", func(t *testing.T) {
+		type node struct {
+			Name    string
+			DB      *mergeDatabase
+			Created *time.Time
+			Next    *node
+		}
+		created := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+		dst := &node{Name: "dst", DB: &mergeDatabase{Host: "localhost", Port: 5432}}
+		src := &node{DB: &mergeDatabase{Host: "db"}, Created: &created, Next: &node{Name: "next", DB: &mergeDatabase{Port: 1}}}
+		src.Next.Next = src
+		assert.NoError(t, DeepMerge(dst, src, MergeOpts{}))
+		assert.Equal(t, &mergeDatabase{Host: "db", Port: 5432}, dst.DB)
+		assert.Equal(t, &created, dst.Created)
+		assert.Equal(t, "next", dst.Next.Name)
+		assert.Equal(t, &mergeDatabase{Port: 1}, dst.Next.DB)
+		// the nested structs aren't shared with the src, the cycle is kept
+		assert.NotSame(t, src.DB, dst.DB)
+		assert.NotSame(t, src.Next, dst.Next)
+		assert.NotSame(t, src.Next.DB, dst.Next.DB)
+		assert.Same(t, dst, dst.Next.Next)
+	})
+}
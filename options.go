@@ -0,0 +1,289 @@
+package fmap
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Options configures the Storage building in GetFromWithOptions, see the Option functions for GetFrom.
+type Options struct {
+	// PromoteEmbedded adds the promoted short names of the fields reached through the embedded (anonymous) structs,
+	// e.g. the `CreatedAt` field of the embedded `Timestamps` struct becomes available as `CreatedAt` in addition
+	// to the `Timestamps.CreatedAt` path. The collisions are resolved like the Go selectors do:
+	// the shallower field wins, and if two embedded structs promote the same name at the same depth,
+	// the name is ambiguous and not promoted at all. The promoted names are not returned by GetAllPaths.
+	PromoteEmbedded bool
+
+	// MaxDepth limits the number of the nesting levels: 1 keeps only the top-level fields,
+	// 2 adds their nested fields and so on. The struct fields at the last level are kept as leaves.
+	// The default 0 means unlimited, the depth is bounded by the struct definition itself.
+	MaxDepth int
+
+	// IncludeUnexported includes the unexported fields, which are skipped by default, for the read-only introspection,
+	// e.g. the debug dumps. Get reads them by the offset like any other field, but Set, GetPtr and the other
+	// methods giving the write access panic or return an error for them and for the fields nested in them.
+	// The embedded structs are always included regardless of the export status, as their exported fields are promoted.
+	IncludeUnexported bool
+
+	// OpaqueTypes are the struct types that are kept as leaves and never expanded, e.g. time.Time or decimal.Decimal,
+	// the fields of these types are read and set as the whole values. The embedded fields of these types are leaves too.
+	// If nil, the DefaultOpaqueTypes are used, use append(DefaultOpaqueTypes(), ...) to register additional types.
+	// The sync/atomic wrapper types, e.g. atomic.Int64, are always kept as leaves, see Field.GetAtomicValue.
+	OpaqueTypes []reflect.Type
+
+	// TagKey keys the Storage fields by their tag paths of the TagKey tag with ignored missing parent tags,
+	// e.g. "address.city" for the `json` TagKey, instead of the struct paths, see GetFromByTag.
+	// The fields without the tag and the fields tagged or nested in the fields tagged with "-" are omitted,
+	// and the promoted names aren't added. The building fails if two fields have the same tag path.
+	// The fields GetStructPath still returns the struct paths.
+	TagKey string
+
+	// StrictTags are the tags which tag paths must be unique, the empty tag stands for the TagKey.
+	// The building fails with the *TagCollisionError listing all colliding tag paths and the struct paths
+	// of their fields, e.g. the same json names of the fields promoted from the different embedded structs,
+	// instead of failing on the first collision of the TagKey paths or silently overwriting them in GetFromByTag.
+	StrictTags []string
+
+	// Cache caches the built Storage by the struct type and the other options, so the next GetFromWithOptions
+	// call with the equal options returns the same Storage, like GetFrom does without the options.
+	Cache bool
+}
+
+// Option configures the Options of GetFrom.
+type Option func(opts *Options)
+
+// WithPromoteEmbedded adds the promoted short names of the fields reached through the embedded structs,
+// see Options.PromoteEmbedded.
+func WithPromoteEmbedded() Option {
+	return func(opts *Options) {
+		opts.PromoteEmbedded = true
+	}
+}
+
+// WithMaxDepth limits the number of the nesting levels, see Options.MaxDepth.
+func WithMaxDepth(n int) Option {
+	return func(opts *Options) {
+		opts.MaxDepth = n
+	}
+}
+
+// WithIncludeUnexported includes the unexported fields for the read-only introspection, see Options.IncludeUnexported.
+func WithIncludeUnexported() Option {
+	return func(opts *Options) {
+		opts.IncludeUnexported = true
+	}
+}
+
+// WithOpaqueType keeps the typeOf struct fields as leaves in addition to the DefaultOpaqueTypes
+// and the previously registered ones, see Options.OpaqueTypes.
+func WithOpaqueType(typeOf reflect.Type) Option {
+	return func(opts *Options) {
+		opts.OpaqueTypes = append(opts.opaqueTypes(), typeOf)
+	}
+}
+
+// WithTagKey keys the fields by their tag paths of the tag, see Options.TagKey.
+func WithTagKey(tag string) Option {
+	return func(opts *Options) {
+		opts.TagKey = tag
+	}
+}
+
+// WithStrictTags fails the building if the tag paths of the tags collide, the TagKey ones if no tags are passed,
+// see Options.StrictTags.
+func WithStrictTags(tags ...string) Option {
+	return func(opts *Options) {
+		if len(tags) == 0 {
+			tags = []string{""}
+		}
+		opts.StrictTags = append(opts.StrictTags, tags...)
+	}
+}
+
+// WithCache caches the built Storage by the struct type and the other options, see Options.Cache.
+func WithCache() Option {
+	return func(opts *Options) {
+		opts.Cache = true
+	}
+}
+
+// DefaultOpaqueTypes returns the struct types that are kept as leaves by default: time.Time, big.Int, big.Float and big.Rat.
+func DefaultOpaqueTypes() []reflect.Type {
+	return []reflect.Type{
+		reflect.TypeOf(time.Time{}),
+		reflect.TypeOf(big.Int{}),
+		reflect.TypeOf(big.Float{}),
+		reflect.TypeOf(big.Rat{}),
+	}
+}
+
+// opaqueTypes returns the OpaqueTypes or the DefaultOpaqueTypes if they are not set.
+func (o Options) opaqueTypes() []reflect.Type {
+	if o.OpaqueTypes == nil {
+		return DefaultOpaqueTypes()
+	}
+	return o.OpaqueTypes
+}
+
+// GetFromWithOptions returns the Storage for the struct or ptr to struct obj built with the opts.
+// Unlike GetFrom, the result is not cached unless the Cache option is set.
+func GetFromWithOptions(obj any, opts Options) (Storage, error) {
+	typeOf, err := checkStructType(reflect.TypeOf(obj))
+	if err != nil {
+		return nil, err
+	}
+	if opts.Cache {
+		return toStorage(getFromCachedWithOptions(typeOf, opts))
+	}
+	return toStorage(buildStorage(typeOf, opts))
+}
+
+var (
+	optsCache   = map[reflect.Type][]optsCacheEntry{}
+	optsCacheMu sync.RWMutex
+)
+
+// optsCacheEntry is the cached Storage built with the opts.
+type optsCacheEntry struct {
+	opts Options
+	s    *storage
+}
+
+func getFromCachedWithOptions(typeOf reflect.Type, opts Options) (*storage, error) {
+	opts.Cache = false
+	if opts.equal(Options{}) {
+		return getFrom(typeOf)
+	}
+	optsCacheMu.RLock()
+	for _, entry := range optsCache[typeOf] {
+		if entry.opts.equal(opts) {
+			optsCacheMu.RUnlock()
+			return entry.s, nil
+		}
+	}
+	optsCacheMu.RUnlock()
+	s, err := buildStorage(typeOf, opts)
+	if err != nil {
+		return nil, err
+	}
+	optsCacheMu.Lock()
+	defer optsCacheMu.Unlock()
+	for _, entry := range optsCache[typeOf] {
+		if entry.opts.equal(opts) {
+			return entry.s, nil
+		}
+	}
+	optsCache[typeOf] = append(optsCache[typeOf], optsCacheEntry{opts: opts, s: s})
+	return s, nil
+}
+
+// equal reports whether the o and the other build the same Storage.
+func (o Options) equal(other Options) bool {
+	if o.PromoteEmbedded != other.PromoteEmbedded || o.MaxDepth != other.MaxDepth ||
+		o.IncludeUnexported != other.IncludeUnexported || o.TagKey != other.TagKey ||
+		len(o.StrictTags) != len(other.StrictTags) {
+		return false
+	}
+	for i := range o.StrictTags {
+		if o.StrictTags[i] != other.StrictTags[i] {
+			return false
+		}
+	}
+	if o.OpaqueTypes == nil && other.OpaqueTypes == nil {
+		return true
+	}
+	opaque, otherOpaque := o.opaqueTypes(), other.opaqueTypes()
+	if len(opaque) != len(otherOpaque) {
+		return false
+	}
+	for i := range opaque {
+		if opaque[i] != otherOpaque[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// buildStorage builds the storage for the ptr to struct typeOf with the opts, keyed by the tag paths if the TagKey is set.
+func buildStorage(typeOf reflect.Type, opts Options) (*storage, error) {
+	s := newStorage(typeOf, opts)
+	if err := s.checkStrictTags(opts); err != nil {
+		return nil, err
+	}
+	if opts.TagKey == "" {
+		return s, nil
+	}
+	byTag := &storage{asMap: make(map[string]Field, len(s.paths)), paths: make([]string, 0, len(s.paths))}
+	for _, path := range s.paths {
+		fld := s.asMap[path].(*field)
+		key := tagKey(fld, opts.TagKey)
+		if key == "" {
+			continue
+		}
+		if other, ok := byTag.asMap[key]; ok {
+			return nil, fmt.Errorf("fmap: field %s: tag path %s collides with the field %s", path, key, other.GetStructPath())
+		}
+		byTag.asMap[key] = fld
+		byTag.paths = append(byTag.paths, key)
+	}
+	return byTag, nil
+}
+
+// TagCollision is the tag path shared by more than one field.
+type TagCollision struct {
+	Tag     string
+	TagPath string
+	// Fields are the struct paths of the colliding fields in the struct definition order.
+	Fields []string
+}
+
+// TagCollisionError is returned by the building with the Options.StrictTags if the tag paths collide.
+type TagCollisionError struct {
+	Collisions []TagCollision
+}
+
+func (e *TagCollisionError) Error() string {
+	collisions := make([]string, 0, len(e.Collisions))
+	for _, c := range e.Collisions {
+		collisions = append(collisions, fmt.Sprintf("%s tag path %s of the fields %s", c.Tag, c.TagPath, strings.Join(c.Fields, ", ")))
+	}
+	return "fmap: colliding tag paths: " + strings.Join(collisions, "; ")
+}
+
+// checkStrictTags returns the *TagCollisionError listing all colliding tag paths of the opts.StrictTags.
+func (s *storage) checkStrictTags(opts Options) error {
+	var collisions []TagCollision
+	for _, tag := range opts.StrictTags {
+		if tag == "" {
+			tag = opts.TagKey
+		}
+		if tag == "" {
+			continue
+		}
+		byTag := map[string][]string{}
+		var tagPaths []string
+		for _, path := range s.paths {
+			key := tagKey(s.asMap[path].(*field), tag)
+			if key == "" {
+				continue
+			}
+			if _, ok := byTag[key]; !ok {
+				tagPaths = append(tagPaths, key)
+			}
+			byTag[key] = append(byTag[key], path)
+		}
+		for _, key := range tagPaths {
+			if len(byTag[key]) > 1 {
+				collisions = append(collisions, TagCollision{Tag: tag, TagPath: key, Fields: byTag[key]})
+			}
+		}
+	}
+	if len(collisions) > 0 {
+		return &TagCollisionError{Collisions: collisions}
+	}
+	return nil
+}
@@ -0,0 +1,357 @@
+package fmap
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type Timestamps struct {
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Version   int
+}
+
+type Audit struct {
+	Version int
+	Author  string
+}
+
+type promotedModel struct {
+	Timestamps
+	Audit
+	ID        int
+	UpdatedAt string
+	Inner     struct {
+		Timestamps
+	}
+}
+
+func TestGetFromWithOptions_PromoteEmbedded(t *testing.T) {
+	fields, err := GetFromWithOptions(&promotedModel{}, Options{PromoteEmbedded: true})
+	assert.NoError(t, err)
+
+	t.Run("Promoted", func(t *testing.T) {
+		obj := &promotedModel{}
+		now := time.Now()
+		createdAt, ok := fields.Find("CreatedAt")
+		assert.True(t, ok)
+		assert.Equal(t, "Timestamps.CreatedAt", createdAt.GetStructPath())
+		createdAt.Set(obj, now)
+		assert.Equal(t, now, obj.CreatedAt)
+		assert.Equal(t, now, createdAt.Get(obj))
+
+		author := fields.MustFind("Author")
+		author.Set(obj, "john")
+		assert.Equal(t, "john", obj.Author)
+	})
+	t.Run("OuterFieldWins", func(t *testing.T) {
+		updatedAt := fields.MustFind("UpdatedAt")
+		assert.Equal(t, "UpdatedAt", updatedAt.GetStructPath())
+		assert.Equal(t, "string", updatedAt.GetType().String())
+	})
+	t.Run("AmbiguousNotPromoted", func(t *testing.T) {
+		_, ok := fields.Find("Version")
+		assert.False(t, ok)
+		_, ok = fields.Find("Timestamps.Version")
+		assert.True(t, ok)
+		_, ok = fields.Find("Audit.Version")
+		assert.True(t, ok)
+	})
+	t.Run("NotEmbeddedChain", func(t *testing.T) {
+		createdAt := fields.MustFind("CreatedAt")
+		assert.Equal(t, "Timestamps.CreatedAt", createdAt.GetStructPath())
+		_, ok := fields.Find("Inner.CreatedAt")
+		assert.False(t, ok)
+	})
+	t.Run("PathsNotChanged", func(t *testing.T) {
+		defaultFields, _ := Get[promotedModel]()
+		assert.Equal(t, defaultFields.GetAllPaths(), fields.GetAllPaths())
+		_, ok := defaultFields.Find("CreatedAt")
+		assert.False(t, ok)
+	})
+	t.Run("Wrapped", func(t *testing.T) {
+		var changed []string
+		synced := Synchronized(fields)
+		hooked := WithSetHook(synced, func(fld Field, _ any, _, _ any) {
+			changed = append(changed, fld.GetStructPath())
+		})
+		for _, wrapped := range []Storage{synced, hooked, hooked.Leaves(), hooked.SubTree("Timestamps")} {
+			createdAt, ok := wrapped.Find("CreatedAt")
+			assert.True(t, ok)
+			assert.Same(t, wrapped.MustFind("Timestamps.CreatedAt"), createdAt)
+		}
+		assert.IsType(t, &SyncField{}, synced.MustFind("Author"))
+		obj := &promotedModel{}
+		hooked.MustFind("Author").Set(obj, "john")
+		assert.Equal(t, "john", obj.Author)
+		assert.Equal(t, []string{"Audit.Author"}, changed)
+		assert.Equal(t, fields.GetAllPaths(), hooked.GetAllPaths())
+	})
+	t.Run("NotAStruct", func(t *testing.T) {
+		_, err := GetFromWithOptions(5, Options{})
+		assert.Error(t, err)
+	})
+}
+
+func TestGetFromWithOptions_MaxDepth(t *testing.T) {
+	type Level3 struct {
+		Value int
+	}
+	type Level2 struct {
+		Level3 Level3
+	}
+	type Level1 struct {
+		Name   string
+		Level2 Level2
+	}
+	t.Run("Unlimited", func(t *testing.T) {
+		fields, _ := GetFromWithOptions(Level1{}, Options{})
+		assert.Equal(t, []string{"Name", "Level2", "Level2.Level3", "Level2.Level3.Value"}, fields.GetAllPaths())
+	})
+	t.Run("TopLevel", func(t *testing.T) {
+		fields, _ := GetFromWithOptions(Level1{}, Options{MaxDepth: 1})
+		assert.Equal(t, []string{"Name", "Level2"}, fields.GetAllPaths())
+		obj := &Level1{}
+		fields.MustFind("Level2").Set(obj, Level2{Level3: Level3{Value: 5}})
+		assert.Equal(t, 5, obj.Level2.Level3.Value)
+	})
+	t.Run("TwoLevels", func(t *testing.T) {
+		fields, _ := GetFromWithOptions(Level1{}, Options{MaxDepth: 2})
+		assert.Equal(t, []string{"Name", "Level2", "Level2.Level3"}, fields.GetAllPaths())
+		assert.Equal(t, []string{"Name", "Level2.Level3"}, fields.Columns(""))
+	})
+}
+
+func TestGetFromWithOptions_IncludeUnexported(t *testing.T) {
+	type secret struct {
+		Key string
+	}
+	type testStruct struct {
+		Name   string
+		token  string
+		secret secret
+		Audit
+	}
+	t.Run("Default", func(t *testing.T) {
+		fields, err := Get[testStruct]()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Name", "Audit", "Audit.Version", "Audit.Author"}, fields.GetAllPaths())
+	})
+	t.Run("TimeIsLeaf", func(t *testing.T) {
+		fields, _ := Get[Timestamps]()
+		assert.Equal(t, []string{"CreatedAt", "UpdatedAt", "Version"}, fields.GetAllPaths())
+	})
+
+	fields, err := GetFromWithOptions(testStruct{}, Options{IncludeUnexported: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"Name", "token", "secret", "secret.Key", "Audit", "Audit.Version", "Audit.Author",
+	}, fields.GetAllPaths())
+	obj := &testStruct{Name: "john", token: "t0k3n", secret: secret{Key: "k"}}
+
+	t.Run("Get", func(t *testing.T) {
+		assert.Equal(t, "t0k3n", fields.MustFind("token").Get(obj))
+		assert.Equal(t, "k", fields.MustFind("secret.Key").Get(obj))
+		assert.Equal(t, secret{Key: "k"}, fields.MustFind("secret").Get(obj))
+	})
+	t.Run("SetRejected", func(t *testing.T) {
+		assert.PanicsWithError(t, "fmap: field token: unexported field is read-only", func() {
+			fields.MustFind("token").Set(obj, "new")
+		})
+		assert.EqualError(t, fields.MustFind("token").TrySet(obj, "new"), "fmap: field token: unexported field is read-only")
+		assert.Error(t, fields.MustFind("secret.Key").TrySet(obj, "new"))
+		assert.Error(t, fields.MustFind("secret.Key").SetConvert(obj, "new"))
+		assert.Panics(t, func() { fields.MustFind("token").GetPtr(obj) })
+		assert.Equal(t, "t0k3n", obj.token)
+		assert.Equal(t, "k", obj.secret.Key)
+	})
+	t.Run("ExportedSettable", func(t *testing.T) {
+		assert.NoError(t, fields.MustFind("Name").TrySet(obj, "jane"))
+		assert.NoError(t, fields.MustFind("Audit.Author").TrySet(obj, "jane"))
+		assert.Equal(t, "jane", obj.Name)
+		assert.Equal(t, "jane", obj.Author)
+	})
+}
+
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+func TestGetFromWithOptions_OpaqueTypes(t *testing.T) {
+	type testStruct struct {
+		CreatedAt time.Time
+		DeletedAt *time.Time
+		Price     Money
+		time.Time
+	}
+	t.Run("Default", func(t *testing.T) {
+		fields, err := GetFromWithOptions(testStruct{}, Options{IncludeUnexported: true})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"CreatedAt", "DeletedAt", "Price", "Price.Amount", "Price.Currency", "Time"}, fields.GetAllPaths())
+		assert.Equal(t, []string{"CreatedAt", "DeletedAt", "Price.Amount", "Price.Currency", "Time"}, fields.Columns(""))
+
+		obj := &testStruct{}
+		now := time.Now()
+		fields.MustFind("CreatedAt").Set(obj, now)
+		fields.MustFind("Time").Set(obj, now)
+		assert.Equal(t, now, obj.CreatedAt)
+		assert.Equal(t, now, fields.MustFind("CreatedAt").Get(obj))
+		assert.Equal(t, now, obj.Time)
+	})
+	t.Run("Registered", func(t *testing.T) {
+		fields, err := GetFromWithOptions(testStruct{}, Options{
+			OpaqueTypes: append(DefaultOpaqueTypes(), reflect.TypeOf(Money{})),
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"CreatedAt", "DeletedAt", "Price", "Time"}, fields.GetAllPaths())
+		obj := &testStruct{}
+		fields.MustFind("Price").Set(obj, Money{Amount: 100, Currency: "USD"})
+		assert.Equal(t, Money{Amount: 100, Currency: "USD"}, fields.MustFind("Price").Get(obj))
+	})
+	t.Run("Big", func(t *testing.T) {
+		type bigStruct struct {
+			Int   big.Int
+			Float big.Float
+			Rat   big.Rat
+		}
+		fields, err := GetFromWithOptions(bigStruct{}, Options{IncludeUnexported: true})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Int", "Float", "Rat"}, fields.GetAllPaths())
+		obj := &bigStruct{}
+		fields.MustFind("Int").Set(obj, *big.NewInt(5))
+		assert.Equal(t, "5", obj.Int.String())
+	})
+	t.Run("Empty", func(t *testing.T) {
+		fields, err := GetFromWithOptions(testStruct{}, Options{IncludeUnexported: true, OpaqueTypes: []reflect.Type{}})
+		assert.NoError(t, err)
+		_, ok := fields.Find("CreatedAt.wall")
+		assert.True(t, ok)
+	})
+}
+
+func TestGetFrom_Options(t *testing.T) {
+	type Level2 struct {
+		Value int `json:"value"`
+	}
+	type testStruct struct {
+		Name   string `json:"name"`
+		token  string
+		Level2 Level2 `json:"level2"`
+		Price  Money
+		Timestamps
+	}
+	t.Run("Default", func(t *testing.T) {
+		fields, err := GetFrom(testStruct{})
+		assert.NoError(t, err)
+		cached, _ := GetFrom(&testStruct{})
+		assert.Same(t, fields, cached)
+		withOpts, _ := GetFromWithOptions(testStruct{}, Options{})
+		assert.Equal(t, fields.GetAllPaths(), withOpts.GetAllPaths())
+	})
+	t.Run("WithMaxDepth", func(t *testing.T) {
+		fields, err := GetFrom(testStruct{}, WithMaxDepth(1))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Name", "Level2", "Price", "Timestamps"}, fields.GetAllPaths())
+	})
+	t.Run("WithIncludeUnexported", func(t *testing.T) {
+		fields, err := GetFrom(testStruct{}, WithIncludeUnexported())
+		assert.NoError(t, err)
+		_, ok := fields.Find("token")
+		assert.True(t, ok)
+	})
+	t.Run("WithOpaqueType", func(t *testing.T) {
+		fields, err := GetFrom(testStruct{}, WithOpaqueType(reflect.TypeOf(Money{})))
+		assert.NoError(t, err)
+		_, ok := fields.Find("Price.Amount")
+		assert.False(t, ok)
+		// the default opaque types are kept
+		_, ok = fields.Find("Timestamps.CreatedAt.wall")
+		assert.False(t, ok)
+	})
+	t.Run("WithPromoteEmbedded", func(t *testing.T) {
+		fields, err := GetFrom(testStruct{}, WithPromoteEmbedded())
+		assert.NoError(t, err)
+		assert.Equal(t, "Timestamps.Version", fields.MustFind("Version").GetStructPath())
+	})
+	t.Run("WithTagKey", func(t *testing.T) {
+		fields, err := GetFrom(testStruct{}, WithTagKey("json"))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"name", "level2", "level2.value"}, fields.GetAllPaths())
+		assert.Equal(t, "Level2.Value", fields.MustFind("level2.value").GetStructPath())
+		obj := &testStruct{}
+		fields.MustFind("level2.value").Set(obj, 5)
+		assert.Equal(t, 5, obj.Level2.Value)
+
+		type collision struct {
+			A string `kv:"a"`
+			B string `kv:"a"`
+		}
+		_, err = GetFrom(collision{}, WithTagKey("kv"))
+		assert.EqualError(t, err, "fmap: field B: tag path a collides with the field A")
+	})
+	t.Run("WithStrictTags", func(t *testing.T) {
+		type Contact struct {
+			Email string `kv:"email" yaml:"email"`
+			Phone string `kv:"phone"`
+		}
+		type Billing struct {
+			Email string `kv:"email"`
+			Phone string `kv:"phone"`
+		}
+		type collisions struct {
+			Contact
+			Billing
+			Name  string `kv:"name" yaml:"name"`
+			Title string `yaml:"name"`
+		}
+		_, err := GetFrom(collisions{}, WithStrictTags("kv", "yaml"))
+		var collisionErr *TagCollisionError
+		assert.ErrorAs(t, err, &collisionErr)
+		assert.Equal(t, []TagCollision{
+			{Tag: "kv", TagPath: "email", Fields: []string{"Contact.Email", "Billing.Email"}},
+			{Tag: "kv", TagPath: "phone", Fields: []string{"Contact.Phone", "Billing.Phone"}},
+			{Tag: "yaml", TagPath: "name", Fields: []string{"Name", "Title"}},
+		}, collisionErr.Collisions)
+		assert.EqualError(t, err, "fmap: colliding tag paths: kv tag path email of the fields Contact.Email, Billing.Email; "+
+			"kv tag path phone of the fields Contact.Phone, Billing.Phone; yaml tag path name of the fields Name, Title")
+
+		// the TagKey collisions are all listed
+		_, err = GetFrom(collisions{}, WithTagKey("kv"), WithStrictTags())
+		assert.ErrorAs(t, err, &collisionErr)
+		assert.Len(t, collisionErr.Collisions, 2)
+
+		fields, err := GetFrom(testStruct{}, WithStrictTags("json"))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Name", "Level2", "Level2.Value", "Price", "Price.Amount", "Price.Currency",
+			"Timestamps", "Timestamps.CreatedAt", "Timestamps.UpdatedAt", "Timestamps.Version"}, fields.GetAllPaths())
+	})
+	t.Run("WithCache", func(t *testing.T) {
+		fields, err := GetFrom(testStruct{}, WithMaxDepth(1), WithCache())
+		assert.NoError(t, err)
+		cached, _ := GetFrom(&testStruct{}, WithCache(), WithMaxDepth(1))
+		assert.Same(t, fields, cached)
+		other, _ := GetFrom(testStruct{}, WithMaxDepth(2), WithCache())
+		assert.NotSame(t, fields, other)
+		uncached, _ := GetFrom(testStruct{}, WithMaxDepth(1))
+		assert.NotSame(t, fields, uncached)
+		opaque, _ := GetFrom(testStruct{}, WithOpaqueType(reflect.TypeOf(Money{})), WithCache())
+		opaqueCached, _ := GetFrom(testStruct{}, WithCache(), WithOpaqueType(reflect.TypeOf(Money{})))
+		assert.Same(t, opaque, opaqueCached)
+
+		// the default options share the GetFrom cache
+		defaults, _ := GetFrom(testStruct{}, WithCache())
+		cached, _ = GetFrom(testStruct{})
+		assert.Same(t, cached, defaults)
+	})
+	t.Run("Errors", func(t *testing.T) {
+		_, err := GetFrom(1, WithMaxDepth(1))
+		assert.Error(t, err)
+		_, err = GetFrom(1, WithCache())
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,163 @@
+package fmap
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// SetFromString parses the s into the field type and updates the value of the field in the provided object.
+// Pointer fields are allocated, ints and uints support base prefixes like 0x, 0o and 0b, the numbers without
+// the prefix are decimal even with the leading zeros, e.g. "010" is 10, not the octal 8,
+// bools are parsed with strconv.ParseBool, time.Duration with time.ParseDuration, e.g. "1m30s".
+// The types implementing encoding.TextUnmarshaler, e.g. net.IP or time.Time, are parsed with UnmarshalText.
+// It returns an error for the unsupported types.
+// The s is parsed before the nil embedded struct pointers on the way are allocated, so the failed parse leaves
+// the object unchanged.
+func (f *field) SetFromString(obj any, s string) error {
+	if err := f.checkObj(obj); err != nil {
+		return err
+	}
+	if err := f.checkWritable(); err != nil {
+		return err
+	}
+	val, err := parseString(f.Type, s)
+	if err != nil {
+		return fmt.Errorf("fmap: field %s: %w", f.structPath, err)
+	}
+	reflect.NewAt(f.Type, f.getPtr(obj)).Elem().Set(val)
+	return nil
+}
+
+// intBase returns the base to parse the integer s with: 0 to detect it by the 0x, 0o or 0b prefix,
+// 10 otherwise, so the leading zeros don't switch to the octal base like strconv does with the base 0.
+func intBase(s string) int {
+	if s != "" && (s[0] == '+' || s[0] == '-') {
+		s = s[1:]
+	}
+	if len(s) > 2 && s[0] == '0' {
+		switch s[1] {
+		case 'x', 'X', 'o', 'O', 'b', 'B':
+			return 0
+		}
+	}
+	return 10
+}
+
+// GetAsString returns the value of the field in the provided object formatted as the string.
+// Primitives are formatted with strconv, so the result can be parsed back with SetFromString,
+// time.Duration is formatted with its String method, e.g. "1m30s", the types implementing encoding.TextMarshaler
+// with MarshalText, composite values are formatted with fmt.Sprint. Pointers are dereferenced, the nil pointer is the empty string.
+func (f *field) GetAsString(obj any) string {
+	return formatValue(reflect.NewAt(f.Type, f.getReadPtr(obj)).Elem())
+}
+
+// formatValue formats the val as the string, see field.GetAsString.
+func formatValue(val reflect.Value) string {
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return ""
+		}
+		val = val.Elem()
+	}
+	if val.Type() == durationType {
+		return time.Duration(val.Int()).String()
+	}
+	if m, ok := textMarshaler(val); ok {
+		if text, err := m.MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+	switch val.Kind() {
+	case reflect.String:
+		return val.String()
+	case reflect.Bool:
+		return strconv.FormatBool(val.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(val.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(val.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(val.Float(), 'g', -1, val.Type().Bits())
+	default:
+		return fmt.Sprint(val.Interface())
+	}
+}
+
+// textMarshaler returns the val as the encoding.TextMarshaler, the addressable val is checked for the pointer receiver too.
+func textMarshaler(val reflect.Value) (encoding.TextMarshaler, bool) {
+	if val.Type().Implements(textMarshalerType) {
+		return val.Interface().(encoding.TextMarshaler), true
+	}
+	if val.CanAddr() && val.Addr().Type().Implements(textMarshalerType) {
+		return val.Addr().Interface().(encoding.TextMarshaler), true
+	}
+	return nil, false
+}
+
+// parseString parses s into the new value of the typ.
+// Pointer types are allocated, ints and uints support base prefixes like 0x, 0o and 0b,
+// bools are parsed with strconv.ParseBool, time.Duration with time.ParseDuration,
+// the encoding.TextUnmarshaler types with UnmarshalText.
+func parseString(typ reflect.Type, s string) (reflect.Value, error) {
+	if typ.Kind() == reflect.Ptr {
+		elem, err := parseString(typ.Elem(), s)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		ptr := reflect.New(typ.Elem())
+		ptr.Elem().Set(elem)
+		return ptr, nil
+	}
+	ptr := reflect.New(typ)
+	if ptr.Type().Implements(textUnmarshalerType) {
+		if err := ptr.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+			return reflect.Value{}, err
+		}
+		return ptr.Elem(), nil
+	}
+	val := ptr.Elem()
+	if typ == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		val.SetInt(int64(d))
+		return val, nil
+	}
+	switch typ.Kind() {
+	case reflect.String:
+		val.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		val.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, intBase(s), typ.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		val.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := strconv.ParseUint(s, intBase(s), typ.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		val.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		fl, err := strconv.ParseFloat(s, typ.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		val.SetFloat(fl)
+	default:
+		return reflect.Value{}, fmt.Errorf("not supported type: %v, can't be parsed from string", typ)
+	}
+	return val, nil
+}
@@ -0,0 +1,203 @@
+package fmap
+
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestField_SetFromString(t *testing.T) {
+	type testStruct struct {
+		String  string
+		Int     int
+		Int8    int8
+		Uint16  uint16
+		Float64 float64
+		Bool    bool
+		PtrInt  *int
+		Slice   []string
+		Timeout time.Duration
+		PtrDur  *time.Duration
+	}
+	fields, _ := Get[testStruct]()
+	obj := &testStruct{}
+	minute := time.Minute
+	tests := []struct {
+		path    string
+		s       string
+		want    any
+		wantErr bool
+	}{
+		{path: "String", s: "test", want: "test"},
+		{path: "Int", s: "-42", want: -42},
+		{path: "Int", s: "0x1f", want: 31},
+		{path: "Int", s: "0b101", want: 5},
+		{path: "Int", s: "0o17", want: 15},
+		{path: "Int", s: "010", want: 10},
+		{path: "Int", s: "-0x10", want: -16},
+		{path: "Uint16", s: "0017", want: uint16(17)},
+		{path: "Int", s: "abc", wantErr: true},
+		{path: "Int8", s: "128", wantErr: true},
+		{path: "Uint16", s: "65535", want: uint16(65535)},
+		{path: "Uint16", s: "-1", wantErr: true},
+		{path: "Float64", s: "1.5", want: 1.5},
+		{path: "Bool", s: "true", want: true},
+		{path: "Bool", s: "F", want: false},
+		{path: "Bool", s: "1", want: true},
+		{path: "Bool", s: "yes", wantErr: true},
+		{path: "PtrInt", s: "7", want: intPtr(7)},
+		{path: "Slice", s: "a,b", wantErr: true},
+		{path: "Timeout", s: "1m30s", want: 90 * time.Second},
+		{path: "Timeout", s: "-1.5h", want: -90 * time.Minute},
+		{path: "Timeout", s: "0", want: time.Duration(0)},
+		{path: "Timeout", s: "90", wantErr: true},
+		{path: "PtrDur", s: "1m", want: &minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path+"_"+tt.s, func(t *testing.T) {
+			fld := fields.MustFind(tt.path)
+			err := fld.SetFromString(obj, tt.s)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, fld.Get(obj))
+		})
+	}
+	assert.Error(t, fields.MustFind("Int").SetFromString(*obj, "1"))
+
+	t.Run("EmbeddedPtrNotAllocatedOnError", func(t *testing.T) {
+		embedded, _ := Get[embeddedRoot]()
+		root := &embeddedRoot{}
+		assert.Error(t, embedded.MustFind("embeddedMiddle.ID").SetFromString(root, "x"))
+		assert.Nil(t, root.embeddedMiddle)
+		assert.NoError(t, embedded.MustFind("embeddedMiddle.ID").SetFromString(root, "07"))
+		assert.Equal(t, 7, root.ID)
+	})
+}
+
+func TestField_GetAsString(t *testing.T) {
+	type Inner struct {
+		A int
+	}
+	type testStruct struct {
+		String  string
+		Int     int
+		Uint8   uint8
+		Float32 float32
+		Float64 float64
+		Bool    bool
+		PtrInt  *int
+		NilPtr  *string
+		Slice   []string
+		Inner   Inner
+		Timeout time.Duration
+		PtrDur  *time.Duration
+	}
+	fields, _ := Get[testStruct]()
+	minute := time.Minute
+	obj := &testStruct{
+		String:  "test",
+		Int:     -42,
+		Uint8:   255,
+		Float32: 0.1,
+		Float64: 1e21,
+		Bool:    true,
+		PtrInt:  intPtr(7),
+		Slice:   []string{"a", "b"},
+		Inner:   Inner{A: 1},
+		Timeout: 90 * time.Second,
+		PtrDur:  &minute,
+	}
+	expected := map[string]string{
+		"String":  "test",
+		"Int":     "-42",
+		"Uint8":   "255",
+		"Float32": "0.1",
+		"Float64": "1e+21",
+		"Bool":    "true",
+		"PtrInt":  "7",
+		"NilPtr":  "",
+		"Slice":   "[a b]",
+		"Inner":   "{1}",
+		"Timeout": "1m30s",
+		"PtrDur":  "1m0s",
+	}
+	for path, want := range expected {
+		assert.Equal(t, want, fields.MustFind(path).GetAsString(obj), path)
+	}
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		dst := &testStruct{}
+		for _, path := range []string{"String", "Int", "Uint8", "Float32", "Float64", "Bool", "PtrInt", "Timeout", "PtrDur"} {
+			fld := fields.MustFind(path)
+			assert.NoError(t, fld.SetFromString(dst, fld.GetAsString(obj)))
+			assert.Equal(t, fld.Get(obj), fld.Get(dst))
+		}
+	})
+}
+
+// textLevel is the string-backed enum implementing encoding.TextMarshaler and encoding.TextUnmarshaler.
+type textLevel int
+
+func (l textLevel) MarshalText() ([]byte, error) {
+	switch l {
+	case 1:
+		return []byte("low"), nil
+	case 2:
+		return []byte("high"), nil
+	}
+	return nil, fmt.Errorf("unknown level %d", int(l))
+}
+
+func (l *textLevel) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "low":
+		*l = 1
+	case "high":
+		*l = 2
+	default:
+		return fmt.Errorf("unknown level %q", text)
+	}
+	return nil
+}
+
+func TestField_TextMarshaler(t *testing.T) {
+	type testStruct struct {
+		IP       net.IP
+		PtrIP    *net.IP
+		Time     time.Time
+		PtrTime  *time.Time
+		Level    textLevel
+		PtrLevel *textLevel
+	}
+	fields, _ := Get[testStruct]()
+	obj := &testStruct{}
+	for path, s := range map[string]string{
+		"IP":       "192.168.0.1",
+		"PtrIP":    "::1",
+		"Time":     "2024-01-02T03:04:05Z",
+		"PtrTime":  "2024-01-02T03:04:05+03:00",
+		"Level":    "high",
+		"PtrLevel": "low",
+	} {
+		assert.NoError(t, fields.MustFind(path).SetFromString(obj, s), path)
+		assert.Equal(t, s, fields.MustFind(path).GetAsString(obj), path)
+	}
+	assert.Equal(t, net.ParseIP("192.168.0.1"), obj.IP)
+	assert.Equal(t, net.ParseIP("::1"), *obj.PtrIP)
+	assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), obj.Time)
+	assert.Equal(t, textLevel(2), obj.Level)
+	assert.Equal(t, textLevel(1), *obj.PtrLevel)
+
+	assert.EqualError(t, fields.MustFind("Level").SetFromString(obj, "medium"), `fmap: field Level: unknown level "medium"`)
+	assert.Error(t, fields.MustFind("IP").SetFromString(obj, "localhost"))
+	assert.Equal(t, textLevel(2), obj.Level)
+	// the MarshalText error falls back to fmt.Sprint
+	obj.Level = 3
+	assert.Equal(t, "3", fields.MustFind("Level").GetAsString(obj))
+}
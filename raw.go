@@ -0,0 +1,87 @@
+package fmap
+
+import (
+	"fmt"
+	"reflect"
+	"unsafe"
+)
+
+// HasPointers reports whether the field type contains GC-managed pointers.
+func (f *field) HasPointers() bool {
+	return f.hasPointers
+}
+
+// GetRaw returns the copy of the field memory in the provided object.
+// It returns an error for the fields containing GC-managed pointers.
+func (f *field) GetRaw(obj any) ([]byte, error) {
+	if f.hasPointers {
+		return nil, fmt.Errorf("fmap: field %s: raw access to the type %v containing pointers is not allowed", f.structPath, f.Type)
+	}
+	ptr, err := f.tryGetPtr(obj, false)
+	if err != nil {
+		return nil, err
+	}
+	raw := make([]byte, f.Type.Size())
+	copy(raw, unsafe.Slice((*byte)(ptr), len(raw)))
+	return raw, nil
+}
+
+// SetRaw overwrites the field memory in the provided object with the raw bytes.
+// The raw length must be equal to the field type size.
+// It returns an error for the fields containing GC-managed pointers.
+func (f *field) SetRaw(obj any, raw []byte) error {
+	if f.hasPointers {
+		return fmt.Errorf("fmap: field %s: raw access to the type %v containing pointers is not allowed", f.structPath, f.Type)
+	}
+	if uintptr(len(raw)) != f.Type.Size() {
+		return fmt.Errorf("fmap: field %s: wrong raw length: %d, expected %d", f.structPath, len(raw), f.Type.Size())
+	}
+	ptr, err := f.tryGetPtr(obj, true)
+	if err != nil {
+		return err
+	}
+	copy(unsafe.Slice((*byte)(ptr), len(raw)), raw)
+	return nil
+}
+
+// GetRawBytes returns the byte slice aliasing the field memory in the provided object, its length is the field
+// type size, e.g. for hashing or comparing the fixed-layout fields without knowing their type.
+// It's inherently unsafe: the slice shares the memory with the struct, so it reflects the later field updates,
+// mutating it mutates the struct bypassing the read-only fields check, and it keeps the whole struct alive.
+// For the fields containing GC-managed pointers, see HasPointers, the bytes are the addresses and the headers,
+// which are valid for the identity comparison only, and writing them corrupts the memory.
+// For the fields behind the nil embedded struct pointers it returns the zero bytes not aliasing the struct.
+// It panics like Get does for the unsupported obj.
+func (f *field) GetRawBytes(obj any) []byte {
+	return unsafe.Slice((*byte)(f.getReadPtr(obj)), f.Type.Size())
+}
+
+// CopyRawBytes returns the copy of the field memory in the provided object, the safer GetRawBytes variant:
+// the copy doesn't reflect the later field updates and mutating it doesn't affect the struct.
+// Unlike GetRaw, it's allowed for the fields containing GC-managed pointers, the copied addresses don't keep
+// the pointed values alive though. It panics like Get does for the unsupported obj.
+func (f *field) CopyRawBytes(obj any) []byte {
+	raw := make([]byte, f.Type.Size())
+	copy(raw, f.GetRawBytes(obj))
+	return raw
+}
+
+// typeHasPointers reports whether the values of the type contain GC-managed pointers.
+func typeHasPointers(typeOf reflect.Type) bool {
+	switch typeOf.Kind() {
+	case reflect.Ptr, reflect.UnsafePointer, reflect.String, reflect.Slice, reflect.Map,
+		reflect.Chan, reflect.Func, reflect.Interface:
+		return true
+	case reflect.Array:
+		return typeOf.Len() > 0 && typeHasPointers(typeOf.Elem())
+	case reflect.Struct:
+		for i := 0; i < typeOf.NumField(); i++ {
+			if typeHasPointers(typeOf.Field(i).Type) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
@@ -0,0 +1,130 @@
+package fmap
+
+import (
+	"encoding/binary"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestField_HasPointers(t *testing.T) {
+	type inner struct {
+		A int
+		B float64
+	}
+	type testStruct struct {
+		String  string
+		Int     int
+		Array   [2]int
+		PtrArr  [2]*int
+		Inner   inner
+		Slice   []int
+		Map     map[string]int
+		Ptr     *int
+		Iface   any
+		Pointer struct{ S []byte }
+	}
+	fields, _ := Get[testStruct]()
+	assert.True(t, fields.MustFind("String").HasPointers())
+	assert.False(t, fields.MustFind("Int").HasPointers())
+	assert.False(t, fields.MustFind("Array").HasPointers())
+	assert.True(t, fields.MustFind("PtrArr").HasPointers())
+	assert.False(t, fields.MustFind("Inner").HasPointers())
+	assert.True(t, fields.MustFind("Slice").HasPointers())
+	assert.True(t, fields.MustFind("Map").HasPointers())
+	assert.True(t, fields.MustFind("Ptr").HasPointers())
+	assert.True(t, fields.MustFind("Iface").HasPointers())
+	assert.True(t, fields.MustFind("Pointer").HasPointers())
+}
+
+func TestField_GetRaw(t *testing.T) {
+	type testStruct struct {
+		String string
+		Uint32 uint32
+	}
+	fields, _ := Get[testStruct]()
+	obj := &testStruct{String: "test", Uint32: 0x01020304}
+
+	raw, err := fields.MustFind("Uint32").GetRaw(obj)
+	assert.NoError(t, err)
+	assert.Len(t, raw, 4)
+	assert.Contains(t, []uint32{binary.LittleEndian.Uint32(raw), binary.BigEndian.Uint32(raw)}, uint32(0x01020304))
+	raw[0] = 0
+	assert.Equal(t, uint32(0x01020304), obj.Uint32)
+
+	_, err = fields.MustFind("String").GetRaw(obj)
+	assert.Error(t, err)
+	_, err = fields.MustFind("Uint32").GetRaw(*obj)
+	assert.Error(t, err)
+}
+
+func TestField_SetRaw(t *testing.T) {
+	type testStruct struct {
+		String string
+		Uint16 uint16
+	}
+	fields, _ := Get[testStruct]()
+	obj := &testStruct{}
+	raw, _ := fields.MustFind("Uint16").GetRaw(&testStruct{Uint16: 515})
+
+	assert.NoError(t, fields.MustFind("Uint16").SetRaw(obj, raw))
+	assert.Equal(t, uint16(515), obj.Uint16)
+	assert.Error(t, fields.MustFind("Uint16").SetRaw(obj, []byte{1}))
+	assert.Error(t, fields.MustFind("String").SetRaw(obj, make([]byte, 16)))
+}
+
+func TestField_GetRawBytes(t *testing.T) {
+	type testStruct struct {
+		String string
+		Uint32 uint32
+		Array  [3]uint8
+		*rawEmbedded
+	}
+	fields, _ := Get[testStruct]()
+	obj := &testStruct{String: "test", Uint32: 0x01020304, Array: [3]uint8{1, 2, 3}}
+
+	raw := fields.MustFind("Uint32").GetRawBytes(obj)
+	assert.Len(t, raw, 4)
+	assert.Contains(t, []uint32{binary.LittleEndian.Uint32(raw), binary.BigEndian.Uint32(raw)}, uint32(0x01020304))
+	assert.Equal(t, []byte{1, 2, 3}, fields.MustFind("Array").GetRawBytes(obj))
+
+	// the slice aliases the field memory
+	array := fields.MustFind("Array").GetRawBytes(obj)
+	array[0] = 9
+	assert.Equal(t, uint8(9), obj.Array[0])
+	obj.Array[1] = 8
+	assert.Equal(t, uint8(8), array[1])
+
+	// the fields with pointers are allowed
+	assert.Len(t, fields.MustFind("String").GetRawBytes(obj), int(unsafe.Sizeof("")))
+	other := &testStruct{String: obj.String}
+	assert.Equal(t, fields.MustFind("String").GetRawBytes(obj), fields.MustFind("String").GetRawBytes(other))
+
+	assert.Equal(t, []byte{0, 0}, fields.MustFind("rawEmbedded.Port").GetRawBytes(obj))
+	assert.Nil(t, obj.rawEmbedded)
+	assert.Panics(t, func() { fields.MustFind("Uint32").GetRawBytes(*obj) })
+}
+
+type rawEmbedded struct {
+	Port uint16
+}
+
+func TestField_CopyRawBytes(t *testing.T) {
+	type testStruct struct {
+		String string
+		Array  [3]uint8
+	}
+	fields, _ := Get[testStruct]()
+	obj := &testStruct{String: "test", Array: [3]uint8{1, 2, 3}}
+
+	raw := fields.MustFind("Array").CopyRawBytes(obj)
+	assert.Equal(t, []byte{1, 2, 3}, raw)
+	raw[0] = 9
+	assert.Equal(t, uint8(1), obj.Array[0])
+	obj.Array[1] = 8
+	assert.Equal(t, uint8(2), raw[1])
+
+	assert.Equal(t, fields.MustFind("String").GetRawBytes(obj), fields.MustFind("String").CopyRawBytes(obj))
+	assert.Panics(t, func() { fields.MustFind("Array").CopyRawBytes(nil) })
+}
@@ -0,0 +1,77 @@
+package fmap
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Rebase returns the copy of the storage which fields offsets are shifted by the delta, so the field map of the inner
+// struct type can be applied to the inner struct stored at the delta offset inside the outer struct, e.g.
+// fields.Rebase(unsafe.Offsetof(outer.Inner), reflect.TypeOf(outer)) takes the &outer instead of the &outer.Inner,
+// without building the field map of the outer type. The fields behind the embedded struct pointers keep their offsets
+// relative to the pointed structs. The original storage isn't modified.
+// The outer is the owner of the rebased fields, so they check the object type like the other fields do and are found
+// by GetFieldByPtr with the outer struct pointer. Misuse corrupts the memory, so it panics if the outer struct
+// or ptr to struct type doesn't contain the owner of the storage fields at the delta offset.
+func (s *storage) Rebase(delta uintptr, outer reflect.Type) Storage {
+	ptrType, err := checkStructType(outer)
+	if err != nil {
+		panic(fmt.Errorf("fmap: rebase: %w", err))
+	}
+	owner := ptrType.Elem()
+	for _, fld := range s.asMap {
+		if inner := fld.(*field).owner; !containsAt(owner, inner, delta) {
+			panic(fmt.Errorf("fmap: rebase: %v doesn't contain %v at the offset %d", owner, inner, delta))
+		}
+		break
+	}
+	copies := make(map[*field]*field, len(s.asMap))
+	var rebase func(fld *field) *field
+	rebase = func(fld *field) *field {
+		if fld == nil {
+			return nil
+		}
+		if c, ok := copies[fld]; ok {
+			return c
+		}
+		c := *fld
+		copies[fld] = &c
+		c.parent = rebase(fld.parent)
+		c.ptrParent = rebase(fld.ptrParent)
+		if fld.ptrParent == nil {
+			c.Offset += delta
+		}
+		c.owner = owner
+		return &c
+	}
+	rebased := &storage{asMap: make(map[string]Field, len(s.asMap)), paths: s.paths}
+	for path, fld := range s.asMap {
+		rebased.asMap[path] = rebase(fld.(*field))
+	}
+	return rebased
+}
+
+// containsAt reports whether the typeOf contains the inner type at the offset, the typeOf itself at 0,
+// the nested struct fields and array elements are searched.
+func containsAt(typeOf, inner reflect.Type, offset uintptr) bool {
+	if offset == 0 && typeOf == inner {
+		return true
+	}
+	if offset >= typeOf.Size() {
+		return false
+	}
+	switch typeOf.Kind() {
+	case reflect.Struct:
+		for i := 0; i < typeOf.NumField(); i++ {
+			fld := typeOf.Field(i)
+			if offset >= fld.Offset && containsAt(fld.Type, inner, offset-fld.Offset) {
+				return true
+			}
+		}
+	case reflect.Array:
+		if elemSize := typeOf.Elem().Size(); elemSize > 0 {
+			return containsAt(typeOf.Elem(), inner, offset%elemSize)
+		}
+	}
+	return false
+}
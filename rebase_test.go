@@ -0,0 +1,96 @@
+package fmap
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type rebaseInner struct {
+	Name  string
+	Count int
+	*embeddedLeaf
+}
+
+type rebaseOuter struct {
+	ID    int
+	Inner rebaseInner
+	Items [2]rebaseInner
+}
+
+func TestStorage_Rebase(t *testing.T) {
+	fields, _ := Get[rebaseInner]()
+	outerType := reflect.TypeOf(rebaseOuter{})
+	rebased := fields.Rebase(unsafe.Offsetof(rebaseOuter{}.Inner), outerType)
+	assert.Equal(t, fields.GetAllPaths(), rebased.GetAllPaths())
+
+	t.Run("GetSet", func(t *testing.T) {
+		obj := &rebaseOuter{ID: 1, Inner: rebaseInner{Count: 2}}
+		rebased.MustFind("Name").Set(obj, "inner")
+		assert.Equal(t, "inner", obj.Inner.Name)
+		assert.Equal(t, 2, rebased.MustFind("Count").Get(obj))
+		assert.Equal(t, 1, obj.ID)
+		assert.Equal(t, fields.MustFind("Name").GetOffset()+unsafe.Offsetof(obj.Inner), rebased.MustFind("Name").GetOffset())
+	})
+	t.Run("EmbeddedPtr", func(t *testing.T) {
+		obj := &rebaseOuter{}
+		rebased.MustFind("embeddedLeaf.Value").Set(obj, "leaf")
+		assert.NotNil(t, obj.Inner.embeddedLeaf)
+		assert.Equal(t, "leaf", obj.Inner.Value)
+		assert.Equal(t, "leaf", rebased.MustFind("embeddedLeaf.Value").Get(obj))
+		assert.Equal(t, fields.MustFind("embeddedLeaf.Value").GetOffset(), rebased.MustFind("embeddedLeaf.Value").GetOffset())
+	})
+	t.Run("ArrayElement", func(t *testing.T) {
+		obj := &rebaseOuter{}
+		second := fields.Rebase(unsafe.Offsetof(obj.Items)+unsafe.Sizeof(obj.Items[0]), outerType)
+		second.MustFind("Name").Set(obj, "second")
+		assert.Equal(t, "second", obj.Items[1].Name)
+		assert.Empty(t, obj.Items[0].Name)
+	})
+	t.Run("Twice", func(t *testing.T) {
+		type wrapper struct {
+			Pad   int64
+			Outer rebaseOuter
+		}
+		obj := &wrapper{}
+		twice := rebased.Rebase(unsafe.Offsetof(obj.Outer), reflect.TypeOf(obj))
+		twice.MustFind("Count").Set(obj, 3)
+		assert.Equal(t, 3, obj.Outer.Inner.Count)
+		assert.Panics(t, func() { twice.MustFind("Count").Set(&rebaseOuter{}, 3) })
+	})
+	t.Run("OriginalNotModified", func(t *testing.T) {
+		obj := &rebaseInner{}
+		fields.MustFind("Name").Set(obj, "name")
+		assert.Equal(t, "name", obj.Name)
+		assert.Panics(t, func() { fields.MustFind("Name").Set(&rebaseOuter{}, "name") })
+		assert.NotSame(t, fields.MustFind("Name"), rebased.MustFind("Name"))
+	})
+	t.Run("Wrappers", func(t *testing.T) {
+		obj := &rebaseOuter{}
+		var changed []string
+		hooked := WithSetHook(Synchronized(fields), func(fld Field, _ any, _, _ any) {
+			changed = append(changed, fld.GetStructPath())
+		}).Rebase(unsafe.Offsetof(obj.Inner), outerType)
+		hooked.MustFind("Name").Set(obj, "name")
+		assert.Equal(t, "name", obj.Inner.Name)
+		assert.Equal(t, []string{"Name"}, changed)
+		assert.IsType(t, &SyncField{}, Synchronized(fields).Rebase(0, reflect.TypeOf(rebaseInner{})).MustFind("Name"))
+	})
+	t.Run("Owner", func(t *testing.T) {
+		obj := &rebaseOuter{}
+		err := rebased.MustFind("Name").TrySet(&rebaseInner{}, "name")
+		assert.EqualError(t, err, "fmap: field Name: object type *fmap.rebaseInner doesn't match the field owner type fmap.rebaseOuter")
+		fld, err := rebased.GetFieldByPtr(obj, &obj.Inner.Count)
+		assert.NoError(t, err)
+		assert.Same(t, rebased.MustFind("Count"), fld)
+	})
+	t.Run("Misuse", func(t *testing.T) {
+		assert.PanicsWithError(t, "fmap: rebase: fmap.rebaseOuter doesn't contain fmap.rebaseInner at the offset 1", func() {
+			fields.Rebase(1, outerType)
+		})
+		assert.Panics(t, func() { fields.Rebase(0, reflect.TypeOf(1)) })
+		assert.NotPanics(t, func() { fields.Rebase(0, reflect.TypeOf(&rebaseInner{})) })
+	})
+}
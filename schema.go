@@ -0,0 +1,62 @@
+package fmap
+
+import "reflect"
+
+// FieldSchema is the marshalable description of the field, e.g. to generate the OpenAPI-like schema of the struct.
+// New fields may be added to it, so it should be built with GetSchema or Schema rather than by hand.
+type FieldSchema struct {
+	// Name is the Go name of the field.
+	Name string `json:"name"`
+	// Path is the struct path of the field, e.g. "Address.City".
+	Path string `json:"path"`
+	// Type is the Go type of the field, e.g. "*time.Time".
+	Type string `json:"type"`
+	// Kind is the reflect.Kind of the field type, e.g. "ptr".
+	Kind string `json:"kind"`
+	// JSONPath is the json tag path of the field with the ignored missing parent tags,
+	// empty if the field has no json tag or is excluded with the "-" one.
+	JSONPath string `json:"jsonPath,omitempty"`
+	// Optional is true for the pointer fields and the fields with the omitempty json tag option.
+	Optional bool `json:"optional"`
+	// ElemType is the element type of the pointer, slice, array, map and chan fields,
+	// the map value one for the maps, empty for the other kinds.
+	ElemType string `json:"elemType,omitempty"`
+	// Depth is the nesting level of the field, see Field.GetDepth.
+	Depth int `json:"depth"`
+}
+
+// Schema returns the FieldSchema of every field of the struct or ptr to struct obj in the struct definition order,
+// parents before their nested fields. It returns nil for the unsupported obj.
+func Schema(obj any) []FieldSchema {
+	fields, err := getFrom(reflect.TypeOf(obj))
+	if err != nil {
+		return nil
+	}
+	schema := make([]FieldSchema, 0, len(fields.paths))
+	for _, path := range fields.paths {
+		schema = append(schema, fields.asMap[path].GetSchema())
+	}
+	return schema
+}
+
+func (f *field) GetSchema() FieldSchema {
+	schema := FieldSchema{
+		Name:     f.Name,
+		Path:     f.structPath,
+		Type:     f.Type.String(),
+		Kind:     f.Type.Kind().String(),
+		JSONPath: f.GetTagPath("json", true),
+		Optional: f.Type.Kind() == reflect.Ptr,
+		Depth:    f.depth,
+	}
+	for _, opt := range f.GetTagOptions("json") {
+		if opt == "omitempty" {
+			schema.Optional = true
+		}
+	}
+	switch f.Type.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map, reflect.Chan:
+		schema.ElemType = f.Type.Elem().String()
+	}
+	return schema
+}
@@ -0,0 +1,42 @@
+package fmap
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type schemaUser struct {
+	Name    string         `json:"name"`
+	Age     *int           `json:"age"`
+	Tags    []string       `json:"tags,omitempty"`
+	Labels  map[string]int `json:"labels"`
+	Created time.Time      `json:"created"`
+	Secret  string         `json:"-"`
+	Address mapAddress     `json:"address"`
+	Extra   map[string]string
+}
+
+func TestSchema(t *testing.T) {
+	schema := Schema(&schemaUser{})
+	assert.Equal(t, []FieldSchema{
+		{Name: "Name", Path: "Name", Type: "string", Kind: "string", JSONPath: "name"},
+		{Name: "Age", Path: "Age", Type: "*int", Kind: "ptr", JSONPath: "age", Optional: true, ElemType: "int"},
+		{Name: "Tags", Path: "Tags", Type: "[]string", Kind: "slice", JSONPath: "tags", Optional: true, ElemType: "string"},
+		{Name: "Labels", Path: "Labels", Type: "map[string]int", Kind: "map", JSONPath: "labels", ElemType: "int"},
+		{Name: "Created", Path: "Created", Type: "time.Time", Kind: "struct", JSONPath: "created"},
+		{Name: "Secret", Path: "Secret", Type: "string", Kind: "string"},
+		{Name: "Address", Path: "Address", Type: "fmap.mapAddress", Kind: "struct", JSONPath: "address"},
+		{Name: "City", Path: "Address.City", Type: "string", Kind: "string", JSONPath: "address.city", Depth: 1},
+		{Name: "Street", Path: "Address.Street", Type: "string", Kind: "string", JSONPath: "address.street", Depth: 1},
+		{Name: "Extra", Path: "Extra", Type: "map[string]string", Kind: "map", ElemType: "string"},
+	}, schema)
+	assert.Equal(t, schema, Schema(schemaUser{}))
+	assert.Nil(t, Schema(1))
+
+	data, err := json.Marshal(schema[1])
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"name":"Age","path":"Age","type":"*int","kind":"ptr","jsonPath":"age","optional":true,"elemType":"int","depth":0}`, string(data))
+}
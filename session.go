@@ -0,0 +1,52 @@
+package fmap
+
+import "sync"
+
+// Session tracks the changes of the struct fields since the Track call, e.g. for the ORM-like partial updates
+// of the changed columns only. The Session methods are safe for concurrent use, the writes made to the object
+// bypassing the Session are detected by Dirty too, but they must be synchronized with the Session by the caller.
+type Session struct {
+	mu       sync.Mutex
+	obj      any
+	fields   *storage
+	snapshot any
+}
+
+// Track starts tracking the changes of the object pointed to by obj, its deep copy is taken as the snapshot
+// the current values are compared against, see DeepCopy.
+// It panics if the obj is not a non-nil pointer to struct.
+func Track(obj any) *Session {
+	fields, err := getFromPtr(obj)
+	if err != nil {
+		panic(err)
+	}
+	snapshot, err := DeepCopy(obj)
+	if err != nil {
+		panic(err)
+	}
+	return &Session{obj: obj, fields: fields, snapshot: snapshot}
+}
+
+// Set sets the val to the field with the struct path in the tracked object like Field.Set does.
+// It panics if the path is not found or the val is not assignable to the field type.
+func (s *Session) Set(path string, val any) {
+	fld := s.fields.MustFind(path)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fld.Set(s.obj, val)
+}
+
+// Dirty returns the leaf fields which values differ from the snapshot taken by Track in the struct definition order,
+// the values are compared like Field.Equal does, so the field set back to its tracked value isn't dirty.
+func (s *Session) Dirty() []Field {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var dirty []Field
+	for _, path := range s.fields.paths {
+		fld := s.fields.asMap[path].(*field)
+		if !fld.hasChildren && !fld.Equal(s.obj, s.snapshot) {
+			dirty = append(dirty, fld)
+		}
+	}
+	return dirty
+}
@@ -0,0 +1,59 @@
+package fmap
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func dirtyPaths(fields []Field) []string {
+	var paths []string
+	for _, fld := range fields {
+		paths = append(paths, fld.GetStructPath())
+	}
+	return paths
+}
+
+func TestTrack(t *testing.T) {
+	user := &mapUser{Name: "John", Tags: []string{"a"}, Address: mapAddress{City: "Paris"}}
+	session := Track(user)
+	assert.Empty(t, session.Dirty())
+
+	session.Set("Name", "Jane")
+	session.Set("Address.City", "Rome")
+	assert.Equal(t, "Jane", user.Name)
+	assert.Equal(t, []string{"Name", "Address.City"}, dirtyPaths(session.Dirty()))
+
+	// the direct writes are detected too, the snapshot is deep
+	user.Tags[0] = "b"
+	assert.Equal(t, []string{"Name", "Tags", "Address.City"}, dirtyPaths(session.Dirty()))
+
+	// the field set back to the tracked value is clean
+	session.Set("Name", "John")
+	assert.Equal(t, []string{"Tags", "Address.City"}, dirtyPaths(session.Dirty()))
+
+	assert.Panics(t, func() { session.Set("Unknown", 1) })
+	assert.Panics(t, func() { session.Set("Name", 1) })
+	assert.Panics(t, func() { Track(*user) })
+	assert.Panics(t, func() { Track((*mapUser)(nil)) })
+}
+
+func TestTrack_Concurrent(t *testing.T) {
+	type counters struct {
+		A, B int
+	}
+	obj := &counters{}
+	session := Track(obj)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			session.Set("A", i+1)
+			_ = session.Dirty()
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, []string{"A"}, dirtyPaths(session.Dirty()))
+}
@@ -0,0 +1,69 @@
+package fmap
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// SetAllError is returned by SetAll when some updates fail the validation, nothing is set in this case.
+// The Errors are ordered by the field struct paths.
+type SetAllError struct {
+	Errors []error
+}
+
+func (e *SetAllError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// SetAll sets all the updates to the object pointed to by obj with the fields Set method.
+// All updates are validated before any write like TrySet does, so either all of them are applied
+// or none of them and the *SetAllError listing every failed update is returned.
+func SetAll(obj any, updates map[Field]any) error {
+	fields := make([]Field, 0, len(updates))
+	for fld := range updates {
+		fields = append(fields, fld)
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].GetStructPath() < fields[j].GetStructPath()
+	})
+	var errs []error
+	for _, fld := range fields {
+		if err := checkSet(fld, obj, updates[fld]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return &SetAllError{Errors: errs}
+	}
+	for _, fld := range fields {
+		fld.Set(obj, updates[fld])
+	}
+	return nil
+}
+
+// setChecker is implemented by the package Field implementations to validate the update like TrySet does without
+// the write, the wrappers, e.g. SyncField and HookField, pass it to the wrapped Field.
+type setChecker interface {
+	checkSet(obj any, val any) error
+}
+
+// checkSet checks that the val can be set to the fld in the obj.
+// The Field implementations outside the package are checked with TryGet for the obj and the TrySet rules for the val.
+func checkSet(fld Field, obj any, val any) error {
+	if c, ok := fld.(setChecker); ok {
+		return c.checkSet(obj, val)
+	}
+	if _, err := fld.TryGet(obj); err != nil {
+		return err
+	}
+	if valType := reflect.TypeOf(val); !settable(fld.GetType(), valType) {
+		return fmt.Errorf("fmap: field %s: value of type %v is not assignable to %v", fld.GetStructPath(), valType, fld.GetType())
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package fmap
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetAll(t *testing.T) {
+	type config struct {
+		Host    string
+		Port    int
+		Debug   bool
+		Limits  struct{ Max int }
+		Servers []string
+	}
+	fields, _ := Get[config]()
+
+	t.Run("Apply", func(t *testing.T) {
+		obj := &config{Host: "old"}
+		err := SetAll(obj, map[Field]any{
+			fields.MustFind("Host"):       "localhost",
+			fields.MustFind("Port"):       8080,
+			fields.MustFind("Limits.Max"): 10,
+			fields.MustFind("Servers"):    []string{"a"},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "localhost", obj.Host)
+		assert.Equal(t, 8080, obj.Port)
+		assert.Equal(t, 10, obj.Limits.Max)
+		assert.Equal(t, []string{"a"}, obj.Servers)
+	})
+	t.Run("AllOrNothing", func(t *testing.T) {
+		obj := &config{Host: "old", Port: 1}
+		err := SetAll(obj, map[Field]any{
+			fields.MustFind("Host"):  "localhost",
+			fields.MustFind("Port"):  "8080",
+			fields.MustFind("Debug"): nil,
+		})
+		var setAllErr *SetAllError
+		assert.True(t, errors.As(err, &setAllErr))
+		assert.Len(t, setAllErr.Errors, 2)
+		assert.EqualError(t, err, "fmap: field Debug: value of type <nil> is not assignable to bool; "+
+			"fmap: field Port: value of type string is not assignable to int")
+		assert.Equal(t, &config{Host: "old", Port: 1}, obj)
+	})
+	t.Run("WrongObject", func(t *testing.T) {
+		err := SetAll(config{}, map[Field]any{fields.MustFind("Host"): "localhost"})
+		assert.Error(t, err)
+	})
+	t.Run("SyncField", func(t *testing.T) {
+		obj := &config{}
+		mu := &sync.RWMutex{}
+		host := NewSyncField(fields.MustFind("Host"), mu)
+		port := NewSyncField(fields.MustFind("Port"), mu)
+		assert.Error(t, SetAll(obj, map[Field]any{host: "localhost", port: 1.5}))
+		assert.Equal(t, "", obj.Host)
+		assert.NoError(t, SetAll(obj, map[Field]any{host: "localhost", port: 80}))
+		assert.Equal(t, "localhost", obj.Host)
+		assert.Equal(t, 80, obj.Port)
+	})
+	t.Run("Wrappers", func(t *testing.T) {
+		type status int
+		type record struct {
+			Ref    *int
+			Status status
+		}
+		recordFields, _ := Get[record]()
+		external := func(fld Field) Field { return struct{ Field }{fld} }
+		mu := &sync.RWMutex{}
+		wrappers := map[string]func(fld Field) Field{
+			"Hook": func(fld Field) Field { return NewHookField(fld, func(Field, any, any, any) {}) },
+			"Sync": func(fld Field) Field { return NewSyncField(fld, mu) },
+			"SyncHook": func(fld Field) Field {
+				return NewHookField(NewSyncField(fld, mu), func(Field, any, any, any) {})
+			},
+			"External": external,
+		}
+		for name, wrap := range wrappers {
+			t.Run(name, func(t *testing.T) {
+				ref, status := wrap(recordFields.MustFind("Ref")), wrap(recordFields.MustFind("Status"))
+				obj := &record{Ref: new(int), Status: 1}
+				assert.NoError(t, SetAll(obj, map[Field]any{ref: nil, status: 2}))
+				assert.Equal(t, &record{Status: 2}, obj)
+
+				err := SetAll(&config{}, map[Field]any{ref: nil, status: 3})
+				assert.Error(t, err)
+				err = SetAll((*record)(nil), map[Field]any{ref: nil})
+				assert.Error(t, err)
+				err = SetAll(obj, map[Field]any{ref: "1", status: 3})
+				assert.EqualError(t, err, "fmap: field Ref: value of type string is not assignable to *int")
+				assert.Equal(t, &record{Status: 2}, obj)
+			})
+		}
+	})
+}
@@ -0,0 +1,177 @@
+package fmap
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// GetSliceLen returns the length of the slice field in the provided object.
+// It panics if the field is not a slice.
+func (f *field) GetSliceLen(obj any) int {
+	return f.sliceValue(obj, false).Len()
+}
+
+// GetSliceIndex returns the i-th element of the slice field in the provided object.
+// It panics if the field is not a slice or if the i is out of range.
+func (f *field) GetSliceIndex(obj any, i int) any {
+	slice := f.sliceValue(obj, false)
+	f.checkIndex(i, slice.Len())
+	return slice.Index(i).Interface()
+}
+
+// SetSliceIndex sets the i-th element of the slice field in the provided object in place.
+// It panics if the field is not a slice, if the i is out of range or if the val is not assignable to the element type.
+func (f *field) SetSliceIndex(obj any, i int, val any) {
+	slice := f.sliceValue(obj, true)
+	f.checkIndex(i, slice.Len())
+	slice.Index(i).Set(f.elemValue(val, slice.Type().Elem()))
+}
+
+// SetSliceLen sets the length of the slice field in the provided object to the n, e.g. to prepare it for SetSliceIndex.
+// The shrunk slice is resliced in place, the grown one is resliced within its capacity or reallocated and copied
+// beyond it, the new elements are zero in both cases. The nil pointers to slice are allocated.
+// It panics if the field is not a slice or pointer to slice or if the n is negative.
+func (f *field) SetSliceLen(obj any, n int) {
+	if f.GetDereferencedType().Kind() != reflect.Slice {
+		panic(fmt.Errorf("fmap: field %s: not supported type: %v, only slice is supported", f.structPath, f.Type))
+	}
+	if n < 0 {
+		panic(fmt.Errorf("fmap: field %s: negative slice length %d", f.structPath, n))
+	}
+	val := reflect.NewAt(f.Type, f.getPtr(obj)).Elem()
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			val.Set(reflect.New(val.Type().Elem()))
+		}
+		val = val.Elem()
+	}
+	length := val.Len()
+	switch {
+	case n <= length:
+		val.SetLen(n)
+	case n <= val.Cap():
+		val.SetLen(n)
+		// the elements beyond the length may hold the stale values
+		zero := reflect.Zero(val.Type().Elem())
+		for i := length; i < n; i++ {
+			val.Index(i).Set(zero)
+		}
+	default:
+		grown := reflect.MakeSlice(val.Type(), n, n)
+		reflect.Copy(grown, val)
+		val.Set(grown)
+	}
+}
+
+// GetBytes returns the []byte field value in the provided object without reflection and boxing into any.
+// The underlying slice is returned without copying, so the writes to its elements modify the field.
+// The named types over []byte, e.g. json.RawMessage, are supported too.
+// It panics if the field is not a slice of bytes.
+func (f *field) GetBytes(obj any) []byte {
+	f.checkBytes()
+	return getPtrValue[[]byte](f.getReadPtr(obj))
+}
+
+// SetBytes sets the b to the []byte field in the provided object as is, without copying.
+// It panics if the field is not a slice of bytes.
+func (f *field) SetBytes(obj any, b []byte) {
+	f.checkBytes()
+	*(*[]byte)(f.getPtr(obj)) = b
+}
+
+func (f *field) checkBytes() {
+	if f.Type.Kind() != reflect.Slice || f.Type.Elem().Kind() != reflect.Uint8 {
+		panic(fmt.Errorf("fmap: field %s: not supported type: %v, only slice of bytes is supported", f.structPath, f.Type))
+	}
+}
+
+// ElementStorage returns the Storage of the element struct type of the slice or array field,
+// the pointers to struct elements and the pointers to slice are dereferenced.
+// Its fields offsets are relative to the element start, not to the field owner struct, so they take the pointer
+// to the element, see ElementPtr, e.g. the element i of the slice starts at the slice data pointer plus i element sizes.
+func (f *field) ElementStorage() (Storage, error) {
+	elemType, err := f.elemStructType()
+	if err != nil {
+		return nil, err
+	}
+	return toStorage(getFrom(elemType))
+}
+
+// ElementPtr returns the pointer to the i-th element struct of the slice or array field in the provided object
+// to be used with the ElementStorage fields: the address of the struct element or the pointer to struct element itself.
+// The nil embedded struct pointers on the way are allocated, the nil pointer elements are returned as is.
+// It panics if the field is not a slice or array of structs or pointers to structs or if the i is out of range.
+func (f *field) ElementPtr(obj any, i int) any {
+	if _, err := f.elemStructType(); err != nil {
+		panic(err)
+	}
+	val := reflect.NewAt(f.Type, f.getPtr(obj)).Elem()
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			val = reflect.New(val.Type().Elem()).Elem()
+			break
+		}
+		val = val.Elem()
+	}
+	f.checkIndex(i, val.Len())
+	elem := val.Index(i)
+	if elem.Kind() == reflect.Ptr {
+		return elem.Interface()
+	}
+	return elem.Addr().Interface()
+}
+
+// elemStructType returns the element struct type of the slice or array of structs or pointers to structs field.
+func (f *field) elemStructType() (reflect.Type, error) {
+	derefType := f.GetDereferencedType()
+	if derefType.Kind() == reflect.Slice || derefType.Kind() == reflect.Array {
+		elemType := derefType.Elem()
+		if elemType.Kind() == reflect.Ptr {
+			elemType = elemType.Elem()
+		}
+		if elemType.Kind() == reflect.Struct {
+			return elemType, nil
+		}
+	}
+	return nil, fmt.Errorf("fmap: field %s: not supported type: %v, only slice or array of structs is supported", f.structPath, f.Type)
+}
+
+// sliceValue returns the addressable slice field value in the provided object.
+// The pointers to slice are dereferenced, the nil pointer results in the nil slice.
+// The write is true for the mutating methods, so the read-only fields are rejected, see checkWritable.
+func (f *field) sliceValue(obj any, write bool) reflect.Value {
+	if f.GetDereferencedType().Kind() != reflect.Slice {
+		panic(fmt.Errorf("fmap: field %s: not supported type: %v, only slice is supported", f.structPath, f.Type))
+	}
+	var val reflect.Value
+	if write {
+		val = reflect.NewAt(f.Type, f.getPtr(obj)).Elem()
+	} else {
+		val = reflect.NewAt(f.Type, f.getReadPtr(obj)).Elem()
+	}
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return reflect.New(f.GetDereferencedType()).Elem()
+		}
+		val = val.Elem()
+	}
+	return val
+}
+
+func (f *field) checkIndex(i, length int) {
+	if i < 0 || i >= length {
+		panic(fmt.Errorf("fmap: field %s: index out of range [%d] with length %d", f.structPath, i, length))
+	}
+}
+
+// elemValue returns the val as the reflect.Value assignable to the elemType, nil is the zero value.
+func (f *field) elemValue(val any, elemType reflect.Type) reflect.Value {
+	if val == nil {
+		return reflect.Zero(elemType)
+	}
+	valOf := reflect.ValueOf(val)
+	if !valOf.Type().AssignableTo(elemType) {
+		panic(fmt.Errorf("fmap: field %s: value of type %v is not assignable to %v", f.structPath, valOf.Type(), elemType))
+	}
+	return valOf
+}
@@ -0,0 +1,248 @@
+package fmap
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestField_SliceIndex(t *testing.T) {
+	type testStruct struct {
+		Names    []string
+		PtrNames *[]string
+		Int      int
+	}
+	fields, _ := Get[testStruct]()
+	names := fields.MustFind("Names")
+	ptrNames := fields.MustFind("PtrNames")
+
+	t.Run("Get", func(t *testing.T) {
+		list := []string{"b"}
+		obj := &testStruct{Names: []string{"a", "b", "c"}, PtrNames: &list}
+		assert.Equal(t, 3, names.GetSliceLen(obj))
+		assert.Equal(t, "b", names.GetSliceIndex(obj, 1))
+		assert.Equal(t, 1, ptrNames.GetSliceLen(obj))
+		assert.Equal(t, "b", ptrNames.GetSliceIndex(obj, 0))
+	})
+	t.Run("Set", func(t *testing.T) {
+		backing := []string{"a", "b", "c"}
+		obj := &testStruct{Names: backing}
+		names.SetSliceIndex(obj, 2, "z")
+		assert.Equal(t, []string{"a", "b", "z"}, backing)
+	})
+	t.Run("Nil", func(t *testing.T) {
+		obj := &testStruct{}
+		assert.Equal(t, 0, names.GetSliceLen(obj))
+		assert.Equal(t, 0, ptrNames.GetSliceLen(obj))
+		assert.Nil(t, obj.PtrNames)
+	})
+	t.Run("OutOfRange", func(t *testing.T) {
+		obj := &testStruct{Names: []string{"a"}}
+		assert.PanicsWithError(t, "fmap: field Names: index out of range [1] with length 1", func() {
+			names.GetSliceIndex(obj, 1)
+		})
+		assert.Panics(t, func() { names.SetSliceIndex(obj, -1, "a") })
+	})
+	t.Run("WrongType", func(t *testing.T) {
+		obj := &testStruct{Names: []string{"a"}}
+		assert.Panics(t, func() { names.SetSliceIndex(obj, 0, 1) })
+		assert.Panics(t, func() { fields.MustFind("Int").GetSliceLen(obj) })
+	})
+}
+
+func TestField_SliceIndexReadOnly(t *testing.T) {
+	type testStruct struct {
+		items []string
+	}
+	fields, _ := GetFromWithOptions(testStruct{}, Options{IncludeUnexported: true})
+	items := fields.MustFind("items")
+	obj := &testStruct{items: []string{"a", "b"}}
+	assert.Equal(t, 2, items.GetSliceLen(obj))
+	assert.Equal(t, "b", items.GetSliceIndex(obj, 1))
+	assert.PanicsWithError(t, "fmap: field items: unexported field is read-only", func() {
+		items.SetSliceIndex(obj, 0, "z")
+	})
+	assert.PanicsWithError(t, "fmap: field items: unexported field is read-only", func() {
+		items.SetSliceLen(obj, 1)
+	})
+	assert.Equal(t, []string{"a", "b"}, obj.items)
+}
+
+func TestField_SetSliceLen(t *testing.T) {
+	type testStruct struct {
+		Names    []string
+		PtrNames *[]string
+		Int      int
+	}
+	fields, _ := Get[testStruct]()
+	names := fields.MustFind("Names")
+
+	t.Run("Grow", func(t *testing.T) {
+		obj := &testStruct{}
+		names.SetSliceLen(obj, 2)
+		assert.Equal(t, []string{"", ""}, obj.Names)
+		names.SetSliceIndex(obj, 1, "b")
+		assert.Equal(t, []string{"", "b"}, obj.Names)
+
+		backing := []string{"a", "b", "c"}
+		obj.Names = backing[:3]
+		names.SetSliceLen(obj, 4)
+		assert.Equal(t, []string{"a", "b", "c", ""}, obj.Names)
+		obj.Names[0] = "z"
+		// reallocated beyond the capacity
+		assert.Equal(t, "a", backing[0])
+	})
+	t.Run("WithinCapacity", func(t *testing.T) {
+		backing := []string{"a", "b", "c"}
+		obj := &testStruct{Names: backing[:1]}
+		names.SetSliceLen(obj, 3)
+		// the stale elements are zeroed in place
+		assert.Equal(t, []string{"a", "", ""}, obj.Names)
+		assert.Equal(t, []string{"a", "", ""}, backing)
+	})
+	t.Run("Shrink", func(t *testing.T) {
+		backing := []string{"a", "b", "c"}
+		obj := &testStruct{Names: backing}
+		names.SetSliceLen(obj, 1)
+		assert.Equal(t, []string{"a"}, obj.Names)
+		assert.Equal(t, 3, cap(obj.Names))
+		names.SetSliceLen(obj, 0)
+		assert.Empty(t, obj.Names)
+		assert.NotNil(t, obj.Names)
+	})
+	t.Run("Ptr", func(t *testing.T) {
+		obj := &testStruct{}
+		fields.MustFind("PtrNames").SetSliceLen(obj, 2)
+		assert.Equal(t, []string{"", ""}, *obj.PtrNames)
+	})
+	t.Run("Errors", func(t *testing.T) {
+		obj := &testStruct{}
+		assert.Panics(t, func() { fields.MustFind("Int").SetSliceLen(obj, 1) })
+		assert.Panics(t, func() { names.SetSliceLen(obj, -1) })
+		assert.Panics(t, func() { names.SetSliceLen(testStruct{}, 1) })
+	})
+}
+
+func TestField_SliceIndexNotSlicePtr(t *testing.T) {
+	type testStruct struct {
+		PtrInt *int
+	}
+	fields, _ := Get[testStruct]()
+	assert.PanicsWithError(t, "fmap: field PtrInt: not supported type: *int, only slice is supported", func() {
+		fields.MustFind("PtrInt").GetSliceLen(&testStruct{})
+	})
+}
+
+func TestField_Bytes(t *testing.T) {
+	type testStruct struct {
+		Data  []byte
+		Raw   json.RawMessage
+		Ints  []int
+		Bytes [4]byte
+	}
+	fields, _ := Get[testStruct]()
+	data := fields.MustFind("Data")
+
+	t.Run("Get", func(t *testing.T) {
+		obj := &testStruct{Data: []byte("data"), Raw: json.RawMessage(`{}`)}
+		b := data.GetBytes(obj)
+		assert.Equal(t, []byte("data"), b)
+		// the underlying slice is returned without copying
+		b[0] = 'D'
+		assert.Equal(t, []byte("Data"), obj.Data)
+		assert.Equal(t, []byte(`{}`), fields.MustFind("Raw").GetBytes(obj))
+		assert.Nil(t, data.GetBytes(&testStruct{}))
+	})
+	t.Run("Set", func(t *testing.T) {
+		obj := &testStruct{}
+		b := []byte("data")
+		data.SetBytes(obj, b)
+		assert.Equal(t, b, obj.Data)
+		assert.Same(t, &b[0], &obj.Data[0])
+		fields.MustFind("Raw").SetBytes(obj, []byte(`[]`))
+		assert.Equal(t, json.RawMessage(`[]`), obj.Raw)
+		data.SetBytes(obj, nil)
+		assert.Nil(t, obj.Data)
+	})
+	t.Run("WrongType", func(t *testing.T) {
+		obj := &testStruct{}
+		assert.PanicsWithError(t, "fmap: field Ints: not supported type: []int, only slice of bytes is supported", func() {
+			fields.MustFind("Ints").GetBytes(obj)
+		})
+		assert.Panics(t, func() { fields.MustFind("Bytes").SetBytes(obj, nil) })
+	})
+}
+
+func BenchmarkField_GetBytes(b *testing.B) {
+	type testStruct struct {
+		Data []byte
+	}
+	fields, _ := Get[testStruct]()
+	data := fields.MustFind("Data")
+	obj := &testStruct{Data: []byte("data")}
+	b.Run("GetBytes", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = data.GetBytes(obj)
+		}
+	})
+	b.Run("Get", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = data.Get(obj).([]byte)
+		}
+	})
+}
+
+func TestField_ElementStorage(t *testing.T) {
+	type Address struct {
+		City string
+		Zip  int
+	}
+	type testStruct struct {
+		Addresses    []Address
+		PtrAddresses []*Address
+		Array        [2]Address
+		PtrSlice     *[]Address
+		Names        []string
+	}
+	fields, _ := Get[testStruct]()
+	obj := &testStruct{
+		Addresses:    []Address{{City: "Paris"}, {City: "Rome"}},
+		PtrAddresses: []*Address{{City: "Berlin"}, nil},
+		PtrSlice:     &[]Address{{City: "Oslo"}},
+	}
+
+	for _, path := range []string{"Addresses", "PtrAddresses", "Array", "PtrSlice"} {
+		elemFields, err := fields.MustFind(path).ElementStorage()
+		assert.NoError(t, err, path)
+		assert.Equal(t, []string{"City", "Zip"}, elemFields.GetAllPaths(), path)
+	}
+	elemFields, _ := fields.MustFind("Addresses").ElementStorage()
+	city := elemFields.MustFind("City")
+
+	t.Run("Get", func(t *testing.T) {
+		assert.Equal(t, "Rome", city.Get(fields.MustFind("Addresses").ElementPtr(obj, 1)))
+		assert.Equal(t, "Berlin", city.Get(fields.MustFind("PtrAddresses").ElementPtr(obj, 0)))
+		assert.Equal(t, "Oslo", city.Get(fields.MustFind("PtrSlice").ElementPtr(obj, 0)))
+		assert.Nil(t, fields.MustFind("PtrAddresses").ElementPtr(obj, 1))
+	})
+	t.Run("Set", func(t *testing.T) {
+		city.Set(fields.MustFind("Addresses").ElementPtr(obj, 0), "Madrid")
+		assert.Equal(t, "Madrid", obj.Addresses[0].City)
+		city.Set(fields.MustFind("Array").ElementPtr(obj, 1), "Lisbon")
+		assert.Equal(t, "Lisbon", obj.Array[1].City)
+		elemFields.MustFind("Zip").Set(fields.MustFind("PtrAddresses").ElementPtr(obj, 0), 10115)
+		assert.Equal(t, 10115, obj.PtrAddresses[0].Zip)
+	})
+	t.Run("Errors", func(t *testing.T) {
+		_, err := fields.MustFind("Names").ElementStorage()
+		assert.EqualError(t, err, "fmap: field Names: not supported type: []string, only slice or array of structs is supported")
+		assert.Panics(t, func() { fields.MustFind("Names").ElementPtr(obj, 0) })
+		assert.PanicsWithError(t, "fmap: field Addresses: index out of range [2] with length 2", func() {
+			fields.MustFind("Addresses").ElementPtr(obj, 2)
+		})
+		assert.Panics(t, func() { fields.MustFind("PtrSlice").ElementPtr(&testStruct{}, 0) })
+	})
+}
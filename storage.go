@@ -4,21 +4,26 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
-	"unsafe"
+	"strings"
+	"sync"
 )
 
-var cache = map[reflect.Type]Storage{}
+var (
+	cache   = map[reflect.Type]*storage{}
+	cacheMu sync.RWMutex
+)
 
 // Get returns a map of field objects.
 // It takes a parameter `T` of type `any`, representing the type to be used for Fields map creation.
 func Get[T any]() (Storage, error) {
 	var tt T
-	return getFrom(reflect.TypeOf(tt))
+	return toStorage(getFrom(reflect.TypeOf(tt)))
 }
 
 type storage struct {
 	asMap map[string]Field
 	paths []string
+	tagIndexes
 }
 
 func (s *storage) Find(path string) (Field, bool) {
@@ -27,22 +32,241 @@ func (s *storage) Find(path string) (Field, bool) {
 }
 
 func (s *storage) MustFind(path string) Field {
-	return s.asMap[path]
+	field, ok := s.asMap[path]
+	if !ok {
+		panic(fieldNotFoundError(path))
+	}
+	return field
+}
+
+func (s *storage) Get(path string) (Field, bool) {
+	return s.Find(path)
+}
+
+func (s *storage) MustGet(path string) Field {
+	return s.MustFind(path)
+}
+
+// fieldNotFoundError returns the MustFind panic error for the missing path.
+func fieldNotFoundError(path string) error {
+	return fmt.Errorf("fmap: field %s: not found", path)
 }
 
 func (s *storage) GetAllPaths() []string {
 	return s.paths
 }
 
+func (s *storage) Leaves() Storage {
+	return s.filter(func(fld *field) bool {
+		return !fld.hasChildren
+	})
+}
+
+func (s *storage) Filter(pred func(f Field) bool) Storage {
+	return s.filter(func(fld *field) bool {
+		return pred(fld)
+	})
+}
+
+// filter returns the new storage containing the fields matching the pred, including the promoted names.
+func (s *storage) filter(pred func(fld *field) bool) *storage {
+	filtered := &storage{
+		asMap: map[string]Field{},
+		paths: make([]string, 0, len(s.paths)),
+	}
+	for _, path := range s.paths {
+		if pred(s.asMap[path].(*field)) {
+			filtered.paths = append(filtered.paths, path)
+		}
+	}
+	for path, fld := range s.asMap {
+		if pred(fld.(*field)) {
+			filtered.asMap[path] = fld
+		}
+	}
+	return filtered
+}
+
+func (s *storage) SubTree(path string) Storage {
+	prefix := path + "."
+	return s.filter(func(fld *field) bool {
+		return fld.structPath == path || strings.HasPrefix(fld.structPath, prefix)
+	})
+}
+
+func (s *storage) SubTreeRerooted(path string) Storage {
+	prefix := path + "."
+	rerooted := &storage{asMap: map[string]Field{}}
+	for _, fldPath := range s.paths {
+		if strings.HasPrefix(fldPath, prefix) {
+			rerooted.paths = append(rerooted.paths, fldPath[len(prefix):])
+			rerooted.asMap[fldPath[len(prefix):]] = s.asMap[fldPath]
+		}
+	}
+	return rerooted
+}
+
+func (s *storage) OfType(typeOf reflect.Type) []Field {
+	var fields []Field
+	for _, path := range s.paths {
+		if fld := s.asMap[path]; fld.GetType() == typeOf {
+			fields = append(fields, fld)
+		}
+	}
+	return fields
+}
+
+// OfTypeG is the generic variant of Storage.OfType returning the fields of the T type.
+func OfTypeG[T any](s Storage) []Field {
+	return s.OfType(reflect.TypeOf((*T)(nil)).Elem())
+}
+
+func (s *storage) Prepare() {
+	for _, fld := range s.asMap {
+		fld.(*field).prepare()
+	}
+}
+
+func (s *storage) Columns(tag string) []string {
+	columns, _ := s.columns(tag)
+	return columns
+}
+
+// columns returns the column names and the leaf fields of the storage in the definition order.
+func (s *storage) columns(tag string) ([]string, []*field) {
+	columns := make([]string, 0, len(s.paths))
+	fields := make([]*field, 0, len(s.paths))
+	for _, path := range s.paths {
+		fld := s.asMap[path].(*field)
+		if fld.hasChildren {
+			continue
+		}
+		column := path
+		if tag != "" {
+			column = fld.GetTagPath(tag, true)
+		}
+		if column == "" {
+			continue
+		}
+		columns = append(columns, column)
+		fields = append(fields, fld)
+	}
+	return columns, fields
+}
+
 // GetFrom returns a map of field objects. It takes a parameter `obj` of type `interface{}` representing the object to be analyzed.
 // The function first checks if the `obj` type is already in the cache, and if it exists, it returns the cached value.
 // Otherwise, it creates a new empty map with storage.
-func GetFrom(obj interface{}) (Storage, error) {
+// The nested structs and the embedded struct pointers are expanded, except the recursive ones:
+// the field of the struct type that is already being expanded on the path is kept as a leaf.
+// The unexported fields are skipped, except the embedded structs, see Options.IncludeUnexported.
+// The DefaultOpaqueTypes, e.g. time.Time, are kept as leaves.
+// The opts, e.g. WithMaxDepth or WithTagKey, configure the building like GetFromWithOptions does,
+// the Storage built with the opts is not cached unless WithCache is passed.
+func GetFrom(obj interface{}, opts ...Option) (Storage, error) {
+	if len(opts) == 0 {
+		return toStorage(getFrom(reflect.TypeOf(obj)))
+	}
+	var o Options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return GetFromWithOptions(obj, o)
+}
+
+// GetFromType is the GetFrom variant for the struct or ptr to struct type itself, e.g. taken from the type registry,
+// as the offsets and the tags come from the type, no instance is needed. The Storage is cached like the GetFrom one,
+// it returns an error for the other types.
+func GetFromType(typeOf reflect.Type) (Storage, error) {
+	return toStorage(getFrom(typeOf))
+}
+
+// GetFromByTag returns the fields of the struct or ptr to struct obj keyed by their tag paths with ignored
+// missing parent tags, e.g. "address.city" for the JSON names, instead of the struct paths.
+// The fields without the tag and the fields tagged or nested in the fields tagged with "-" are omitted,
+// the empty tag keys the fields by the struct paths. It returns an error if two fields have the same tag path,
+// e.g. the fields of the untagged nested structs with the same tag names.
+// The opts configure the building like in GetFrom with the tag as the TagKey, e.g. WithStrictTags reports
+// all colliding tag paths with the *TagCollisionError instead of the first one.
+func GetFromByTag(obj any, tag string, opts ...Option) (map[string]Field, error) {
+	if len(opts) > 0 {
+		var o Options
+		for _, opt := range opts {
+			opt(&o)
+		}
+		o.TagKey = tag
+		fields, err := GetFromWithOptions(obj, o)
+		if err != nil {
+			return nil, err
+		}
+		return copyTagIndex(fields.(*storage).asMap), nil
+	}
+	fields, err := getFrom(reflect.TypeOf(obj))
+	if err != nil {
+		return nil, err
+	}
+	byTag := make(map[string]Field, len(fields.paths))
+	for _, path := range fields.paths {
+		fld := fields.asMap[path].(*field)
+		key := tagKey(fld, tag)
+		if key == "" {
+			continue
+		}
+		if other, ok := byTag[key]; ok {
+			return nil, fmt.Errorf("fmap: field %s: tag path %s collides with the field %s", path, key, other.GetStructPath())
+		}
+		byTag[key] = fld
+	}
+	return byTag, nil
+}
+
+// toStorage prevents the nil *storage from being wrapped into the non-nil Storage interface.
+func toStorage(s *storage, err error) (Storage, error) {
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func getFrom(typeOf reflect.Type) (*storage, error) {
+	typeOf, err := checkStructType(typeOf)
+	if err != nil {
+		return nil, err
+	}
+	cacheMu.RLock()
+	tFields, ok := cache[typeOf]
+	cacheMu.RUnlock()
+	if ok {
+		return tFields, nil
+	}
+	tFields = newStorage(typeOf, Options{})
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	if cached, ok := cache[typeOf]; ok {
+		return cached, nil
+	}
+	cache[typeOf] = tFields
+	return tFields, nil
+}
+
+// getFromPtr is the getFrom variant for the obj that must be a ptr to struct, e.g. to be modified.
+func getFromPtr(obj any) (*storage, error) {
 	typeOf := reflect.TypeOf(obj)
-	return getFrom(typeOf)
+	if typeOf == nil || typeOf.Kind() != reflect.Ptr {
+		return nil, fmt.Errorf("fmap: not supported type: %v, only ptr to struct is supported", typeOf)
+	}
+	fields, err := getFrom(typeOf)
+	if err != nil {
+		return nil, fmt.Errorf("fmap: %w", err)
+	}
+	return fields, nil
 }
 
-func getFrom(typeOf reflect.Type) (Storage, error) {
+// checkStructType checks that the typeOf is a struct or ptr to struct and returns it as ptr to struct.
+func checkStructType(typeOf reflect.Type) (reflect.Type, error) {
+	if typeOf == nil {
+		return nil, fmt.Errorf("not supported type: %v, only struct and ptr to struct is supported", typeOf)
+	}
 	if typeOf.Kind() == reflect.Struct {
 		typeOf = reflect.PointerTo(typeOf)
 	}
@@ -50,19 +274,28 @@ func getFrom(typeOf reflect.Type) (Storage, error) {
 		(typeOf.Kind() == reflect.Pointer && typeOf.Elem().Kind() != reflect.Struct) {
 		return nil, fmt.Errorf("not supported type: %v, only struct and ptr to struct is supported", typeOf)
 	}
-	if tFields, ok := cache[typeOf]; ok {
-		return tFields, nil
-	}
-	tFields := map[string]Field{}
+	return typeOf, nil
+}
+
+// newStorage builds the storage for the ptr to struct typeOf.
+func newStorage(typeOf reflect.Type, opts Options) *storage {
 	count := new(int)
 	calculateFields(typeOf, count)
-	slice := make([]string, 0, *count)
-	getFieldsMapRecursive(typeOf, "", &tFields, &slice, 0)
-	cache[typeOf] = &storage{
-		asMap: tFields,
-		paths: slice,
+	b := &builder{
+		opts:   opts,
+		owner:  typeOf.Elem(),
+		fields: map[string]Field{},
+		paths:  make([]string, 0, *count),
+		opaque: opts.opaqueTypes(),
+	}
+	b.getFieldsMapRecursive(typeOf, "", nil, nil, 0, nil)
+	if opts.PromoteEmbedded {
+		b.promoteEmbedded()
+	}
+	return &storage{
+		asMap: b.fields,
+		paths: b.paths,
 	}
-	return cache[typeOf], nil
 }
 
 func calculateFields(confTypeOf reflect.Type, count *int) {
@@ -77,38 +310,128 @@ func calculateFields(confTypeOf reflect.Type, count *int) {
 	}
 }
 
-func getFieldsMapRecursive(confTypeOf reflect.Type, path string, f *map[string]Field, s *[]string, offset uintptr) {
+// builder collects the fields of the owner struct type.
+type builder struct {
+	opts   Options
+	owner  reflect.Type
+	fields map[string]Field
+	paths  []string
+	// stack contains the struct types on the current path, it's used to break the recursive types cycles.
+	stack []reflect.Type
+	// opaque contains the struct types that are not expanded, see Options.OpaqueTypes.
+	opaque []reflect.Type
+}
+
+// isOpaque reports whether the typeOf struct must be kept as a leaf.
+func (b *builder) isOpaque(typeOf reflect.Type) bool {
+	if isAtomicType(typeOf) {
+		return true
+	}
+	for _, t := range b.opaque {
+		if t == typeOf {
+			return true
+		}
+	}
+	return false
+}
+
+// onStack reports whether the typeOf struct is already being collected on the current path.
+func (b *builder) onStack(typeOf reflect.Type) bool {
+	for _, t := range b.stack {
+		if t == typeOf {
+			return true
+		}
+	}
+	return false
+}
+
+// getFieldsMapRecursive collects the fields of the confTypeOf struct.
+// The offset is relative to the struct pointed to by the ptrParent, or to the owner struct if the ptrParent is nil.
+func (b *builder) getFieldsMapRecursive(confTypeOf reflect.Type, path string, parent, ptrParent *field, offset uintptr, index []int) {
 	if confTypeOf.Kind() == reflect.Ptr {
 		confTypeOf = confTypeOf.Elem()
 	}
 	if path != "" {
 		path += "."
 	}
+	b.stack = append(b.stack, confTypeOf)
+	defer func() { b.stack = b.stack[:len(b.stack)-1] }()
 	for i := 0; i < confTypeOf.NumField(); i++ {
 		fieldTypeOf := confTypeOf.Field(i)
-		var parent *field = nil
-		if path != "" {
-			parentPath := path[:len(path)-1]
-			parent, _ = (*f)[parentPath].(*field)
+		unexported := !fieldTypeOf.IsExported() && !fieldTypeOf.Anonymous
+		if unexported && !b.opts.IncludeUnexported {
+			continue
+		}
+		fld := &field{
+			StructField: fieldTypeOf,
+			structPath:  path + fieldTypeOf.Name,
+			parent:      parent,
+			owner:       b.owner,
+			ptrParent:   ptrParent,
+			structType:  confTypeOf,
+		}
+		fld.Offset = fld.Offset + offset
+		fld.Index = append(index[:len(index):len(index)], i)
+		fld.readOnly = unexported
+		if parent != nil {
+			fld.depth = parent.depth + 1
+			fld.readOnly = fld.readOnly || parent.readOnly
+			fld.promoted = parent.Anonymous || parent.promoted
+		}
+		// fill the dereferenced type and element caches before the field is shared between goroutines
+		fld.GetDereferencedType()
+		if fld.hasElem() {
+			fld.elemType = fld.Type.Elem()
+			fld.elemSize = fld.elemType.Size()
 		}
-		switch fieldTypeOf.Type.Kind() {
-		case reflect.Struct:
-			fld := &field{StructField: fieldTypeOf, structPath: path + fieldTypeOf.Name, parent: parent}
-			(*f)[path+fieldTypeOf.Name] = fld
-			*s = append(*s, fld.structPath)
-			getFieldsMapRecursive(fieldTypeOf.Type, path+fieldTypeOf.Name, f, s, offset+fieldTypeOf.Offset)
-		default:
-			fld := &field{StructField: fieldTypeOf, structPath: path + fieldTypeOf.Name, parent: parent}
-			fld.Offset = fld.Offset + offset
-			*s = append(*s, fld.structPath)
-			(*f)[path+fieldTypeOf.Name] = fld
+		fld.hasPointers = typeHasPointers(fld.Type)
+		if parent != nil {
+			parent.hasChildren = true
+		}
+		b.fields[fld.structPath] = fld
+		b.paths = append(b.paths, fld.structPath)
+		if b.opts.MaxDepth > 0 && fld.depth+1 >= b.opts.MaxDepth {
+			continue
+		}
+		if b.onStack(fld.GetDereferencedType()) {
+			// recursive type, the field is kept as a leaf
+			continue
+		}
+		if b.isOpaque(fld.GetDereferencedType()) {
+			continue
+		}
+		switch {
+		case fieldTypeOf.Type.Kind() == reflect.Struct:
+			b.getFieldsMapRecursive(fieldTypeOf.Type, fld.structPath, fld, ptrParent, fld.Offset, fld.Index)
+		case fld.isEmbeddedStructPtr():
+			// the fields behind the pointer are not at the fixed offset, see field.basePtr
+			b.getFieldsMapRecursive(fieldTypeOf.Type, fld.structPath, fld, fld, 0, fld.Index)
+		}
+	}
+}
+
+// promoteEmbedded adds the promoted names of the fields reached through the embedded structs.
+// The alias is added only if the Go selector with the same name resolves to the field,
+// so the outer fields shadow the promoted ones and the ambiguous names aren't promoted at all.
+func (b *builder) promoteEmbedded() {
+	for _, path := range b.paths {
+		fld := b.fields[path].(*field)
+		if fld.parent == nil || !fld.parent.isEmbeddedChain() {
+			continue
+		}
+		if _, ok := b.fields[fld.Name]; ok {
+			continue
+		}
+		promoted, ok := b.owner.FieldByName(fld.Name)
+		if ok && reflect.DeepEqual(promoted.Index, fld.Index) {
+			b.fields[fld.Name] = fld
 		}
 	}
 }
 
 func (s *storage) GetFieldByPtr(structPtr, fieldPtr any) (Field, error) {
 	fldType := reflect.TypeOf(fieldPtr)
-	if fldType.Kind() != reflect.Ptr {
+	if fldType == nil || fldType.Kind() != reflect.Ptr {
 		return nil, fmt.Errorf("not supported type: %v, only ptr to types is supported", fldType)
 	}
 
@@ -117,19 +440,22 @@ func (s *storage) GetFieldByPtr(structPtr, fieldPtr any) (Field, error) {
 	}
 
 	structType := reflect.TypeOf(structPtr)
-	if structType.Kind() != reflect.Ptr ||
+	if structType == nil || structType.Kind() != reflect.Ptr ||
 		(structType.Kind() == reflect.Ptr && structType.Elem().Kind() != reflect.Struct) {
-		return nil, fmt.Errorf("not supported type: %v, only struct and ptr to struct is supported", structType)
+		return nil, fmt.Errorf("not supported type: %v, only ptr to struct is supported", structType)
 	}
 
-	fPtr := ((*[2]unsafe.Pointer)(unsafe.Pointer(&fieldPtr)))[1]
-	sPtr := ((*[2]unsafe.Pointer)(unsafe.Pointer(&structPtr)))[1]
+	fPtr := objPointer(fieldPtr)
+	sPtr := objPointer(structPtr)
+	if fPtr == nil || sPtr == nil {
+		return nil, errors.New("nil pointer is not supported")
+	}
 	offset := uintptr(fPtr) - uintptr(sPtr)
 
 	for _, path := range s.GetAllPaths() {
 		fld := s.MustFind(path)
 
-		if fld.GetOffset() != offset {
+		if fld.(*field).owner != structType.Elem() || fld.(*field).ptrParent != nil || fld.GetOffset() != offset {
 			continue
 		}
 
@@ -1,10 +1,14 @@
 package fmap
 
 import (
-	"github.com/stretchr/testify/assert"
+	"errors"
+	"io"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestFmap(t *testing.T) {
@@ -69,6 +73,31 @@ func TestFmap(t *testing.T) {
 	})
 }
 
+func TestStorage_MustFind(t *testing.T) {
+	fields, _ := Get[TestStruct]()
+	synced := Synchronized(fields)
+	for name, storage := range map[string]Storage{"Storage": fields, "Synchronized": synced} {
+		t.Run(name, func(t *testing.T) {
+			fld, ok := storage.Get("NestedStruct.String")
+			assert.True(t, ok)
+			assert.Equal(t, "NestedStruct.String", fld.GetStructPath())
+			assert.Equal(t, fld, storage.MustGet("NestedStruct.String"))
+			assert.Equal(t, fld, storage.MustFind("NestedStruct.String"))
+
+			_, ok = storage.Get("NestedStruct.Strin")
+			assert.False(t, ok)
+			assert.PanicsWithError(t, "fmap: field NestedStruct.Strin: not found", func() {
+				storage.MustFind("NestedStruct.Strin")
+			})
+			assert.PanicsWithError(t, "fmap: field NestedStruct.Strin: not found", func() {
+				storage.MustGet("NestedStruct.Strin")
+			})
+		})
+	}
+	_, isSync := synced.MustGet("String").(*SyncField)
+	assert.True(t, isSync)
+}
+
 func BenchmarkGetFrom(b *testing.B) {
 	tt := TestStruct{}
 	for i := 0; i < b.N; i++ {
@@ -106,6 +135,64 @@ func BenchmarkFieldSet(b *testing.B) {
 	}
 }
 
+type benchNested struct {
+	ID    int
+	Name  string
+	Tags  []string
+	Score [4]float64
+}
+
+type benchOwner struct {
+	Nested benchNested
+}
+
+func BenchmarkFieldSetStruct(b *testing.B) {
+	fields, _ := Get[benchOwner]()
+	fields.Prepare()
+	fld := fields.MustFind("Nested")
+	tt := &benchOwner{}
+	var val any = benchNested{ID: 1, Name: "name", Tags: []string{"a"}}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		fld.Set(tt, val)
+	}
+}
+
+func TestStorage_Prepare(t *testing.T) {
+	// the struct type created at runtime has no precomputed pointer type
+	nestedType := reflect.StructOf([]reflect.StructField{
+		{Name: "ID", Type: reflect.TypeOf(0)},
+		{Name: "Name", Type: reflect.TypeOf("")},
+	})
+	ownerType := reflect.StructOf([]reflect.StructField{
+		{Name: "Nested", Type: nestedType},
+		{Name: "List", Type: reflect.ArrayOf(2, nestedType)},
+	})
+	obj := reflect.New(ownerType).Interface()
+	fields, err := GetFrom(obj)
+	assert.NoError(t, err)
+	fields.Prepare()
+	fields.Prepare()
+
+	list := fields.MustFind("List").(*field)
+	assert.Equal(t, nestedType, list.elemType)
+	assert.Equal(t, nestedType.Size(), list.elemSize)
+
+	val := reflect.New(nestedType).Elem()
+	val.Field(0).SetInt(5)
+	val.Field(1).SetString("name")
+	nested := fields.MustFind("Nested")
+	iface := val.Interface()
+	allocs := testing.AllocsPerRun(10, func() {
+		nested.Set(obj, iface)
+		list.SetArrayIndex(obj, 1, iface)
+	})
+	assert.Equal(t, float64(0), allocs)
+	assert.Equal(t, iface, nested.Get(obj))
+	assert.Equal(t, iface, list.GetArrayIndex(obj, 1))
+}
+
 func BenchmarkRawFieldGet(b *testing.B) {
 	tt := TestStruct{}
 	for i := 0; i < b.N; i++ {
@@ -284,3 +371,409 @@ func Test_storage_GetFieldByPtr(t *testing.T) {
 		})
 	}
 }
+
+type embeddedLeaf struct {
+	Value string
+	Count int
+}
+
+type embeddedMiddle struct {
+	ID int
+	embeddedLeaf
+}
+
+type embeddedRoot struct {
+	Name string
+	*embeddedMiddle
+}
+
+func TestFmap_EmbeddedPtr(t *testing.T) {
+	fields, err := Get[embeddedRoot]()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"Name",
+		"embeddedMiddle",
+		"embeddedMiddle.ID",
+		"embeddedMiddle.embeddedLeaf",
+		"embeddedMiddle.embeddedLeaf.Value",
+		"embeddedMiddle.embeddedLeaf.Count",
+	}, fields.GetAllPaths())
+
+	t.Run("GetNilPtr", func(t *testing.T) {
+		obj := &embeddedRoot{}
+		assert.Equal(t, "", fields.MustFind("embeddedMiddle.embeddedLeaf.Value").Get(obj))
+		assert.Equal(t, 0, fields.MustFind("embeddedMiddle.ID").Get(obj))
+		assert.Nil(t, obj.embeddedMiddle)
+	})
+	t.Run("SetNilPtr", func(t *testing.T) {
+		obj := &embeddedRoot{}
+		fields.MustFind("embeddedMiddle.embeddedLeaf.Value").Set(obj, "test")
+		fields.MustFind("embeddedMiddle.embeddedLeaf.Count").Set(obj, 5)
+		fields.MustFind("embeddedMiddle.ID").Set(obj, 7)
+		assert.Equal(t, "test", obj.Value)
+		assert.Equal(t, 5, obj.Count)
+		assert.Equal(t, 7, obj.ID)
+	})
+	t.Run("GetSet", func(t *testing.T) {
+		obj := &embeddedRoot{Name: "root", embeddedMiddle: &embeddedMiddle{ID: 1, embeddedLeaf: embeddedLeaf{Value: "a"}}}
+		middle := obj.embeddedMiddle
+		assert.Equal(t, "a", fields.MustFind("embeddedMiddle.embeddedLeaf.Value").Get(obj))
+		assert.Equal(t, embeddedLeaf{Value: "a"}, fields.MustFind("embeddedMiddle.embeddedLeaf").Get(obj))
+		fields.MustFind("embeddedMiddle.embeddedLeaf.Value").Set(obj, "b")
+		assert.Equal(t, "b", middle.Value)
+		assert.Same(t, middle, obj.embeddedMiddle)
+		*fields.MustFind("embeddedMiddle.ID").GetPtr(obj).(*int) = 2
+		assert.Equal(t, 2, middle.ID)
+	})
+	t.Run("GetFieldByPtr", func(t *testing.T) {
+		obj := &embeddedRoot{embeddedMiddle: &embeddedMiddle{}}
+		fld, err := fields.GetFieldByPtr(obj, &obj.Name)
+		assert.NoError(t, err)
+		assert.Equal(t, "Name", fld.GetStructPath())
+	})
+}
+
+type embeddedReader struct {
+	io.Reader
+	Name string
+}
+
+type embeddedStringer interface {
+	String() string
+}
+
+type embeddedUnexportedIface struct {
+	embeddedStringer
+}
+
+func TestFmap_EmbeddedInterface(t *testing.T) {
+	fields, err := GetFrom(embeddedReader{}, WithPromoteEmbedded())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Reader", "Name"}, fields.GetAllPaths())
+	fld := fields.MustFind("Reader")
+	assert.True(t, fld.GetAnonymous())
+	assert.Equal(t, reflect.TypeOf((*io.Reader)(nil)).Elem(), fld.GetType())
+
+	t.Run("GetSet", func(t *testing.T) {
+		obj := &embeddedReader{}
+		assert.Nil(t, fld.Get(obj))
+		reader := strings.NewReader("data")
+		fld.Set(obj, reader)
+		assert.Same(t, reader, obj.Reader)
+		assert.Same(t, reader, fld.Get(obj))
+		assert.Same(t, reader, fld.GetByIndex(obj))
+		data, _ := io.ReadAll(obj)
+		assert.Equal(t, "data", string(data))
+		fld.Set(obj, nil)
+		assert.Nil(t, obj.Reader)
+	})
+	t.Run("TrySet", func(t *testing.T) {
+		obj := &embeddedReader{Reader: strings.NewReader("data")}
+		assert.Error(t, fld.TrySet(obj, "data"))
+		assert.NoError(t, fld.TrySet(obj, nil))
+		assert.Nil(t, obj.Reader)
+		assert.NoError(t, fld.TrySet(obj, io.Reader(strings.NewReader("data"))))
+		assert.NotNil(t, obj.Reader)
+	})
+	t.Run("Unexported", func(t *testing.T) {
+		fields, err := Get[embeddedUnexportedIface]()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"embeddedStringer"}, fields.GetAllPaths())
+		obj := &embeddedUnexportedIface{}
+		fields.MustFind("embeddedStringer").Set(obj, time.Second)
+		assert.Equal(t, "1s", obj.String())
+	})
+}
+
+type recursiveEmbedded struct {
+	Value int
+	*recursiveEmbedded
+}
+
+func TestFmap_EmbeddedPtrRecursive(t *testing.T) {
+	fields, err := Get[recursiveEmbedded]()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Value", "recursiveEmbedded"}, fields.GetAllPaths())
+}
+
+type linkedNode struct {
+	Value int
+	Next  *linkedNode
+}
+
+type treeNode struct {
+	Name string
+	*treeNode
+	Data struct {
+		Parent *treeNode
+	}
+}
+
+func TestFmap_RecursiveTypes(t *testing.T) {
+	t.Run("LinkedList", func(t *testing.T) {
+		fields, err := Get[linkedNode]()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Value", "Next"}, fields.GetAllPaths())
+		obj := &linkedNode{}
+		next := &linkedNode{Value: 2}
+		fields.MustFind("Next").Set(obj, next)
+		assert.Same(t, next, obj.Next)
+		assert.Same(t, next, fields.MustFind("Next").Get(obj))
+	})
+	t.Run("EmbeddedCycle", func(t *testing.T) {
+		fields, err := Get[treeNode]()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Name", "treeNode", "Data", "Data.Parent"}, fields.GetAllPaths())
+		obj := &treeNode{}
+		fields.MustFind("treeNode").Set(obj, &treeNode{Name: "parent"})
+		assert.Equal(t, "parent", obj.treeNode.Name)
+	})
+}
+
+func TestStorage_Leaves(t *testing.T) {
+	type Address struct {
+		City string
+	}
+	type User struct {
+		Name    string
+		Tags    []string
+		Meta    map[string]string
+		Address Address
+		Empty   struct{}
+		Next    *User
+	}
+	fields, _ := Get[User]()
+	leaves := fields.Leaves()
+	assert.Equal(t, []string{"Name", "Tags", "Meta", "Address.City", "Empty", "Next"}, leaves.GetAllPaths())
+	_, ok := leaves.Find("Address")
+	assert.False(t, ok)
+	assert.Same(t, fields.MustFind("Address.City"), leaves.MustFind("Address.City"))
+
+	syncLeaves := Synchronized(fields).Leaves()
+	assert.Equal(t, leaves.GetAllPaths(), syncLeaves.GetAllPaths())
+	assert.IsType(t, &SyncField{}, syncLeaves.MustFind("Name"))
+}
+
+func TestStorage_Filter(t *testing.T) {
+	type Inner struct {
+		Ratio float64
+		Name  string
+		Deep  struct {
+			Weight float64
+		}
+	}
+	type testStruct struct {
+		Price  float64
+		Inner  Inner
+		Inner2 *Inner
+	}
+	fields, _ := Get[testStruct]()
+	deepFloats := fields.Filter(func(f Field) bool {
+		return f.GetType().Kind() == reflect.Float64 && f.GetDepth() > 0
+	})
+	assert.Equal(t, []string{"Inner.Ratio", "Inner.Deep.Weight"}, deepFloats.GetAllPaths())
+	assert.Same(t, fields.MustFind("Inner.Deep.Weight"), deepFloats.MustFind("Inner.Deep.Weight"))
+	_, ok := deepFloats.Find("Price")
+	assert.False(t, ok)
+	assert.Empty(t, fields.Filter(func(f Field) bool { return false }).GetAllPaths())
+	assert.Equal(t, fields.GetAllPaths(), fields.Filter(func(f Field) bool { return true }).GetAllPaths())
+
+	synced := Synchronized(fields).Filter(func(f Field) bool {
+		_, ok := f.(*SyncField)
+		return ok && f.GetName() == "Price"
+	})
+	assert.Equal(t, []string{"Price"}, synced.GetAllPaths())
+	assert.IsType(t, &SyncField{}, synced.MustFind("Price"))
+}
+
+func TestStorage_SubTree(t *testing.T) {
+	type Address struct {
+		City   string
+		Street string
+	}
+	type User struct {
+		Name        string
+		Address     Address
+		AddressLine string
+	}
+	type Root struct {
+		ID   int
+		User User
+	}
+	fields, _ := Get[Root]()
+	obj := &Root{User: User{Address: Address{City: "Paris"}}}
+
+	t.Run("SubTree", func(t *testing.T) {
+		sub := fields.SubTree("User.Address")
+		assert.Equal(t, []string{"User.Address", "User.Address.City", "User.Address.Street"}, sub.GetAllPaths())
+		_, ok := sub.Find("User.AddressLine")
+		assert.False(t, ok)
+		city := sub.MustFind("User.Address.City")
+		assert.Same(t, fields.MustFind("User.Address.City"), city)
+		assert.Equal(t, "Paris", city.Get(obj))
+		assert.Equal(t, []string{"User.Address.City", "User.Address.Street"}, sub.Leaves().GetAllPaths())
+		assert.Empty(t, fields.SubTree("Unknown").GetAllPaths())
+	})
+	t.Run("Rerooted", func(t *testing.T) {
+		sub := fields.SubTreeRerooted("User")
+		assert.Equal(t, []string{"Name", "Address", "Address.City", "Address.Street", "AddressLine"}, sub.GetAllPaths())
+		city := sub.MustFind("Address.City")
+		assert.Equal(t, "User.Address.City", city.GetStructPath())
+		// the fields still take the root object
+		city.Set(obj, "Rome")
+		assert.Equal(t, "Rome", obj.User.Address.City)
+		fld, err := sub.GetFieldByPtr(obj, &obj.User.Address.Street)
+		assert.NoError(t, err)
+		assert.Equal(t, "User.Address.Street", fld.GetStructPath())
+	})
+	t.Run("Wrapped", func(t *testing.T) {
+		sub := Synchronized(fields).SubTreeRerooted("User")
+		assert.IsType(t, &SyncField{}, sub.MustFind("Name"))
+		fld, err := sub.GetFieldByPtr(obj, &obj.User.Name)
+		assert.NoError(t, err)
+		assert.IsType(t, &SyncField{}, fld)
+		assert.Equal(t, "User.Name", fld.GetStructPath())
+		hooked := WithSetHook(fields, func(Field, any, any, any) {}).SubTree("User")
+		assert.IsType(t, &HookField{}, hooked.MustFind("User.Name"))
+	})
+}
+
+func TestStorage_OfType(t *testing.T) {
+	type Timeouts struct {
+		Read  time.Duration
+		Write time.Duration
+	}
+	type Config struct {
+		Timeout  time.Duration
+		Retries  int64
+		Interval *time.Duration
+		Timeouts Timeouts
+	}
+	fields, _ := Get[Config]()
+	paths := func(fields []Field) []string {
+		var paths []string
+		for _, fld := range fields {
+			paths = append(paths, fld.GetStructPath())
+		}
+		return paths
+	}
+	durationType := reflect.TypeOf(time.Duration(0))
+	assert.Equal(t, []string{"Timeout", "Timeouts.Read", "Timeouts.Write"}, paths(fields.OfType(durationType)))
+	assert.Equal(t, []string{"Timeout", "Timeouts.Read", "Timeouts.Write"}, paths(OfTypeG[time.Duration](fields)))
+	// the named types and their underlying types are distinct
+	assert.Equal(t, []string{"Retries"}, paths(OfTypeG[int64](fields)))
+	assert.Equal(t, []string{"Interval"}, paths(OfTypeG[*time.Duration](fields)))
+	assert.Equal(t, []string{"Timeouts"}, paths(OfTypeG[Timeouts](fields)))
+	assert.Empty(t, OfTypeG[string](fields))
+
+	syncFields := OfTypeG[time.Duration](Synchronized(fields))
+	assert.Len(t, syncFields, 3)
+	assert.IsType(t, &SyncField{}, syncFields[0])
+	hookFields := OfTypeG[time.Duration](WithSetHook(fields.SubTreeRerooted("Timeouts"), func(Field, any, any, any) {}))
+	assert.Len(t, hookFields, 2)
+	assert.IsType(t, &HookField{}, hookFields[0])
+}
+
+func TestGetFromType(t *testing.T) {
+	type testStruct struct {
+		Name   string `json:"name"`
+		Nested struct {
+			ID int
+		}
+	}
+	fields, err := GetFromType(reflect.TypeOf(testStruct{}))
+	assert.NoError(t, err)
+	expected, _ := Get[testStruct]()
+	assert.Same(t, expected, fields)
+	ptrFields, err := GetFromType(reflect.TypeOf(&testStruct{}))
+	assert.NoError(t, err)
+	assert.Same(t, expected, ptrFields)
+	assert.Equal(t, []string{"Name", "Nested", "Nested.ID"}, fields.GetAllPaths())
+
+	for _, typeOf := range []reflect.Type{nil, reflect.TypeOf(1), reflect.TypeOf(new(int)), reflect.TypeOf([]testStruct{})} {
+		fields, err = GetFromType(typeOf)
+		assert.Error(t, err)
+		assert.Nil(t, fields)
+	}
+}
+
+func TestGetFromByTag(t *testing.T) {
+	type Address struct {
+		City   string `json:"city"`
+		Street string
+	}
+	type User struct {
+		Name     string  `json:"name,omitempty"`
+		Address  Address `json:"address"`
+		Password string  `json:"-"`
+		Internal Address `json:"-"`
+		Plain    struct {
+			Zip string `json:"zip"`
+		}
+	}
+	t.Run("Tag", func(t *testing.T) {
+		byTag, err := GetFromByTag(&User{}, "json")
+		assert.NoError(t, err)
+		paths := map[string]string{}
+		for key, fld := range byTag {
+			paths[key] = fld.GetStructPath()
+		}
+		assert.Equal(t, map[string]string{
+			"name":         "Name",
+			"address":      "Address",
+			"address.city": "Address.City",
+			"zip":          "Plain.Zip",
+		}, paths)
+		user := &User{}
+		byTag["address.city"].Set(user, "Paris")
+		assert.Equal(t, "Paris", user.Address.City)
+	})
+	t.Run("StructPaths", func(t *testing.T) {
+		byTag, err := GetFromByTag(User{}, "")
+		assert.NoError(t, err)
+		fields, _ := Get[User]()
+		assert.Len(t, byTag, len(fields.GetAllPaths()))
+		assert.Same(t, fields.MustFind("Internal.Street"), byTag["Internal.Street"])
+	})
+	t.Run("Collision", func(t *testing.T) {
+		type Dup struct {
+			A struct {
+				ID int `json:"id"`
+			}
+			B struct {
+				ID int `json:"id"`
+			}
+		}
+		_, err := GetFromByTag(Dup{}, "json")
+		assert.EqualError(t, err, "fmap: field B.ID: tag path id collides with the field A.ID")
+		_, err = GetFromByTag(1, "json")
+		assert.Error(t, err)
+	})
+	t.Run("Options", func(t *testing.T) {
+		type Dup struct {
+			A struct {
+				ID   int    `json:"id"`
+				Name string `json:"name" db:"name"`
+			}
+			B struct {
+				ID   int    `json:"id"`
+				Name string `json:"name" db:"name"`
+			}
+		}
+		_, err := GetFromByTag(Dup{}, "json", WithStrictTags())
+		var collisionErr *TagCollisionError
+		assert.True(t, errors.As(err, &collisionErr))
+		assert.EqualError(t, err, "fmap: colliding tag paths: json tag path id of the fields A.ID, B.ID; "+
+			"json tag path name of the fields A.Name, B.Name")
+		_, err = GetFromByTag(User{}, "json", WithStrictTags("json", "db"))
+		assert.NoError(t, err)
+
+		byTag, err := GetFromByTag(User{}, "json", WithMaxDepth(1), WithCache())
+		assert.NoError(t, err)
+		assert.Len(t, byTag, 2)
+		assert.Contains(t, byTag, "address")
+		delete(byTag, "name")
+		byTag, _ = GetFromByTag(User{}, "json", WithMaxDepth(1), WithCache())
+		assert.Contains(t, byTag, "name")
+	})
+}
@@ -0,0 +1,546 @@
+package fmap
+
+import (
+	"reflect"
+	"sync"
+	"unsafe"
+)
+
+// SyncField is a Field wrapper that serializes access to the field value with a sync.RWMutex.
+// All methods reading the field value in the obj are guarded by the read lock, all methods writing it by the write lock,
+// including the pointer getters, e.g. GetPtr and GetReflectValue, as they allocate the nil pointers on the way.
+// The field metadata methods, e.g. GetName or GetTagPath, are passed to the wrapped Field as is.
+// The wrapped Field isn't embedded, so every Field method is implemented explicitly and a new one can't bypass the lock.
+// Pointers and slices returned by GetPtr, GetUnsafePointer, GetReflectValue, ElementPtr and GetRawBytes are not guarded,
+// the access through them bypasses the lock.
+type SyncField struct {
+	fld Field
+	mu  *sync.RWMutex
+}
+
+var _ Field = (*SyncField)(nil)
+
+// NewSyncField returns the SyncField guarding the fld with the mu.
+// The same mu can be shared between several fields to serialize access to the whole object.
+func NewSyncField(fld Field, mu *sync.RWMutex) *SyncField {
+	return &SyncField{fld: fld, mu: mu}
+}
+
+// Get returns the value of the field in the provided object under the read lock.
+func (f *SyncField) Get(obj any) any {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.fld.Get(obj)
+}
+
+// GetDereferenced returns the dereferenced value of the field in the provided object under the read lock.
+func (f *SyncField) GetDereferenced(obj any) (any, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.fld.GetDereferenced(obj)
+}
+
+// Equal compares the field values in the a and b objects under the read lock.
+func (f *SyncField) Equal(a, b any) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.fld.Equal(a, b)
+}
+
+// IsZero reports whether the field value in the provided object is zero under the read lock.
+func (f *SyncField) IsZero(obj any) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.fld.IsZero(obj)
+}
+
+// Set updates the value of the field in the provided object under the write lock.
+func (f *SyncField) Set(obj any, val any) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.fld.Set(obj, val)
+}
+
+// SetWithHook updates the value of the field in the provided object under the write lock,
+// the hook is called under the lock too.
+func (f *SyncField) SetWithHook(obj any, val any, hook func(old, new any)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.fld.SetWithHook(obj, val, hook)
+}
+
+// SetDefault sets the val to the zero field in the provided object under the write lock,
+// so the check and the update are atomic.
+func (f *SyncField) SetDefault(obj any, val any) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.fld.SetDefault(obj, val)
+}
+
+// GetBit reports whether the bit of the integer field in the provided object is set under the read lock.
+func (f *SyncField) GetBit(obj any, bit int) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.fld.GetBit(obj, bit)
+}
+
+// SetBit sets or clears the bit of the integer field in the provided object under the write lock.
+func (f *SyncField) SetBit(obj any, bit int, v bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.fld.SetBit(obj, bit, v)
+}
+
+// TryGet returns the value of the field in the provided object under the read lock.
+func (f *SyncField) TryGet(obj any) (any, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.fld.TryGet(obj)
+}
+
+// TrySet updates the value of the field in the provided object under the write lock.
+func (f *SyncField) TrySet(obj any, val any) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.fld.TrySet(obj, val)
+}
+
+// GetSliceLen returns the length of the slice field in the provided object under the read lock.
+func (f *SyncField) GetSliceLen(obj any) int {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.fld.GetSliceLen(obj)
+}
+
+// GetSliceIndex returns the i-th element of the slice field in the provided object under the read lock.
+func (f *SyncField) GetSliceIndex(obj any, i int) any {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.fld.GetSliceIndex(obj, i)
+}
+
+// SetSliceIndex sets the i-th element of the slice field in the provided object under the write lock.
+func (f *SyncField) SetSliceIndex(obj any, i int, val any) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.fld.SetSliceIndex(obj, i, val)
+}
+
+// GetMapKey returns the value of the key in the map field in the provided object under the read lock.
+func (f *SyncField) GetMapKey(obj any, key any) (any, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.fld.GetMapKey(obj, key)
+}
+
+// SetMapKey sets the value of the key in the map field in the provided object under the write lock.
+func (f *SyncField) SetMapKey(obj any, key any, val any) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.fld.SetMapKey(obj, key, val)
+}
+
+// SetConvert converts the val and updates the value of the field in the provided object under the write lock.
+func (f *SyncField) SetConvert(obj any, val any) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.fld.SetConvert(obj, val)
+}
+
+// SetReflectValue assigns the v to the field in the provided object under the write lock.
+func (f *SyncField) SetReflectValue(obj any, v reflect.Value) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.fld.SetReflectValue(obj, v)
+}
+
+// TrySetReflectValue assigns the v to the field in the provided object under the write lock.
+func (f *SyncField) TrySetReflectValue(obj any, v reflect.Value) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.fld.TrySetReflectValue(obj, v)
+}
+
+// SetReflectValueConvert converts the v and assigns it to the field in the provided object under the write lock.
+func (f *SyncField) SetReflectValueConvert(obj any, v reflect.Value) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.fld.SetReflectValueConvert(obj, v)
+}
+
+// GetBytes returns the []byte field in the provided object under the read lock.
+func (f *SyncField) GetBytes(obj any) []byte {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.fld.GetBytes(obj)
+}
+
+// SetBytes sets the b to the []byte field in the provided object under the write lock.
+func (f *SyncField) SetBytes(obj any, b []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.fld.SetBytes(obj, b)
+}
+
+// SetFromJSON decodes the data into the field in the provided object under the write lock.
+func (f *SyncField) SetFromJSON(obj any, data []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.fld.SetFromJSON(obj, data)
+}
+
+// GetAtomicValue loads the value of the sync/atomic wrapper field in the provided object under the read lock.
+func (f *SyncField) GetAtomicValue(obj any) any {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.fld.GetAtomicValue(obj)
+}
+
+// SetAtomicValue stores the val to the sync/atomic wrapper field in the provided object under the write lock.
+func (f *SyncField) SetAtomicValue(obj any, val any) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.fld.SetAtomicValue(obj, val)
+}
+
+// SetSliceLen resizes the slice field in the provided object under the write lock.
+func (f *SyncField) SetSliceLen(obj any, n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.fld.SetSliceLen(obj, n)
+}
+
+// GetByIndex returns the value of the field by its index path in the provided object under the read lock.
+func (f *SyncField) GetByIndex(obj any) any {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.fld.GetByIndex(obj)
+}
+
+// GetAsString returns the value of the field formatted as the string in the provided object under the read lock.
+func (f *SyncField) GetAsString(obj any) string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.fld.GetAsString(obj)
+}
+
+// GetArrayIndex returns the i-th element of the array field in the provided object under the read lock.
+func (f *SyncField) GetArrayIndex(obj any, i int) any {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.fld.GetArrayIndex(obj, i)
+}
+
+// TryGetArrayIndex returns the i-th element of the array field in the provided object under the read lock.
+func (f *SyncField) TryGetArrayIndex(obj any, i int) (any, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.fld.TryGetArrayIndex(obj, i)
+}
+
+// GetAtomic loads the value of the integer field atomically in the provided object under the read lock.
+func (f *SyncField) GetAtomic(obj any) any {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.fld.GetAtomic(obj)
+}
+
+// GetRaw returns the raw memory of the field copy in the provided object under the read lock.
+func (f *SyncField) GetRaw(obj any) ([]byte, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.fld.GetRaw(obj)
+}
+
+// GetRawBytes returns the raw memory of the field in the provided object under the read lock.
+// The returned slice aliases the field memory, the access through it bypasses the lock.
+func (f *SyncField) GetRawBytes(obj any) []byte {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.fld.GetRawBytes(obj)
+}
+
+// CopyRawBytes returns the copy of the raw memory of the field in the provided object under the read lock.
+func (f *SyncField) CopyRawBytes(obj any) []byte {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.fld.CopyRawBytes(obj)
+}
+
+// GetPtr returns the pointer to the field in the provided object under the write lock.
+// The nil pointers on the way are allocated, the writes through the returned pointer bypass the lock.
+func (f *SyncField) GetPtr(obj any) any {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.fld.GetPtr(obj)
+}
+
+// GetUnsafePointer returns the unsafe.Pointer to the field in the provided object under the write lock.
+// The nil pointers on the way are allocated, the writes through the returned pointer bypass the lock.
+func (f *SyncField) GetUnsafePointer(obj any) unsafe.Pointer {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.fld.GetUnsafePointer(obj)
+}
+
+// GetReflectValue returns the addressable reflect.Value of the field in the provided object under the write lock.
+// The nil pointers on the way are allocated, the writes through the returned value bypass the lock.
+func (f *SyncField) GetReflectValue(obj any) reflect.Value {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.fld.GetReflectValue(obj)
+}
+
+// ElementPtr returns the pointer to the i-th element of the slice or array field in the provided object under the write lock.
+// The writes through the returned pointer bypass the lock.
+func (f *SyncField) ElementPtr(obj any, i int) any {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.fld.ElementPtr(obj, i)
+}
+
+// EnsureNonNil allocates the nil pointer field in the provided object under the write lock.
+func (f *SyncField) EnsureNonNil(obj any) any {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.fld.EnsureNonNil(obj)
+}
+
+// SetFromString parses the s into the field in the provided object under the write lock.
+func (f *SyncField) SetFromString(obj any, s string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.fld.SetFromString(obj, s)
+}
+
+// SetArrayIndex sets the i-th element of the array field in the provided object under the write lock.
+func (f *SyncField) SetArrayIndex(obj any, i int, val any) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.fld.SetArrayIndex(obj, i, val)
+}
+
+// TrySetArrayIndex sets the i-th element of the array field in the provided object under the write lock.
+func (f *SyncField) TrySetArrayIndex(obj any, i int, val any) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.fld.TrySetArrayIndex(obj, i, val)
+}
+
+// SetAtomic stores the val to the integer field atomically in the provided object under the write lock.
+func (f *SyncField) SetAtomic(obj any, val any) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.fld.SetAtomic(obj, val)
+}
+
+// AddAtomic adds the delta to the integer field atomically in the provided object under the write lock.
+func (f *SyncField) AddAtomic(obj any, delta int64) int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.fld.AddAtomic(obj, delta)
+}
+
+// SetRaw copies the raw memory to the field in the provided object under the write lock.
+func (f *SyncField) SetRaw(obj any, raw []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.fld.SetRaw(obj, raw)
+}
+
+// checkSet checks the update of the wrapped field, see SetAll.
+func (f *SyncField) checkSet(obj any, val any) error {
+	return checkSet(f.fld, obj, val)
+}
+
+// Clone returns the SyncField guarding the clone of the wrapped field with the same mutex.
+func (f *SyncField) Clone() Field {
+	return NewSyncField(f.fld.Clone(), f.mu)
+}
+
+// GetName returns the name of the wrapped field.
+func (f *SyncField) GetName() string {
+	return f.fld.GetName()
+}
+
+// GetPkgPath returns the package path of the wrapped field.
+func (f *SyncField) GetPkgPath() string {
+	return f.fld.GetPkgPath()
+}
+
+// GetType returns the type of the wrapped field.
+func (f *SyncField) GetType() reflect.Type {
+	return f.fld.GetType()
+}
+
+// GetKind returns the kind of the wrapped field.
+func (f *SyncField) GetKind() reflect.Kind {
+	return f.fld.GetKind()
+}
+
+// IsPointer reports whether the wrapped field is a pointer.
+func (f *SyncField) IsPointer() bool {
+	return f.fld.IsPointer()
+}
+
+// ElemKind returns the element kind of the wrapped field.
+func (f *SyncField) ElemKind() reflect.Kind {
+	return f.fld.ElemKind()
+}
+
+// Implements reports whether the type of the wrapped field implements the iface.
+func (f *SyncField) Implements(iface reflect.Type) bool {
+	return f.fld.Implements(iface)
+}
+
+// PtrImplements reports whether the pointer to the type of the wrapped field implements the iface.
+func (f *SyncField) PtrImplements(iface reflect.Type) bool {
+	return f.fld.PtrImplements(iface)
+}
+
+// GetTag returns the tag of the wrapped field.
+func (f *SyncField) GetTag() reflect.StructTag {
+	return f.fld.GetTag()
+}
+
+// GetOffset returns the offset of the wrapped field.
+func (f *SyncField) GetOffset() uintptr {
+	return f.fld.GetOffset()
+}
+
+// GetSize returns the size of the wrapped field.
+func (f *SyncField) GetSize() uintptr {
+	return f.fld.GetSize()
+}
+
+// GetEnd returns the end offset of the wrapped field.
+func (f *SyncField) GetEnd() uintptr {
+	return f.fld.GetEnd()
+}
+
+// GetAlign returns the alignment of the wrapped field.
+func (f *SyncField) GetAlign() uintptr {
+	return f.fld.GetAlign()
+}
+
+// GetFieldAlign returns the alignment of the wrapped field as a struct field.
+func (f *SyncField) GetFieldAlign() uintptr {
+	return f.fld.GetFieldAlign()
+}
+
+// GetIndex returns the index sequence of the wrapped field.
+func (f *SyncField) GetIndex() []int {
+	return f.fld.GetIndex()
+}
+
+// GetAnonymous reports whether the wrapped field is embedded.
+func (f *SyncField) GetAnonymous() bool {
+	return f.fld.GetAnonymous()
+}
+
+// IsPromoted reports whether the wrapped field is promoted.
+func (f *SyncField) IsPromoted() bool {
+	return f.fld.IsPromoted()
+}
+
+// IsExported reports whether the wrapped field is exported.
+func (f *SyncField) IsExported() bool {
+	return f.fld.IsExported()
+}
+
+// GetStructPath returns the struct path of the wrapped field.
+func (f *SyncField) GetStructPath() string {
+	return f.fld.GetStructPath()
+}
+
+// GetTagPath returns the tag path of the wrapped field.
+func (f *SyncField) GetTagPath(tag string, ignoreParentTagMissing bool) string {
+	return f.fld.GetTagPath(tag, ignoreParentTagMissing)
+}
+
+// GetTagPathWithSep returns the tag path of the wrapped field joined with the sep.
+func (f *SyncField) GetTagPathWithSep(tag, sep string, ignoreParentTagMissing bool) string {
+	return f.fld.GetTagPathWithSep(tag, sep, ignoreParentTagMissing)
+}
+
+// GetTagPathFunc returns the tag path of the wrapped field transformed by the transform func.
+func (f *SyncField) GetTagPathFunc(tag string, transform func(string) string, sep string, ignoreParentTagMissing bool) string {
+	return f.fld.GetTagPathFunc(tag, transform, sep, ignoreParentTagMissing)
+}
+
+// IsTagSkipped reports whether the wrapped field is skipped by the tag.
+func (f *SyncField) IsTagSkipped(tag string) bool {
+	return f.fld.IsTagSkipped(tag)
+}
+
+// GetTagOr returns the tag value of the wrapped field or the def.
+func (f *SyncField) GetTagOr(tag, def string) string {
+	return f.fld.GetTagOr(tag, def)
+}
+
+// GetTagOptions returns the tag options of the wrapped field.
+func (f *SyncField) GetTagOptions(tag string) []string {
+	return f.fld.GetTagOptions(tag)
+}
+
+// HasTagOption reports whether the tag of the wrapped field has the option.
+func (f *SyncField) HasTagOption(tag, option string) bool {
+	return f.fld.HasTagOption(tag, option)
+}
+
+// String returns the description of the wrapped field.
+func (f *SyncField) String() string {
+	return f.fld.String()
+}
+
+// GetSchema returns the schema of the wrapped field.
+func (f *SyncField) GetSchema() FieldSchema {
+	return f.fld.GetSchema()
+}
+
+// GetDepth returns the depth of the wrapped field.
+func (f *SyncField) GetDepth() int {
+	return f.fld.GetDepth()
+}
+
+// GetStruct returns the struct type the wrapped field belongs to.
+func (f *SyncField) GetStruct() reflect.Type {
+	return f.fld.GetStruct()
+}
+
+// GetParent returns the parent of the wrapped field.
+func (f *SyncField) GetParent() Field {
+	return f.fld.GetParent()
+}
+
+// GetDereferencedType returns the dereferenced type of the wrapped field.
+func (f *SyncField) GetDereferencedType() reflect.Type {
+	return f.fld.GetDereferencedType()
+}
+
+// ElementStorage returns the Storage of the wrapped field elements.
+func (f *SyncField) ElementStorage() (Storage, error) {
+	return f.fld.ElementStorage()
+}
+
+// HasPointers reports whether the wrapped field type contains pointers.
+func (f *SyncField) HasPointers() bool {
+	return f.fld.HasPointers()
+}
+
+// SetLocked updates the value of the fld in the provided object while holding the mu.
+func SetLocked(fld Field, obj any, val any, mu *sync.Mutex) {
+	mu.Lock()
+	defer mu.Unlock()
+	fld.Set(obj, val)
+}
+
+// Synchronized returns the Storage which fields are SyncField guarded by one shared sync.RWMutex.
+// It's useful when the same object is updated from several goroutines through the storage fields.
+// The wrapped Storage is not modified, so the cached Storage can still be used without locking.
+func Synchronized(s Storage) Storage {
+	mu := &sync.RWMutex{}
+	return wrapStorage(s, func(fld Field) Field {
+		return NewSyncField(fld, mu)
+	})
+}
@@ -0,0 +1,157 @@
+package fmap
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSynchronized(t *testing.T) {
+	type Counter struct {
+		Name  string
+		Count int
+	}
+	fields, _ := Get[Counter]()
+	syncFields := Synchronized(fields)
+
+	t.Run("ConcurrentSet", func(t *testing.T) {
+		c := &Counter{}
+		fld := syncFields.MustFind("Count")
+		wg := sync.WaitGroup{}
+		for i := 0; i < 100; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				fld.Set(c, i)
+				_ = fld.Get(c)
+			}(i)
+		}
+		wg.Wait()
+		assert.GreaterOrEqual(t, c.Count, 0)
+	})
+	t.Run("Find", func(t *testing.T) {
+		fld, ok := syncFields.Find("Name")
+		assert.True(t, ok)
+		assert.IsType(t, &SyncField{}, fld)
+		_, ok = syncFields.Find("Unknown")
+		assert.False(t, ok)
+		assert.Equal(t, fields.GetAllPaths(), syncFields.GetAllPaths())
+	})
+	t.Run("GetFieldByPtr", func(t *testing.T) {
+		c := &Counter{}
+		fld, err := syncFields.GetFieldByPtr(c, &c.Name)
+		assert.NoError(t, err)
+		assert.IsType(t, &SyncField{}, fld)
+		assert.Equal(t, "Name", fld.GetStructPath())
+		_, err = syncFields.GetFieldByPtr(c, new(float64))
+		assert.Error(t, err)
+	})
+	t.Run("GetDereferenced", func(t *testing.T) {
+		c := &Counter{Name: "test"}
+		val, ok := syncFields.MustFind("Name").GetDereferenced(c)
+		assert.True(t, ok)
+		assert.Equal(t, "test", val)
+	})
+}
+
+func TestSyncField_Guarded(t *testing.T) {
+	type testStruct struct {
+		Names []string
+		Tags  map[string]int
+		Count int
+		Data  []byte
+		Value atomic.Value
+		Total int64
+		Arr   [2]int
+		Ptr   *int
+		Items [1]struct{ X int }
+	}
+	fields, _ := Get[testStruct]()
+	mu := &sync.RWMutex{}
+	guarded := func(path string) Field {
+		return NewSyncField(fields.MustFind(path), mu)
+	}
+	obj := &testStruct{Names: []string{"a"}}
+	for _, tc := range []struct {
+		name  string
+		write bool
+		call  func()
+	}{
+		{"GetSliceLen", false, func() { guarded("Names").GetSliceLen(obj) }},
+		{"GetSliceIndex", false, func() { guarded("Names").GetSliceIndex(obj, 0) }},
+		{"SetSliceIndex", true, func() { guarded("Names").SetSliceIndex(obj, 0, "b") }},
+		{"GetMapKey", false, func() { guarded("Tags").GetMapKey(obj, "a") }},
+		{"SetMapKey", true, func() { guarded("Tags").SetMapKey(obj, "a", 2) }},
+		{"SetConvert", true, func() { _ = guarded("Count").SetConvert(obj, 1.0) }},
+		{"SetReflectValue", true, func() { guarded("Count").SetReflectValue(obj, reflect.ValueOf(1)) }},
+		{"TrySetReflectValue", true, func() { _ = guarded("Count").TrySetReflectValue(obj, reflect.ValueOf(1)) }},
+		{"SetReflectValueConvert", true, func() { _ = guarded("Count").SetReflectValueConvert(obj, reflect.ValueOf(1.0)) }},
+		{"GetBytes", false, func() { guarded("Data").GetBytes(obj) }},
+		{"SetBytes", true, func() { guarded("Data").SetBytes(obj, []byte("a")) }},
+		{"SetFromJSON", true, func() { _ = guarded("Count").SetFromJSON(obj, []byte("1")) }},
+		{"GetAtomicValue", false, func() { guarded("Value").GetAtomicValue(obj) }},
+		{"SetAtomicValue", true, func() { guarded("Value").SetAtomicValue(obj, 1) }},
+		{"SetSliceLen", true, func() { guarded("Names").SetSliceLen(obj, 1) }},
+		{"GetByIndex", false, func() { guarded("Count").GetByIndex(obj) }},
+		{"GetAsString", false, func() { guarded("Count").GetAsString(obj) }},
+		{"GetArrayIndex", false, func() { guarded("Arr").GetArrayIndex(obj, 0) }},
+		{"TryGetArrayIndex", false, func() { _, _ = guarded("Arr").TryGetArrayIndex(obj, 0) }},
+		{"GetAtomic", false, func() { guarded("Total").GetAtomic(obj) }},
+		{"GetRaw", false, func() { _, _ = guarded("Count").GetRaw(obj) }},
+		{"GetRawBytes", false, func() { guarded("Count").GetRawBytes(obj) }},
+		{"CopyRawBytes", false, func() { guarded("Count").CopyRawBytes(obj) }},
+		{"GetPtr", true, func() { guarded("Count").GetPtr(obj) }},
+		{"GetUnsafePointer", true, func() { guarded("Count").GetUnsafePointer(obj) }},
+		{"GetReflectValue", true, func() { guarded("Count").GetReflectValue(obj) }},
+		{"ElementPtr", true, func() { guarded("Items").ElementPtr(obj, 0) }},
+		{"EnsureNonNil", true, func() { guarded("Ptr").EnsureNonNil(obj) }},
+		{"SetFromString", true, func() { _ = guarded("Count").SetFromString(obj, "1") }},
+		{"SetArrayIndex", true, func() { guarded("Arr").SetArrayIndex(obj, 0, 1) }},
+		{"TrySetArrayIndex", true, func() { _ = guarded("Arr").TrySetArrayIndex(obj, 0, 1) }},
+		{"SetAtomic", true, func() { guarded("Total").SetAtomic(obj, int64(1)) }},
+		{"AddAtomic", true, func() { guarded("Total").AddAtomic(obj, 1) }},
+		{"SetRaw", true, func() { _ = guarded("Count").SetRaw(obj, make([]byte, 8)) }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assertGuarded(t, mu, tc.write, tc.call)
+		})
+	}
+}
+
+// assertGuarded checks that the call waits for the mu held by the test, the write call waits even for the read lock.
+func assertGuarded(t *testing.T, mu *sync.RWMutex, write bool, call func()) {
+	unlock := mu.Unlock
+	if write {
+		mu.RLock()
+		unlock = mu.RUnlock
+	} else {
+		mu.Lock()
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		call()
+	}()
+	select {
+	case <-done:
+		t.Error("the call isn't guarded by the mutex")
+	case <-time.After(10 * time.Millisecond):
+	}
+	unlock()
+	<-done
+}
+
+func TestSetLocked(t *testing.T) {
+	type Counter struct {
+		Count int
+	}
+	fields, _ := Get[Counter]()
+	c := &Counter{}
+	mu := &sync.Mutex{}
+	SetLocked(fields.MustFind("Count"), c, 5, mu)
+	assert.Equal(t, 5, c.Count)
+}
@@ -0,0 +1,147 @@
+package fmap
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// tagIndexes lazily builds and caches the tag path indexes of the Storage by the tag, see Storage.TagIndex,
+// and the collisions of the tag paths.
+type tagIndexes struct {
+	indexes    sync.Map
+	collisions sync.Map
+}
+
+// tagCollisions are the collisions of the tag paths of the storage fields.
+type tagCollisions struct {
+	// keys maps the tag paths of the tagIndex shared by several fields to the collision error, see ApplyTagMap.
+	keys map[string]error
+	// leaves is the first collision of the leaf field tag paths in the definition order, including the path
+	// nested in the other one, e.g. "a.b" in "a", which can't be stored in the same nested map, see ToMap.
+	leaves error
+}
+
+// get returns the cached index of the tag, building it with the build func on the first call.
+func (c *tagIndexes) get(tag string, build func() map[string]Field) map[string]Field {
+	if index, ok := c.indexes.Load(tag); ok {
+		return index.(map[string]Field)
+	}
+	index, _ := c.indexes.LoadOrStore(tag, build())
+	return index.(map[string]Field)
+}
+
+// getCollisions returns the cached collisions of the tag, building them with the build func on the first call.
+func (c *tagIndexes) getCollisions(tag string, build func() *tagCollisions) *tagCollisions {
+	if collisions, ok := c.collisions.Load(tag); ok {
+		return collisions.(*tagCollisions)
+	}
+	collisions, _ := c.collisions.LoadOrStore(tag, build())
+	return collisions.(*tagCollisions)
+}
+
+// TagIndex returns the copy of the cached index, so the caller can modify it.
+func (s *storage) TagIndex(tag string) map[string]Field {
+	return copyTagIndex(s.tagIndex(tag))
+}
+
+func (s *storage) ByTagPath(tag, tagPath string) (Field, bool) {
+	fld, ok := s.tagIndex(tag)[tagPath]
+	return fld, ok
+}
+
+// tagIndex returns the cached index of the tag, the first field in the definition order is kept on the collision.
+func (s *storage) tagIndex(tag string) map[string]Field {
+	return s.tagIndexes.get(tag, func() map[string]Field {
+		index := make(map[string]Field, len(s.paths))
+		for _, path := range s.paths {
+			fld := s.asMap[path].(*field)
+			key := tagKey(fld, tag)
+			if _, ok := index[key]; key == "" || ok {
+				continue
+			}
+			index[key] = fld
+		}
+		return index
+	})
+}
+
+// tagCollisions returns the cached collisions of the tag paths of the tag.
+func (s *storage) tagCollisions(tag string) *tagCollisions {
+	return s.tagIndexes.getCollisions(tag, func() *tagCollisions {
+		collisions := &tagCollisions{keys: map[string]error{}}
+		index := s.tagIndex(tag)
+		for _, path := range s.paths {
+			key := tagKey(s.asMap[path].(*field), tag)
+			if other, ok := index[key]; ok && other != s.asMap[path] {
+				if _, collided := collisions.keys[key]; !collided {
+					collisions.keys[key] = fmt.Errorf("fmap: field %s: tag path %s collides with the field %s", path, key, other.GetStructPath())
+				}
+			}
+		}
+		leaves := make(map[string]*field, len(s.paths))
+		var leafPaths []string
+		for _, path := range s.paths {
+			fld := s.asMap[path].(*field)
+			tagPath := fld.GetTagPath(tag, true)
+			if fld.hasChildren || tagPath == "" {
+				continue
+			}
+			if other, ok := leaves[tagPath]; ok {
+				if collisions.leaves == nil {
+					collisions.leaves = fmt.Errorf("fmap: field %s: tag path %s collides with the field %s", path, tagPath, other.structPath)
+				}
+				continue
+			}
+			leaves[tagPath] = fld
+			leafPaths = append(leafPaths, tagPath)
+		}
+		for _, tagPath := range leafPaths {
+			if collisions.leaves != nil {
+				break
+			}
+			for i := strings.LastIndexByte(tagPath, '.'); i > 0; i = strings.LastIndexByte(tagPath[:i], '.') {
+				if other, ok := leaves[tagPath[:i]]; ok {
+					collisions.leaves = fmt.Errorf("fmap: field %s: tag path %s is nested in the tag path of the field %s",
+						leaves[tagPath].structPath, tagPath, other.structPath)
+					break
+				}
+			}
+		}
+		return collisions
+	})
+}
+
+// copyTagIndex returns the shallow copy of the index.
+func copyTagIndex(index map[string]Field) map[string]Field {
+	indexCopy := make(map[string]Field, len(index))
+	for key, fld := range index {
+		indexCopy[key] = fld
+	}
+	return indexCopy
+}
+
+// wrapTagIndex returns the copy of the index with the fields replaced by the wrapped ones.
+func wrapTagIndex(index map[string]Field, wrapped map[Field]Field) map[string]Field {
+	wrappedIndex := make(map[string]Field, len(index))
+	for key, fld := range index {
+		wrappedIndex[key] = wrapped[fld]
+	}
+	return wrappedIndex
+}
+
+// TagIndex returns the index of the wrapped fields.
+func (s *wrappedStorage) TagIndex(tag string) map[string]Field {
+	return copyTagIndex(s.tagIndex(tag))
+}
+
+func (s *wrappedStorage) ByTagPath(tag, tagPath string) (Field, bool) {
+	fld, ok := s.tagIndex(tag)[tagPath]
+	return fld, ok
+}
+
+func (s *wrappedStorage) tagIndex(tag string) map[string]Field {
+	return s.tagIndexes.get(tag, func() map[string]Field {
+		return wrapTagIndex(s.Storage.TagIndex(tag), s.wrapped)
+	})
+}
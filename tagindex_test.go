@@ -0,0 +1,75 @@
+package fmap
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStorage_TagIndex(t *testing.T) {
+	type Address struct {
+		City string `json:"city"`
+	}
+	type User struct {
+		Name     string  `json:"name,omitempty"`
+		Address  Address `json:"address"`
+		Password string  `json:"-"`
+		Alias    string  `kv:"alias"`
+		Nick     string  `kv:"alias"`
+		Plain    string
+	}
+	fields, _ := Get[User]()
+	index := fields.TagIndex("json")
+	assert.Equal(t, map[string]Field{
+		"name":         fields.MustFind("Name"),
+		"address":      fields.MustFind("Address"),
+		"address.city": fields.MustFind("Address.City"),
+	}, index)
+	// the returned index is the copy of the cached one
+	index["copied"] = nil
+	delete(index, "name")
+	assert.NotContains(t, fields.TagIndex("json"), "copied")
+	_, ok := fields.ByTagPath("json", "name")
+	assert.True(t, ok)
+	synced := Synchronized(fields)
+	delete(synced.TagIndex("json"), "name")
+	assert.Contains(t, synced.TagIndex("json"), "name")
+
+	fld, ok := fields.ByTagPath("json", "address.city")
+	assert.True(t, ok)
+	assert.Same(t, fields.MustFind("Address.City"), fld)
+	_, ok = fields.ByTagPath("json", "Password")
+	assert.False(t, ok)
+	assert.Len(t, fields.TagIndex(""), len(fields.GetAllPaths()))
+	assert.Empty(t, fields.TagIndex("yaml"))
+	// the first colliding field is kept
+	assert.Equal(t, map[string]Field{"alias": fields.MustFind("Alias")}, fields.TagIndex("kv"))
+
+	t.Run("Wrapped", func(t *testing.T) {
+		synced := Synchronized(fields)
+		fld, ok := synced.ByTagPath("json", "name")
+		assert.True(t, ok)
+		assert.Same(t, synced.MustFind("Name"), fld)
+
+		hooked := WithSetHook(fields, func(Field, any, any, any) {})
+		fld, ok = hooked.ByTagPath("json", "address.city")
+		assert.True(t, ok)
+		assert.IsType(t, &HookField{}, fld)
+		assert.Same(t, hooked.MustFind("Address.City"), fld)
+	})
+	t.Run("Concurrent", func(t *testing.T) {
+		sub := fields.SubTree("Address")
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				fld, ok := sub.ByTagPath("json", "address.city")
+				assert.True(t, ok)
+				assert.Equal(t, "City", fld.GetName())
+			}()
+		}
+		wg.Wait()
+	})
+}
@@ -0,0 +1,252 @@
+package fmap
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ToMap converts the object pointed to by obj to the nested map keyed by the field tag path segments,
+// e.g. the field with the `user.address.city` tag path is stored as m["user"]["address"]["city"].
+// Only the leaf fields, i.e. fields without nested fields, are converted. The fields without the tag are skipped,
+// the missing parent tags are ignored.
+// The fields with the omitempty tag option are skipped if their values are empty like encoding/json defines it:
+// false, 0, "", nil pointers and interfaces, empty arrays, slices and maps. Unlike Field.IsZero the empty non-nil
+// slices and maps are omitted too, while the zero structs, e.g. time.Time, are kept.
+// It returns an error if the tag paths of two fields are the same or one is nested in the other, e.g. "a" and "a.b",
+// as they can't be stored in the nested map, regardless of the values.
+func ToMap(obj any, tag string) (map[string]any, error) {
+	m := map[string]any{}
+	if err := ToMapInto(obj, tag, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ToMapInto is the ToMap variant that writes into the caller-provided non-nil dst map instead of the new one,
+// e.g. to reuse the map across the calls for the same struct. The nested maps already present in the dst
+// are reused and written into too. The caller owns clearing the dst: the keys already present in it
+// are overwritten or left as is, e.g. the key of the omitempty field that became empty isn't removed.
+func ToMapInto(obj any, tag string, dst map[string]any) error {
+	fields, err := getFromPtr(obj)
+	if err != nil {
+		return err
+	}
+	if dst == nil {
+		return fmt.Errorf("fmap: to map: nil dst map")
+	}
+	if err = fields.tagCollisions(tag).leaves; err != nil {
+		return err
+	}
+	for _, path := range fields.paths {
+		fld := fields.asMap[path].(*field)
+		if fld.hasChildren {
+			continue
+		}
+		tagPath := fld.GetTagPath(tag, true)
+		if tagPath == "" {
+			continue
+		}
+		if val, ok := tagValue(fld, obj, tag); ok {
+			setMapPath(dst, tagPath, val)
+		}
+	}
+	return nil
+}
+
+// UnknownKeysError is returned by FromMap when some data keys don't match any field tag path.
+// All known keys are applied anyway, so the error can be ignored with errors.As if the unknown keys are expected.
+type UnknownKeysError struct {
+	Keys []string
+}
+
+func (e *UnknownKeysError) Error() string {
+	return "unknown keys: " + strings.Join(e.Keys, ", ")
+}
+
+// FromMap populates the object pointed to by obj from the data keyed by the field tag paths, the inverse of ToMap.
+// The nested maps are matched against the nested struct fields, e.g. data["address"]["city"] is set to the field
+// with the `address.city` tag path, unless the `address` field is a leaf, then the whole nested map is set to it.
+// The values are converted to the field types like Field.SetConvert does. All values are converted in the sorted
+// keys order before any write, so on the conversion error the object is not modified and the first failed key
+// in that order is reported. The keys without the matching field are returned as the *UnknownKeysError,
+// the matching ones are set then.
+func FromMap(obj any, tag string, data map[string]any) error {
+	fields, err := getFromPtr(obj)
+	if err != nil {
+		return err
+	}
+	index := make(map[string]*field, len(fields.paths))
+	for _, path := range fields.paths {
+		fld := fields.asMap[path].(*field)
+		if tagPath := fld.GetTagPath(tag, true); tagPath != "" {
+			index[tagPath] = fld
+		}
+	}
+	var updates []mapUpdate
+	var unknown []string
+	if err = fromMap(obj, index, "", data, &updates, &unknown); err != nil {
+		return err
+	}
+	for _, u := range updates {
+		reflect.NewAt(u.fld.Type, u.fld.getPtr(obj)).Elem().Set(u.val)
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return &UnknownKeysError{Keys: unknown}
+	}
+	return nil
+}
+
+// mapUpdate is the converted value of the FromMap data to set to the field.
+type mapUpdate struct {
+	fld *field
+	val reflect.Value
+}
+
+// fromMap collects the converted values of the data in the sorted keys order into the updates
+// and the keys without the matching field into the unknown ones.
+func fromMap(obj any, index map[string]*field, prefix string, data map[string]any, updates *[]mapUpdate, unknown *[]string) error {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		val := data[key]
+		tagPath := prefix + key
+		fld, ok := index[tagPath]
+		if nested, isMap := val.(map[string]any); isMap && !(ok && !fld.hasChildren) {
+			if err := fromMap(obj, index, tagPath+".", nested, updates, unknown); err != nil {
+				return err
+			}
+			continue
+		}
+		if !ok {
+			*unknown = append(*unknown, tagPath)
+			continue
+		}
+		if err := fld.checkObj(obj); err != nil {
+			return err
+		}
+		if err := fld.checkWritable(); err != nil {
+			return err
+		}
+		converted, err := convertValue(reflect.ValueOf(val), fld.Type)
+		if err != nil {
+			return fmt.Errorf("fmap: field %s: %w", fld.structPath, err)
+		}
+		*updates = append(*updates, mapUpdate{fld: fld, val: converted})
+	}
+	return nil
+}
+
+// setMapPath stores the val in the nested map by the dot separated path.
+func setMapPath(m map[string]any, path string, val any) {
+	for {
+		key, rest, found := strings.Cut(path, ".")
+		if !found {
+			m[key] = val
+			return
+		}
+		nested, ok := m[key].(map[string]any)
+		if !ok {
+			nested = map[string]any{}
+			m[key] = nested
+		}
+		m, path = nested, rest
+	}
+}
+
+// KeyError is the per-key error returned by ApplyTagMap.
+type KeyError struct {
+	Key string
+	Err error
+}
+
+func (e *KeyError) Error() string {
+	return "key " + e.Key + ": " + e.Err.Error()
+}
+
+func (e *KeyError) Unwrap() error {
+	return e.Err
+}
+
+// ApplyTagMap sets the already typed values to the fields of the object pointed to by obj matched by the flat
+// tag paths, e.g. the `address.city` key for the City field of the Address struct, see GetFromByTag.
+// The values are converted to the field types like Field.SetConvert does. Unlike FromMap, every key is applied
+// independently, so the failed keys don't stop the others, and the *KeyError is returned for every failed,
+// unknown or colliding key, ordered by the keys. The colliding tag paths of the keys not in the values are ignored.
+// The single error without the key is returned if the obj isn't supported.
+func ApplyTagMap(obj any, tag string, values map[string]any) []error {
+	fields, err := getFromPtr(obj)
+	if err != nil {
+		return []error{err}
+	}
+	index := fields.tagIndex(tag)
+	collisions := fields.tagCollisions(tag).keys
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var errs []error
+	for _, key := range keys {
+		if err = collisions[key]; err != nil {
+			errs = append(errs, &KeyError{Key: key, Err: err})
+			continue
+		}
+		fld, ok := index[key]
+		if !ok {
+			errs = append(errs, &KeyError{Key: key, Err: fmt.Errorf("unknown tag path")})
+			continue
+		}
+		if err = fld.SetConvert(obj, values[key]); err != nil {
+			errs = append(errs, &KeyError{Key: key, Err: err})
+		}
+	}
+	return errs
+}
+
+// Values returns the snapshot of the current values of all fields of the struct or ptr to struct obj keyed by
+// their struct paths, including the nested struct fields and their parents, e.g. to log the full state of the config.
+// Unlike ToMap, it's flat, doesn't depend on the tags and includes the values of all kinds read like Field.Get does.
+// It never panics: it returns nil for the unsupported obj and the nil pointer, and skips the fields
+// which values can't be read, the fields behind the nil embedded struct pointers are zero values.
+func Values(obj any) map[string]any {
+	typeOf, err := checkStructType(reflect.TypeOf(obj))
+	if err != nil {
+		return nil
+	}
+	valOf := reflect.ValueOf(obj)
+	if valOf.Kind() == reflect.Struct {
+		// the struct value is copied to be read by the offset
+		ptr := reflect.New(valOf.Type())
+		ptr.Elem().Set(valOf)
+		obj = ptr.Interface()
+	} else if valOf.IsNil() {
+		return nil
+	}
+	fields, err := getFrom(typeOf)
+	if err != nil {
+		return nil
+	}
+	values := make(map[string]any, len(fields.paths))
+	for _, path := range fields.paths {
+		if val, ok := tryGetValue(fields.asMap[path], obj); ok {
+			values[path] = val
+		}
+	}
+	return values
+}
+
+// tryGetValue returns the value of the fld in the obj, the ok is false if the Get panics.
+func tryGetValue(fld Field, obj any) (val any, ok bool) {
+	defer func() {
+		if recover() != nil {
+			val, ok = nil, false
+		}
+	}()
+	return fld.Get(obj), true
+}
@@ -0,0 +1,358 @@
+package fmap
+
+import (
+	"encoding/json"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mapAddress struct {
+	City   string `json:"city"`
+	Street string `json:"street"`
+}
+
+type mapUser struct {
+	Name    string   `json:"name"`
+	Age     *int     `json:"age"`
+	Tags    []string `json:"tags"`
+	Secret  string
+	Address mapAddress `json:"address"`
+	Plain   struct {
+		Zip string `json:"zip"`
+	}
+}
+
+func TestToMap(t *testing.T) {
+	age := 30
+	user := &mapUser{
+		Name:    "John",
+		Age:     &age,
+		Tags:    []string{"a"},
+		Secret:  "secret",
+		Address: mapAddress{City: "Paris", Street: "Main"},
+	}
+	user.Plain.Zip = "123"
+
+	m, err := ToMap(user, "json")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"name": "John",
+		"age":  &age,
+		"tags": []string{"a"},
+		"address": map[string]any{
+			"city":   "Paris",
+			"street": "Main",
+		},
+		"zip": "123",
+	}, m)
+
+	_, err = ToMap(*user, "json")
+	assert.Error(t, err)
+}
+
+func TestToMap_Collisions(t *testing.T) {
+	type nested struct {
+		Zip string `kv:"zip"`
+	}
+	type prefix struct {
+		Address string `kv:"address"`
+		Nested  nested `kv:"address"`
+	}
+	type dotted struct {
+		City    string `kv:"address.city"`
+		Address string `kv:"address"`
+	}
+	type same struct {
+		A      string `kv:"name"`
+		Nested struct {
+			B string `kv:"name"`
+		}
+	}
+	_, err := ToMap(&prefix{}, "kv")
+	assert.EqualError(t, err, "fmap: field Nested.Zip: tag path address.zip is nested in the tag path of the field Address")
+	_, err = ToMap(&dotted{}, "kv")
+	assert.EqualError(t, err, "fmap: field City: tag path address.city is nested in the tag path of the field Address")
+	m := map[string]any{}
+	err = ToMapInto(&same{A: "a"}, "kv", m)
+	assert.EqualError(t, err, "fmap: field Nested.B: tag path name collides with the field A")
+	assert.Empty(t, m)
+	m, err = ToMap(&same{}, "db")
+	assert.NoError(t, err)
+	assert.Empty(t, m)
+}
+
+func TestToMapInto(t *testing.T) {
+	user := &mapUser{Name: "John", Address: mapAddress{City: "Paris"}}
+	m := map[string]any{"stale": 1}
+	assert.NoError(t, ToMapInto(user, "json", m))
+	expected, _ := ToMap(user, "json")
+	expected["stale"] = 1
+	assert.Equal(t, expected, m)
+
+	address := m["address"].(map[string]any)
+	user.Address.City = "Berlin"
+	assert.NoError(t, ToMapInto(user, "json", m))
+	// the nested maps are reused
+	assert.Equal(t, "Berlin", address["city"])
+
+	assert.Error(t, ToMapInto(user, "json", nil))
+	assert.Error(t, ToMapInto(*user, "json", m))
+}
+
+func TestToMap_OmitEmpty(t *testing.T) {
+	type omitStruct struct {
+		String  string            `json:"string,omitempty"`
+		Ptr     *int              `json:"ptr,omitempty"`
+		Slice   []string          `json:"slice,omitempty"`
+		Map     map[string]int    `json:"map,omitempty"`
+		Bool    bool              `json:"bool,omitempty"`
+		Time    time.Time         `json:"time,omitempty"`
+		Kept    string            `json:"kept"`
+		Nested  mapAddress        `json:"nested,omitempty"`
+		Options map[string]string `json:"options,string,omitempty"`
+	}
+	t.Run("Empty", func(t *testing.T) {
+		obj := &omitStruct{Slice: []string{}, Map: map[string]int{}}
+		m, err := ToMap(obj, "json")
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{
+			"time":   time.Time{},
+			"kept":   "",
+			"nested": map[string]any{"city": "", "street": ""},
+		}, m)
+		assert.Equal(t, jsonKeys(t, obj), mapKeys(m))
+	})
+	t.Run("NonEmpty", func(t *testing.T) {
+		zero := 0
+		obj := &omitStruct{String: "a", Ptr: &zero, Slice: []string{""}, Map: map[string]int{"a": 0}, Bool: true}
+		m, err := ToMap(obj, "json")
+		assert.NoError(t, err)
+		assert.Equal(t, "a", m["string"])
+		assert.Same(t, &zero, m["ptr"])
+		assert.Equal(t, []string{""}, m["slice"])
+		assert.Equal(t, map[string]int{"a": 0}, m["map"])
+		assert.Equal(t, true, m["bool"])
+		assert.Equal(t, jsonKeys(t, obj), mapKeys(m))
+	})
+}
+
+// jsonKeys returns the sorted top-level keys of the obj encoded by encoding/json.
+func jsonKeys(t *testing.T, obj any) []string {
+	data, err := json.Marshal(obj)
+	assert.NoError(t, err)
+	var m map[string]any
+	assert.NoError(t, json.Unmarshal(data, &m))
+	return mapKeys(m)
+}
+
+func mapKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestFromMap(t *testing.T) {
+	t.Run("Populate", func(t *testing.T) {
+		user := &mapUser{}
+		err := FromMap(user, "json", map[string]any{
+			"name": "John",
+			"age":  float64(30),
+			"tags": []any{"a", "b"},
+			"address": map[string]any{
+				"city": "Paris",
+			},
+			"zip": "123",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "John", user.Name)
+		assert.Equal(t, 30, *user.Age)
+		assert.Equal(t, []string{"a", "b"}, user.Tags)
+		assert.Equal(t, "Paris", user.Address.City)
+		assert.Equal(t, "123", user.Plain.Zip)
+	})
+	t.Run("RoundTrip", func(t *testing.T) {
+		age := 5
+		src := &mapUser{Name: "John", Age: &age, Address: mapAddress{City: "Paris"}}
+		m, _ := ToMap(src, "json")
+		dst := &mapUser{}
+		assert.NoError(t, FromMap(dst, "json", m))
+		assert.Equal(t, src, dst)
+	})
+	t.Run("UnknownKeys", func(t *testing.T) {
+		user := &mapUser{}
+		err := FromMap(user, "json", map[string]any{
+			"name":    "John",
+			"unknown": 1,
+			"address": map[string]any{"country": "FR"},
+		})
+		var unknownErr *UnknownKeysError
+		assert.ErrorAs(t, err, &unknownErr)
+		assert.Equal(t, []string{"address.country", "unknown"}, unknownErr.Keys)
+		assert.Equal(t, "John", user.Name)
+	})
+	t.Run("ConvertError", func(t *testing.T) {
+		user := &mapUser{}
+		err := FromMap(user, "json", map[string]any{"age": 1.5})
+		assert.Error(t, err)
+		err = FromMap(user, "json", map[string]any{"name": 1})
+		assert.Error(t, err)
+	})
+	t.Run("Atomic", func(t *testing.T) {
+		for i := 0; i < 20; i++ {
+			user := &mapUser{Name: "John"}
+			err := FromMap(user, "json", map[string]any{
+				"age":     1.5,
+				"name":    "Jane",
+				"tags":    []any{"a"},
+				"address": map[string]any{"city": 1},
+			})
+			// the first failed key in the sorted order is reported and nothing is written
+			assert.EqualError(t, err, "fmap: field Address.City: can't convert value of type int to string")
+			assert.Equal(t, mapUser{Name: "John"}, *user)
+		}
+	})
+}
+
+func TestApplyTagMap(t *testing.T) {
+	t.Run("Apply", func(t *testing.T) {
+		user := &mapUser{Name: "John"}
+		errs := ApplyTagMap(user, "json", map[string]any{
+			"age":          int64(30),
+			"tags":         []any{"a", "b"},
+			"address.city": "Paris",
+			"zip":          "123",
+		})
+		assert.Empty(t, errs)
+		assert.Equal(t, "John", user.Name)
+		assert.Equal(t, 30, *user.Age)
+		assert.Equal(t, []string{"a", "b"}, user.Tags)
+		assert.Equal(t, "Paris", user.Address.City)
+		assert.Equal(t, "123", user.Plain.Zip)
+	})
+	t.Run("PerKeyErrors", func(t *testing.T) {
+		user := &mapUser{}
+		errs := ApplyTagMap(user, "json", map[string]any{
+			"name":            "John",
+			"age":             1.5,
+			"unknown":         1,
+			"address":         mapAddress{City: "Paris"},
+			"address.country": "FR",
+		})
+		assert.Len(t, errs, 3)
+		keys := make([]string, len(errs))
+		for i, err := range errs {
+			var keyErr *KeyError
+			assert.ErrorAs(t, err, &keyErr)
+			keys[i] = keyErr.Key
+		}
+		assert.Equal(t, []string{"address.country", "age", "unknown"}, keys)
+		assert.EqualError(t, errs[2], "key unknown: unknown tag path")
+		// the failed keys don't stop the others
+		assert.Equal(t, "John", user.Name)
+		assert.Equal(t, "Paris", user.Address.City)
+		assert.Nil(t, user.Age)
+	})
+	t.Run("StructPaths", func(t *testing.T) {
+		user := &mapUser{}
+		assert.Empty(t, ApplyTagMap(user, "", map[string]any{"Secret": "s", "Plain.Zip": "1"}))
+		assert.Equal(t, "s", user.Secret)
+		assert.Equal(t, "1", user.Plain.Zip)
+	})
+	t.Run("Collisions", func(t *testing.T) {
+		type Inner struct {
+			Name string `kv:"name"`
+		}
+		type testStruct struct {
+			Name  string `kv:"name"`
+			Inner Inner
+			Age   int `kv:"age"`
+		}
+		obj := &testStruct{}
+		assert.Empty(t, ApplyTagMap(obj, "kv", map[string]any{"age": 30}))
+		assert.Equal(t, 30, obj.Age)
+		errs := ApplyTagMap(obj, "kv", map[string]any{"name": "John", "age": 31})
+		assert.Len(t, errs, 1)
+		assert.EqualError(t, errs[0], "key name: fmap: field Inner.Name: tag path name collides with the field Name")
+		assert.Equal(t, testStruct{Age: 31}, *obj)
+	})
+	t.Run("Errors", func(t *testing.T) {
+		assert.Len(t, ApplyTagMap(mapUser{}, "json", map[string]any{"name": "John"}), 1)
+		assert.Len(t, ApplyTagMap(nil, "json", nil), 1)
+	})
+}
+
+func TestValues(t *testing.T) {
+	type weird struct {
+		Chan    chan int
+		Func    func()
+		Complex complex128
+		Iface   any
+		Array   [2]uintptr
+		Map     map[[2]int]*int
+	}
+	type testStruct struct {
+		mapUser
+		Weird weird
+		*embeddedMiddle
+	}
+	age := 30
+	fn := func() {}
+	obj := &testStruct{
+		mapUser: mapUser{Name: "John", Age: &age, Tags: []string{"a"}, Address: mapAddress{City: "Paris"}},
+		Weird:   weird{Chan: make(chan int), Func: fn, Complex: 1 + 2i, Iface: 5},
+	}
+	values := Values(obj)
+	fields, _ := GetFrom(obj)
+	assert.Len(t, values, len(fields.GetAllPaths()))
+	assert.Equal(t, "John", values["mapUser.Name"])
+	assert.Equal(t, &age, values["mapUser.Age"])
+	assert.Equal(t, []string{"a"}, values["mapUser.Tags"])
+	assert.Equal(t, mapAddress{City: "Paris"}, values["mapUser.Address"])
+	assert.Equal(t, "Paris", values["mapUser.Address.City"])
+	assert.Equal(t, obj.Weird.Chan, values["Weird.Chan"])
+	assert.NotNil(t, values["Weird.Func"])
+	assert.Equal(t, 1+2i, values["Weird.Complex"])
+	assert.Equal(t, 5, values["Weird.Iface"])
+	assert.Equal(t, [2]uintptr{}, values["Weird.Array"])
+	assert.Nil(t, values["Weird.Map"])
+	assert.Equal(t, 0, values["embeddedMiddle.ID"])
+	assert.Nil(t, obj.embeddedMiddle)
+
+	assert.Equal(t, "John", Values(*obj)["mapUser.Name"])
+	assert.Nil(t, Values(nil))
+	assert.Nil(t, Values(1))
+	assert.Nil(t, Values((*testStruct)(nil)))
+}
+
+func TestToMap_SkipTag(t *testing.T) {
+	type skipStruct struct {
+		Name     string     `json:"name"`
+		Password string     `json:"-"`
+		Internal mapAddress `json:"-"`
+	}
+	obj := &skipStruct{Name: "John", Password: "secret", Internal: mapAddress{City: "Paris"}}
+
+	m, err := ToMap(obj, "json")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"name": "John"}, m)
+
+	dst := &skipStruct{}
+	var unknown *UnknownKeysError
+	err = FromMap(dst, "json", map[string]any{"name": "Jane", "-": "secret", "city": "Paris"})
+	assert.ErrorAs(t, err, &unknown)
+	assert.ElementsMatch(t, []string{"-", "city"}, unknown.Keys)
+	assert.Equal(t, skipStruct{Name: "Jane"}, *dst)
+
+	diff, err := DiffByTag(obj, &skipStruct{Name: "John"}, "json")
+	assert.NoError(t, err)
+	assert.Empty(t, diff)
+
+	fields, _ := Get[skipStruct]()
+	assert.Equal(t, []string{"name"}, fields.Columns("json"))
+}
@@ -0,0 +1,22 @@
+package fmap
+
+import "reflect"
+
+// MapType applies fn to every field of type T in the object pointed to by obj.
+// Each matching field is read, passed through fn and written back via the field map.
+// Only fields whose type is exactly T are affected, named types built on T are not.
+func MapType[T any](obj any, fn func(T) T) error {
+	fields, err := getFromPtr(obj)
+	if err != nil {
+		return err
+	}
+	tType := reflect.TypeOf((*T)(nil)).Elem()
+	for _, path := range fields.GetAllPaths() {
+		fld := fields.MustFind(path)
+		if fld.GetType() != tType {
+			continue
+		}
+		fld.Set(obj, fn(fld.Get(obj).(T)))
+	}
+	return nil
+}
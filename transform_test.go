@@ -0,0 +1,49 @@
+package fmap
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMapType(t *testing.T) {
+	type Address struct {
+		City   string
+		Street string
+		Number int
+	}
+	type Person struct {
+		Name    string
+		Age     int
+		Tags    []string
+		Address Address
+	}
+
+	t.Run("TrimAndUpper", func(t *testing.T) {
+		p := &Person{
+			Name: "  john ",
+			Age:  42,
+			Tags: []string{" a "},
+			Address: Address{
+				City:   " paris",
+				Street: "main st  ",
+				Number: 7,
+			},
+		}
+		err := MapType(p, func(s string) string {
+			return strings.ToUpper(strings.TrimSpace(s))
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "JOHN", p.Name)
+		assert.Equal(t, "PARIS", p.Address.City)
+		assert.Equal(t, "MAIN ST", p.Address.Street)
+		assert.Equal(t, 42, p.Age)
+		assert.Equal(t, 7, p.Address.Number)
+		assert.Equal(t, []string{" a "}, p.Tags)
+	})
+	t.Run("NotAPointer", func(t *testing.T) {
+		err := MapType(Person{}, func(s string) string { return s })
+		assert.Error(t, err)
+	})
+}
@@ -1,6 +1,9 @@
 package fmap
 
-import "reflect"
+import (
+	"reflect"
+	"unsafe"
+)
 
 type Storage interface {
 	// Find returns the Field object and a boolean value indicating if the field with the given path was found.
@@ -10,15 +13,82 @@ type Storage interface {
 	Find(path string) (Field, bool)
 
 	// MustFind returns the Field object for the field with the given path in the struct.
-	// If the Field is not found, MustFind panics.
+	// If the Field is not found, MustFind panics with the error naming the path,
+	// so the typos in the field paths are caught at the lookup instead of the nil Field usage.
 	MustFind(path string) Field
 
+	// Get is the alias of Find.
+	Get(path string) (Field, bool)
+
+	// MustGet is the alias of MustFind.
+	MustGet(path string) Field
+
 	// GetAllPaths returns a slice containing all paths of fields in the struct ordered like field struct definition.
 	GetAllPaths() []string
 
 	GetFieldByPtr(structPtr, fieldPtr any) (Field, error)
+
+	// Leaves returns the Storage containing only the leaf fields, i.e. fields without expanded nested fields.
+	// All non-struct kinds (primitives, pointers, slices, arrays, maps, interfaces, etc.) are leaves,
+	// as well as the struct fields that are not expanded, e.g. the recursive ones or the ones limited by MaxDepth.
+	// The nested struct fields and the embedded struct pointers with the expanded nested fields are excluded.
+	Leaves() Storage
+
+	// Filter returns the Storage containing the fields matching the pred in the same order, e.g. all float fields
+	// deeper than the top level, the generalization of Leaves and OfType. The fields are the same instances
+	// as in the original Storage, the promoted names are kept for the matching fields.
+	Filter(pred func(f Field) bool) Storage
+
+	// TagIndex returns the fields keyed by their tag paths of the tag with ignored missing parent tags like
+	// GetFromByTag does, e.g. "address.city" for the json tag. The index is built lazily on the first call
+	// and cached on the Storage, each call returns the new copy of it, so the caller is free to modify the map.
+	// Unlike GetFromByTag, which returns an error on the colliding tag paths, TagIndex keeps the first field
+	// in the definition order and silently drops the others, see WithStrictTags to detect them.
+	TagIndex(tag string) map[string]Field
+
+	// ByTagPath returns the field with the tag path of the tag, see TagIndex.
+	ByTagPath(tag, tagPath string) (Field, bool)
+
+	// SubTree returns the Storage containing the field with the path and all fields nested in it,
+	// e.g. SubTree("User.Address") for the scoped sub-form, the unknown path gives the empty Storage.
+	// The fields are the same as in the original Storage: they keep the original struct paths
+	// and the offsets relative to the root object, so they still take the pointer to the root object, not to the sub-struct.
+	SubTree(path string) Storage
+
+	// SubTreeRerooted is the SubTree variant that strips the path prefix from the paths of the nested fields,
+	// e.g. "User.Address.City" is found as "City", the field with the path itself is excluded.
+	// Only the Storage paths are re-rooted, the fields still take the pointer to the root object
+	// and return the original struct paths from GetStructPath.
+	SubTreeRerooted(path string) Storage
+
+	// Rebase returns the copy of the Storage which fields offsets are shifted by the delta, so the field map
+	// of the inner struct type can be applied to the inner struct at the delta offset inside the outer struct,
+	// the outer becomes the owner of the rebased fields. Misuse corrupts the memory, so it panics
+	// if the outer type doesn't contain the inner struct at the delta offset.
+	Rebase(delta uintptr, outer reflect.Type) Storage
+
+	// OfType returns the fields of the typeOf type in the struct definition order, e.g. all time.Duration fields.
+	// The types are compared by the reflect.Type identity, so the named types and their underlying types are distinct,
+	// e.g. the time.Duration fields are not returned for the int64 type.
+	OfType(typeOf reflect.Type) []Field
+
+	// Prepare warms the reflection caches of all fields, e.g. the pointer types used by the composite Get and Set paths,
+	// so the first Get and Set calls in the hot loop don't allocate. It's safe to call it concurrently and more than once.
+	Prepare()
+
+	// Columns returns the names of the leaf fields, i.e. fields without nested fields, in the struct definition order.
+	// The name is the field tag path with ignored missing parent tags, fields without the tag are skipped.
+	// If the tag is empty, the struct paths are returned.
+	Columns(tag string) []string
 }
 
+// Field is an accessor to the struct field.
+//
+// Field metadata methods are read-only and safe for concurrent use, as well as the Storage itself.
+// Get, GetPtr, GetReflectValue, GetDereferenced and Set access the object memory without any synchronization:
+// concurrent calls on different fields of the same object are safe, but Set concurrent with
+// Set or Get of the same field in the same object is a data race. Use SyncField, SetLocked or
+// Synchronized storage for such cases.
 type Field interface {
 	// GetName returns the name of the field.
 	GetName() string
@@ -29,37 +99,159 @@ type Field interface {
 	// GetType returns the reflect.Type of the field.
 	GetType() reflect.Type
 
+	// GetKind returns the reflect.Kind of the field type, the shortcut for GetType().Kind().
+	GetKind() reflect.Kind
+
+	// IsPointer reports whether the field type is a pointer.
+	IsPointer() bool
+
+	// ElemKind returns the element kind of the pointer, slice, array or map field, e.g. reflect.Int for []int,
+	// or the reflect.Invalid for the other kinds.
+	ElemKind() reflect.Kind
+
+	// Implements reports whether the field type implements the iface interface type, see reflect.Type.Implements.
+	// It panics if the iface is not an interface type.
+	Implements(iface reflect.Type) bool
+
+	// PtrImplements reports whether the field type or the pointer to it implements the iface interface type,
+	// i.e. it covers the pointer receiver methods callable on the addressable field, e.g. via GetPtr.
+	// It panics if the iface is not an interface type.
+	PtrImplements(iface reflect.Type) bool
+
 	// GetTag returns the reflect.StructTag of the field. The reflect.StructTag is a string.
 	GetTag() reflect.StructTag
 
 	// GetOffset returns the offset of the field in memory relative to the start of the struct.
+	// For the fields behind the embedded struct pointer it's relative to the start of the pointed struct.
 	GetOffset() uintptr
 
-	// GetIndex returns the index of the field within its containing struct as a slice of integers.
+	// GetSize returns the number of bytes the field type value occupies in memory, see reflect.Type.Size.
+	GetSize() uintptr
+
+	// GetEnd returns the offset of the first byte after the field, i.e. GetOffset() + GetSize(),
+	// the field occupies the [GetOffset(), GetEnd()) byte range, the padding after it isn't included.
+	GetEnd() uintptr
+
+	// GetAlign returns the alignment in bytes of the field type value in memory, see reflect.Type.Align.
+	GetAlign() uintptr
+
+	// GetFieldAlign returns the alignment in bytes of the field type value used as the struct field,
+	// see reflect.Type.FieldAlign. The field offset is always the multiple of it.
+	GetFieldAlign() uintptr
+
+	// GetIndex returns the full index sequence of the field in the root struct, e.g. [1 0] for the first field
+	// of the second field struct, usable directly with reflect.Value.FieldByIndex and reflect.Type.FieldByIndex.
 	GetIndex() []int
 
 	// GetAnonymous returns a boolean value indicating whether the field is anonymous.
 	GetAnonymous() bool
 
+	// IsPromoted reports whether the field is reached through the embedded struct, i.e. any of its parents
+	// is anonymous, e.g. the CreatedAt field of the embedded Timestamps struct, while the Timestamps field itself
+	// is anonymous but not promoted unless it's embedded in the embedded struct too.
+	IsPromoted() bool
+
 	// IsExported checks if a field is exported by checking its PkgPath property.
 	IsExported() bool
 
 	// Get returns the value of the storage in the provided object.
-	// It takes a parameter `obj` of type `interface{}`, representing the object.
+	// It takes a parameter `obj` of type `interface{}`, representing the pointer to object.
 	// It returns the value of the storage as an `interface{}`.
+	// The value has the declared field type, i.e. the named types over the primitive kinds, e.g. type Status int,
+	// are returned as Status, not as the underlying int, so the type switches at the call site work.
+	// The same holds for the pointers to them and the named pointer types, e.g. type IntPtr *int, while the aliases,
+	// e.g. type Text = string, are the identical types, so their values are returned as the aliased type.
+	// So are the builtin byte and rune aliases: the byte and rune fields are returned as uint8 and int32,
+	// which are the identical types, so the `case byte:` and `case rune:` type switches match them,
+	// only the type names, e.g. printed with %T, are uint8 and int32, as reflect doesn't keep the alias names.
+	// It panics if the obj is not a non-nil pointer to the field owner struct.
 	Get(obj any) any
 
+	// GetByIndex returns the value of the field in the provided object read with reflect.Value.FieldByIndex
+	// by GetIndex instead of the offset, the safe fallback to Get to cross-check the offset access.
+	// It panics if the obj is not a non-nil pointer to the field owner struct.
+	GetByIndex(obj any) any
+
 	// GetPtr returns the pointer to the field's value in the provided object.
 	// It takes a parameter `obj` of type `any`, representing the pointer to object.
 	// It returns the pointer to the field's value as an `any`.
 	GetPtr(obj any) any
 
+	// GetUnsafePointer returns the raw pointer to the field's value in the provided object, the GetPtr one
+	// without the type, e.g. to pass it to cgo. It's unsafe: the pointer is only valid while the obj is alive,
+	// the caller is responsible for the type of the reads and writes through it, and the Go pointers stored
+	// through it aren't checked, see the cgo pointer passing rules. The nil embedded struct pointers on the way
+	// are allocated like GetPtr does.
+	// It panics if the obj is not a non-nil pointer to the field owner struct or the field is read-only.
+	GetUnsafePointer(obj any) unsafe.Pointer
+
+	// GetReflectValue returns the reflect.Value of the field in the provided object.
+	// The value is addressable and settable, it refers to the field memory, so .Set, .Addr, .Len, etc.
+	// can be called without the round trip through any. The nil embedded struct pointers on the way are allocated.
+	// It panics if the obj is not a non-nil pointer to the field owner struct.
+	GetReflectValue(obj any) reflect.Value
+
 	// Set updates the value of the storage in the provided object with the provided value.
 	// It takes two parameters:
 	//   - obj: interface{}, representing the object pointer containing the field.
 	//   - val: interface{}, representing the new value for the field.
+	// The val is assigned as is, i.e. the pointers, slices and maps are shallow copied: the *big.Int field set
+	// from the other object shares the same big.Int with it. Use DeepCopy for the independent copies.
+	// The nil val clears the pointer, slice, map, chan, func and interface fields. The pointer fields of any depth
+	// accept the values of the pointed types too, e.g. int for the **int field, written through the pointers.
+	// It panics if the obj is not a non-nil pointer to the field owner struct or if the val is nil for the other kinds.
 	Set(obj any, val any)
 
+	// SetWithHook updates the value of the field in the provided object like Set does
+	// and calls the hook with the field values before and after the update. The nil hook is ignored.
+	SetWithHook(obj any, val any, hook func(old, new any))
+
+	// SetDefault sets the val to the field in the provided object only if the field value is zero, i.e. IsZero,
+	// and reports whether it was set, so the values already set by the user are not clobbered by the defaults.
+	// The nil pointer is zero. It panics like Set does.
+	SetDefault(obj any, val any) bool
+
+	// SetConvert updates the value of the field in the provided object with the val converted to the field type.
+	// It supports nil, numeric, string and bool values of other types, pointers and element-wise slice, array and map conversion.
+	// It returns an error if the val can't be converted without loss.
+	SetConvert(obj any, val any) error
+
+	// SetReflectValue assigns the v to the field in the provided object, it's the Set variant
+	// for the values from the other reflection code that avoids boxing them into any.
+	// It panics if the obj is not a non-nil pointer to the field owner struct or if the v is not assignable to the field type.
+	SetReflectValue(obj any, v reflect.Value)
+
+	// TrySetReflectValue is the SetReflectValue variant that returns an error instead of panic.
+	TrySetReflectValue(obj any, v reflect.Value) error
+
+	// SetReflectValueConvert is the SetConvert variant for the reflect.Value.
+	SetReflectValueConvert(obj any, v reflect.Value) error
+
+	// SetFromString parses the s into the field type and updates the value of the field in the provided object.
+	// It supports strings, bools, numbers, time.Duration and pointers to them, ints support base prefixes like 0x, 0o and 0b,
+	// durations are parsed with time.ParseDuration, e.g. "1m30s". The types implementing encoding.TextUnmarshaler,
+	// e.g. net.IP, time.Time or uuid.UUID, are parsed with UnmarshalText, the pointers to them are allocated.
+	// It returns an error for the unsupported types or invalid strings.
+	SetFromString(obj any, s string) error
+
+	// SetFromJSON unmarshals the JSON data into the new value of the field type and assigns it to the field
+	// in the provided object, e.g. `{"city":"Paris"}` for the Address struct field, the pointers are allocated.
+	// It returns the error if the obj is not a non-nil pointer to the field owner struct or the data is invalid.
+	SetFromJSON(obj any, data []byte) error
+
+	// GetAsString returns the value of the field in the provided object formatted as the string.
+	// Primitives, time.Duration and the encoding.TextMarshaler types are formatted so they can be parsed back
+	// with SetFromString, composites are formatted with fmt.Sprint.
+	// Pointers are dereferenced, the nil pointer is formatted as the empty string.
+	GetAsString(obj any) string
+
+	// TryGet is the Get variant that returns an error instead of panic if the obj is not a non-nil pointer to the field owner struct.
+	TryGet(obj any) (any, error)
+
+	// TrySet is the Set variant that returns an error instead of panic if the obj is not a non-nil pointer to the field owner struct
+	// or if the val is not assignable to the field type.
+	TrySet(obj any, val any) error
+
 	// GetStructPath returns the struct path of the field.
 	// It returns the struct path as a string.
 	GetStructPath() string
@@ -68,9 +260,58 @@ type Field interface {
 	// It takes two parameters:
 	//   - tag: string, representing the tag name.
 	//   - ignoreParentTagMissing: bool, representing whether to ignore the missing parent tags or not.
-	// It returns the tag value path as a string.
+	// It returns the tag value path as a string, the empty one if the field or its parent is excluded with the "-" tag value.
 	GetTagPath(tag string, ignoreParentTagMissing bool) string
 
+	// GetTagPathWithSep is the GetTagPath variant that joins the parent and the child tag names with the sep
+	// instead of the ".", e.g. "__" for the OUTER__INNER__NAME env-var naming.
+	GetTagPathWithSep(tag, sep string, ignoreParentTagMissing bool) string
+
+	// GetTagPathFunc is the GetTagPathWithSep variant that applies the transform to each tag name,
+	// the parent and the leaf ones, before joining, e.g. strings.ToUpper with the "_" sep gives OUTER_INNER_NAME.
+	// The nil transform keeps the tag names as is.
+	GetTagPathFunc(tag string, transform func(string) string, sep string, ignoreParentTagMissing bool) string
+
+	// IsTagSkipped reports whether the field or any of its parents has the tag value of exactly "-", e.g. `json:"-"`,
+	// such fields are skipped by ToMap, FromMap, Flatten, GetFromByTag and the other tag keyed functions
+	// and their GetTagPath is empty.
+	IsTagSkipped(tag string) bool
+
+	// GetTagOr returns the raw value of the tag, including the options, or the def if the tag is absent.
+	// The present empty tag, e.g. `scope:""`, is returned as is.
+	GetTagOr(tag, def string) string
+
+	// GetTagOptions returns the options following the name in the tag value, e.g. ["omitempty"] for `json:"name,omitempty"`.
+	// It returns nil if the tag is missing or has no options.
+	GetTagOptions(tag string) []string
+
+	// HasTagOption reports whether the tag value has the option, e.g. HasTagOption("json", "omitempty").
+	HasTagOption(tag, option string) bool
+
+	// IsZero reports whether the field value in the provided object is the zero value of the field type.
+	// It panics if the obj is not a non-nil pointer to the field owner struct.
+	IsZero(obj any) bool
+
+	// String returns the human-readable field description for debugging.
+	String() string
+
+	// GetSchema returns the marshalable description of the field: its name, type, json tag path,
+	// whether it's optional and its element type, see FieldSchema.
+	GetSchema() FieldSchema
+
+	// GetDepth returns the nesting level of the field: 0 for the top-level fields, 1 for their nested fields, etc.
+	GetDepth() int
+
+	// GetStruct returns the type of the struct that directly declares the field,
+	// i.e. the parent field dereferenced type or the root struct type for the top-level fields.
+	GetStruct() reflect.Type
+
+	// Clone returns the independent copy of the field, including the copy of its parent chain, with the same
+	// struct path, so it works for Get and Set like the original one and can be cached apart from the Storage.
+	// Note the fields metadata is immutable once the Storage is built, so the original fields are safe
+	// to share between goroutines and to keep as long as needed too, Clone is never required for that.
+	Clone() Field
+
 	// GetParent returns the parent field of the current field, if not exist return nil.
 	GetParent() Field
 
@@ -80,4 +321,129 @@ type Field interface {
 
 	// GetDereferenced - uses reflect package for casting field value from obj to direct field value, i.e. dereferenced value.
 	GetDereferenced(obj any) (any, bool)
+
+	// EnsureNonNil allocates the zero value of the element type if the pointer field in the provided object is nil
+	// and returns the field value, i.e. the possibly newly allocated pointer, the non-nil pointers are left untouched.
+	// It panics if the field is not a pointer.
+	EnsureNonNil(obj any) any
+
+	// Equal reports whether the field values in the a and b objects are equal, e.g. to skip the no-op copying.
+	// The primitives are compared in place without boxing, the composite values with reflect.DeepEqual,
+	// so the nil pointers are equal and the non-nil pointers are equal if they point to the deeply equal values.
+	// It panics if the a or b is not a non-nil pointer to the field owner struct.
+	Equal(a, b any) bool
+
+	// GetSliceLen returns the length of the slice field in the provided object, nil pointer to slice has zero length.
+	// It panics if the field is not a slice or pointer to slice.
+	GetSliceLen(obj any) int
+
+	// GetSliceIndex returns the i-th element of the slice field in the provided object.
+	// It panics if the field is not a slice or pointer to slice, or if the i is out of range.
+	GetSliceIndex(obj any, i int) any
+
+	// SetSliceLen sets the length of the slice field in the provided object to the n, the new elements are zero,
+	// the slice is reallocated if the n exceeds its capacity, e.g. to prepare it for SetSliceIndex.
+	// It panics if the field is not a slice or pointer to slice or if the n is negative.
+	SetSliceLen(obj any, n int)
+
+	// SetSliceIndex sets the i-th element of the slice field in the provided object in place.
+	// It panics if the field is not a slice or pointer to slice, if the i is out of range
+	// or if the val is not assignable to the element type.
+	SetSliceIndex(obj any, i int, val any)
+
+	// GetBytes returns the []byte field value in the provided object without reflection, the fast path for the codecs.
+	// The underlying slice is returned without copying, the writes to its elements modify the field.
+	// It panics if the field is not a slice of bytes.
+	GetBytes(obj any) []byte
+
+	// SetBytes sets the b to the []byte field in the provided object without copying.
+	// It panics if the field is not a slice of bytes.
+	SetBytes(obj any, b []byte)
+
+	// ElementStorage returns the Storage of the element struct type of the slice or array of structs
+	// or pointers to structs field, e.g. of Address for the []Address field, to access the fields of the elements.
+	// Its fields offsets are relative to the element start, so they take the element pointer, see ElementPtr.
+	ElementStorage() (Storage, error)
+
+	// ElementPtr returns the pointer to the i-th element struct of the slice or array field in the provided object,
+	// e.g. *Address for the []Address and []*Address fields, to be passed to the ElementStorage fields.
+	// It panics if the field is not a slice or array of structs or pointers to structs or if the i is out of range.
+	ElementPtr(obj any, i int) any
+
+	// GetArrayIndex returns the i-th element of the array field in the provided object without copying the whole array.
+	// It panics if the field is not an array or if the i is out of range.
+	GetArrayIndex(obj any, i int) any
+
+	// SetArrayIndex sets the i-th element of the array field in the provided object in place.
+	// It panics if the field is not an array, if the i is out of range or if the val is not assignable to the element type.
+	SetArrayIndex(obj any, i int, val any)
+
+	// TryGetArrayIndex is the GetArrayIndex variant that returns an error instead of panic.
+	TryGetArrayIndex(obj any, i int) (any, error)
+
+	// TrySetArrayIndex is the SetArrayIndex variant that returns an error instead of panic.
+	// The nil val is accepted for the nilable element types, e.g. pointers, and sets the element to nil.
+	TrySetArrayIndex(obj any, i int, val any) error
+
+	// GetMapKey returns the value stored by the key in the map field of the provided object
+	// and a boolean value indicating if the key was found.
+	// It panics if the field is not a map or pointer to map, or if the key type doesn't match.
+	GetMapKey(obj any, key any) (any, bool)
+
+	// SetMapKey stores the val by the key in the map field of the provided object, nil maps are allocated.
+	// It panics if the field is not a map or pointer to map, or if the key or val types don't match.
+	SetMapKey(obj any, key any, val any)
+
+	// GetBit reports whether the bit, counting from the least significant 0, of the integer field in the provided
+	// object is set, e.g. for the feature flags packed into the uint64 field.
+	// It panics if the field is not an integer or if the bit is out of the field bit width.
+	GetBit(obj any, bit int) bool
+
+	// SetBit sets or clears the bit of the integer field in the provided object.
+	// It's the plain read-modify-write, not atomic, the concurrent SetBit calls on the same field must be synchronized.
+	// It panics if the field is not an integer or if the bit is out of the field bit width.
+	SetBit(obj any, bit int, v bool)
+
+	// GetAtomic returns the value of the int32, int64, uint32 or uint64 field in the provided object loaded atomically.
+	// The 64-bit fields must be 64-bit aligned, which is guaranteed on the 64-bit platforms only, see sync/atomic.
+	// It panics if the field is not one of the supported kinds or is not aligned.
+	GetAtomic(obj any) any
+
+	// SetAtomic stores the val to the int32, int64, uint32 or uint64 field in the provided object atomically.
+	// It panics if the field is not one of the supported kinds or is not aligned, or if the val is not of the field type.
+	SetAtomic(obj any, val any)
+
+	// AddAtomic atomically adds the delta to the int32, int64, uint32 or uint64 field in the provided object
+	// and returns the new value, e.g. for the lock-free counters.
+	// It panics if the field is not one of the supported kinds or is not aligned.
+	AddAtomic(obj any, delta int64) int64
+
+	// GetAtomicValue returns the value of the sync/atomic wrapper field, e.g. atomic.Int64 or atomic.Pointer[T],
+	// in the provided object loaded with its Load method, as Get returns the copy of the wrapper, which is not safe.
+	// It panics if the field is not the sync/atomic wrapper.
+	GetAtomicValue(obj any) any
+
+	// SetAtomicValue stores the val to the sync/atomic wrapper field in the provided object with its Store method.
+	// It panics if the field is not the sync/atomic wrapper or if the val is not assignable to the Store argument type.
+	SetAtomicValue(obj any, val any)
+
+	// HasPointers reports whether the field type contains GC-managed pointers, like string, slice, map or pointer.
+	HasPointers() bool
+
+	// GetRaw returns the copy of the field memory in the provided object.
+	// It returns an error for the fields containing GC-managed pointers, see HasPointers.
+	GetRaw(obj any) ([]byte, error)
+
+	// SetRaw overwrites the field memory in the provided object with the raw bytes of the field type size.
+	// It returns an error for the fields containing GC-managed pointers, see HasPointers.
+	SetRaw(obj any, raw []byte) error
+
+	// GetRawBytes returns the byte slice of the field type size aliasing the field memory in the provided object.
+	// Mutating the slice mutates the struct, see CopyRawBytes for the safe copy.
+	// It panics if the obj is not a non-nil ptr to the field owner struct.
+	GetRawBytes(obj any) []byte
+
+	// CopyRawBytes returns the copy of the field memory in the provided object, the fields with pointers are allowed.
+	// It panics if the obj is not a non-nil ptr to the field owner struct.
+	CopyRawBytes(obj any) []byte
 }
@@ -0,0 +1,49 @@
+package fmap
+
+import (
+	"net/url"
+	"reflect"
+)
+
+// ToURLValues returns the url.Values of the object pointed to by obj, e.g. for the form-encoded request body,
+// keyed like Flatten does: by the dotted tag paths with the ignored missing parent tags, or by the struct paths
+// if the tag is empty. The fields without the tag and the fields tagged or nested in the fields tagged with "-"
+// are skipped. The scalar leaves are formatted like Field.GetAsString does, the slices and arrays of scalars
+// produce the repeated keys, one per element, the other composite leaves and the nil pointers are skipped.
+// The fields with the omitempty tag option are skipped if their values are empty like ToMap defines it.
+func ToURLValues(obj any, tag string) (url.Values, error) {
+	fields, err := getFromPtr(obj)
+	if err != nil {
+		return nil, err
+	}
+	values := url.Values{}
+	for _, path := range fields.paths {
+		fld := fields.asMap[path].(*field)
+		if fld.hasChildren {
+			continue
+		}
+		key := tagKey(fld, tag)
+		if key == "" {
+			continue
+		}
+		ptr := fld.getReadPtr(obj)
+		if tag != "" && fld.HasTagOption(tag, "omitempty") && isEmptyJSON(fld.Type, ptr) {
+			continue
+		}
+		val := reflect.NewAt(fld.Type, ptr).Elem()
+		switch {
+		case isScalarType(fld.Type):
+			if val.Kind() == reflect.Ptr && val.IsNil() {
+				continue
+			}
+			values.Add(key, formatValue(val))
+		case (val.Kind() == reflect.Slice || val.Kind() == reflect.Array) && isScalarType(val.Type().Elem()):
+			for i := 0; i < val.Len(); i++ {
+				if elem := val.Index(i); elem.Kind() != reflect.Ptr || !elem.IsNil() {
+					values.Add(key, formatValue(elem))
+				}
+			}
+		}
+	}
+	return values, nil
+}
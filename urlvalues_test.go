@@ -0,0 +1,68 @@
+package fmap
+
+import (
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToURLValues(t *testing.T) {
+	type Filter struct {
+		Status string `form:"status,omitempty"`
+		Limit  int    `form:"limit"`
+	}
+	type request struct {
+		Query    string        `form:"q"`
+		Page     *int          `form:"page"`
+		Timeout  time.Duration `form:"timeout"`
+		IP       net.IP        `form:"ip"`
+		Tags     []string      `form:"tag"`
+		IDs      [2]int        `form:"id"`
+		Empty    []string      `form:"empty,omitempty"`
+		Zero     int           `form:"zero,omitempty"`
+		Token    string        `form:"-"`
+		Filter   Filter        `form:"filter"`
+		Meta     map[string]string
+		Untagged string
+	}
+	obj := &request{
+		Query:    "go",
+		Timeout:  time.Second,
+		IP:       net.ParseIP("10.0.0.1"),
+		Tags:     []string{"a", "b"},
+		IDs:      [2]int{1, 2},
+		Token:    "secret",
+		Filter:   Filter{Limit: 10},
+		Untagged: "untagged",
+	}
+	values, err := ToURLValues(obj, "form")
+	assert.NoError(t, err)
+	assert.Equal(t, url.Values{
+		"q":            {"go"},
+		"timeout":      {"1s"},
+		"ip":           {"10.0.0.1"},
+		"tag":          {"a", "b"},
+		"id":           {"1", "2"},
+		"filter.limit": {"10"},
+	}, values)
+	assert.Equal(t, "filter.limit=10&id=1&id=2&ip=10.0.0.1&q=go&tag=a&tag=b&timeout=1s", values.Encode())
+
+	page := 2
+	obj.Page = &page
+	obj.Filter.Status = "open"
+	values, _ = ToURLValues(obj, "form")
+	assert.Equal(t, []string{"2"}, values["page"])
+	assert.Equal(t, []string{"open"}, values["filter.status"])
+
+	values, err = ToURLValues(obj, "")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"untagged"}, values["Untagged"])
+	assert.Equal(t, []string{"secret"}, values["Token"])
+	assert.Equal(t, []string{"0"}, values["Zero"])
+
+	_, err = ToURLValues(*obj, "form")
+	assert.Error(t, err)
+}
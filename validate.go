@@ -0,0 +1,162 @@
+package fmap
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ValidateTag is the struct tag with the comma separated rules checked by Validate, e.g. `validate:"required,max=100"`.
+const ValidateTag = "validate"
+
+// ValidateRule checks the field value val against the rule parameter param, e.g. "100" for the `max=100` rule
+// and "" for the `required` one. The val is the value of the field type, the pointers are not dereferenced.
+type ValidateRule func(val reflect.Value, param string) error
+
+var (
+	validateRulesMu sync.RWMutex
+	validateRules   = map[string]ValidateRule{
+		"required": validateRequired,
+		"min":      validateMin,
+		"max":      validateMax,
+		"len":      validateLen,
+	}
+)
+
+// RegisterValidateRule registers the rule used by Validate for the rule name in the validate tag.
+// The built-in required, min, max and len rules can be replaced too. It's safe for the concurrent use.
+func RegisterValidateRule(name string, rule ValidateRule) {
+	validateRulesMu.Lock()
+	defer validateRulesMu.Unlock()
+	validateRules[name] = rule
+}
+
+// ValidationError is returned by Validate when some fields violate their rules.
+// The Errors are ordered like the fields in GetAllPaths, then like the rules in the tag.
+type ValidationError struct {
+	Errors []error
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Validate checks the fields of the object pointed to by obj against the rules in their validate tags
+// and returns the *ValidationError listing every violation. It covers the basics without the validator libraries:
+//   - required: the value is not zero;
+//   - min=N, max=N: the number is not less or greater than N, for the strings, slices, arrays and maps their length is;
+//   - len=N: the length of the string, slice, array or map is N.
+//
+// The rules other than required are skipped for the nil pointers and applied to the pointed values otherwise.
+// The nested struct fields are validated too, the fields behind the nil embedded struct pointers are zero.
+// The unknown rule names are reported as violations, see RegisterValidateRule to add them.
+func Validate(obj any) error {
+	fields, err := getFromPtr(obj)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, path := range fields.paths {
+		fld := fields.asMap[path].(*field)
+		tag, ok := fld.Tag.Lookup(ValidateTag)
+		if !ok || tag == "" || tag == "-" {
+			continue
+		}
+		val := reflect.NewAt(fld.Type, fld.getReadPtr(obj)).Elem()
+		for _, rule := range strings.Split(tag, ",") {
+			name, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+			if err = validateRule(val, name, param); err != nil {
+				errs = append(errs, fmt.Errorf("fmap: field %s: %s: %w", fld.structPath, name, err))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+func validateRule(val reflect.Value, name, param string) error {
+	validateRulesMu.RLock()
+	rule, ok := validateRules[name]
+	validateRulesMu.RUnlock()
+	if !ok {
+		return fmt.Errorf("unknown rule")
+	}
+	return rule(val, param)
+}
+
+func validateRequired(val reflect.Value, _ string) error {
+	if val.IsZero() {
+		return fmt.Errorf("value is required")
+	}
+	return nil
+}
+
+func validateMin(val reflect.Value, param string) error {
+	return validateBound(val, param, func(n, bound float64) bool { return n >= bound }, "less than the min")
+}
+
+func validateMax(val reflect.Value, param string) error {
+	return validateBound(val, param, func(n, bound float64) bool { return n <= bound }, "greater than the max")
+}
+
+// validateBound checks the number or the length of the val with the ok func against the bound parsed from the param.
+func validateBound(val reflect.Value, param string, ok func(n, bound float64) bool, violation string) error {
+	if val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return nil
+		}
+		val = val.Elem()
+	}
+	bound, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return fmt.Errorf("invalid parameter %q: %w", param, err)
+	}
+	var n float64
+	what := "value"
+	switch val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = float64(val.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n = float64(val.Uint())
+	case reflect.Float32, reflect.Float64:
+		n = val.Float()
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		n, what = float64(val.Len()), "length"
+	default:
+		return fmt.Errorf("not supported type: %v", val.Type())
+	}
+	if !ok(n, bound) {
+		return fmt.Errorf("%s %v is %s %s", what, n, violation, param)
+	}
+	return nil
+}
+
+func validateLen(val reflect.Value, param string) error {
+	if val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return nil
+		}
+		val = val.Elem()
+	}
+	length, err := strconv.Atoi(param)
+	if err != nil {
+		return fmt.Errorf("invalid parameter %q: %w", param, err)
+	}
+	switch val.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+	default:
+		return fmt.Errorf("not supported type: %v", val.Type())
+	}
+	if val.Len() != length {
+		return fmt.Errorf("length %d is not %d", val.Len(), length)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package fmap
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type validateAddress struct {
+	City string `validate:"required"`
+	Zip  string `validate:"len=5"`
+}
+
+type validateUser struct {
+	Name     string            `validate:"required,max=10"`
+	Age      int               `validate:"min=18,max=130"`
+	Score    *float64          `validate:"min=0.5"`
+	Tags     []string          `validate:"min=1"`
+	Labels   map[string]string `validate:"max=1"`
+	Address  validateAddress
+	Nickname string `validate:"-"`
+	Comment  string
+}
+
+func TestValidate(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		score := 1.0
+		assert.NoError(t, Validate(&validateUser{
+			Name:    "John",
+			Age:     30,
+			Score:   &score,
+			Tags:    []string{"a"},
+			Address: validateAddress{City: "Paris", Zip: "75001"},
+		}))
+	})
+	t.Run("Violations", func(t *testing.T) {
+		score := 0.1
+		err := Validate(&validateUser{
+			Name:    "John Doe Smith",
+			Age:     10,
+			Score:   &score,
+			Labels:  map[string]string{"a": "1", "b": "2"},
+			Address: validateAddress{Zip: "750"},
+		})
+		var validationErr *ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+		msgs := make([]string, len(validationErr.Errors))
+		for i, err := range validationErr.Errors {
+			msgs[i] = err.Error()
+		}
+		assert.Equal(t, []string{
+			"fmap: field Name: max: length 14 is greater than the max 10",
+			"fmap: field Age: min: value 10 is less than the min 18",
+			"fmap: field Score: min: value 0.1 is less than the min 0.5",
+			"fmap: field Tags: min: length 0 is less than the min 1",
+			"fmap: field Labels: max: length 2 is greater than the max 1",
+			"fmap: field Address.City: required: value is required",
+			"fmap: field Address.Zip: len: length 3 is not 5",
+		}, msgs)
+		assert.Equal(t, strings.Join(msgs, "; "), err.Error())
+	})
+	t.Run("NilPointer", func(t *testing.T) {
+		err := Validate(&validateUser{Name: "John", Age: 30, Tags: []string{"a"}, Address: validateAddress{City: "Paris", Zip: "75001"}})
+		assert.NoError(t, err)
+	})
+	t.Run("Misuse", func(t *testing.T) {
+		type testStruct struct {
+			Bool    bool   `validate:"min=1"`
+			Param   int    `validate:"max=ten"`
+			Unknown string `validate:"email"`
+		}
+		err := Validate(&testStruct{})
+		assert.EqualError(t, err, "fmap: field Bool: min: not supported type: bool; "+
+			`fmap: field Param: max: invalid parameter "ten": strconv.ParseFloat: parsing "ten": invalid syntax; `+
+			"fmap: field Unknown: email: unknown rule")
+		assert.Error(t, Validate(testStruct{}))
+	})
+}
+
+func TestRegisterValidateRule(t *testing.T) {
+	RegisterValidateRule("prefix", func(val reflect.Value, param string) error {
+		if !strings.HasPrefix(val.String(), param) {
+			return fmt.Errorf("value %q has no prefix %q", val.String(), param)
+		}
+		return nil
+	})
+	type testStruct struct {
+		ID string `validate:"required,prefix=id-"`
+	}
+	assert.NoError(t, Validate(&testStruct{ID: "id-1"}))
+	assert.EqualError(t, Validate(&testStruct{ID: "1"}), `fmap: field ID: prefix: value "1" has no prefix "id-"`)
+}
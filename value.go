@@ -0,0 +1,41 @@
+package fmap
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// GetFromValue returns the value of the field f in the struct v passed by value.
+// The v is copied into the new addressable struct and the field is read from the copy,
+// so it's for read-only access only: the changes made through the returned reference types
+// (slices, maps, pointers) are visible in the v, but nothing can be set to the v itself.
+// If the v is already a pointer, it's read directly without copying.
+// It panics if the v is not the field owner struct or pointer to it.
+func GetFromValue(f Field, v any) any {
+	valOf := reflect.ValueOf(v)
+	if valOf.Kind() != reflect.Struct {
+		return f.Get(v)
+	}
+	cp := reflect.New(valOf.Type())
+	cp.Elem().Set(valOf)
+	return f.Get(cp.Interface())
+}
+
+// FieldPtr is the generic variant of Field.GetPtr returning the typed pointer to the field of the T type
+// in the object pointed to by obj, e.g. to update the field in place with *p++ instead of Set.
+// The nil embedded struct pointers on the way to the field are allocated like GetPtr does.
+// It returns an error instead of panic if the field type is not exactly T or the obj is not the field owner.
+func FieldPtr[T any](f Field, obj any) (ptr *T, err error) {
+	if typeOf := reflect.TypeOf(ptr).Elem(); f.GetType() != typeOf {
+		return nil, fmt.Errorf("fmap: field %s: field type %v doesn't match %v", f.GetStructPath(), f.GetType(), typeOf)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			if err, _ = r.(error); err == nil {
+				err = fmt.Errorf("%v", r)
+			}
+			ptr = nil
+		}
+	}()
+	return f.GetPtr(obj).(*T), nil
+}
@@ -0,0 +1,58 @@
+package fmap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetFromValue(t *testing.T) {
+	type inner struct {
+		Name string
+	}
+	type testStruct struct {
+		ID    int
+		Inner inner
+		Tags  []string
+	}
+	fields, _ := Get[testStruct]()
+	obj := testStruct{ID: 5, Inner: inner{Name: "john"}, Tags: []string{"a"}}
+
+	assert.Equal(t, 5, GetFromValue(fields.MustFind("ID"), obj))
+	assert.Equal(t, "john", GetFromValue(fields.MustFind("Inner.Name"), obj))
+	assert.Equal(t, inner{Name: "john"}, GetFromValue(fields.MustFind("Inner"), obj))
+	assert.Equal(t, []string{"a"}, GetFromValue(fields.MustFind("Tags"), obj))
+	assert.Equal(t, 5, GetFromValue(fields.MustFind("ID"), &obj))
+
+	assert.Panics(t, func() { GetFromValue(fields.MustFind("ID"), inner{}) })
+	assert.Panics(t, func() { GetFromValue(fields.MustFind("ID"), nil) })
+}
+
+func TestFieldPtr(t *testing.T) {
+	type inner struct {
+		Name string
+	}
+	type testStruct struct {
+		Count int
+		*inner
+	}
+	fields, _ := Get[testStruct]()
+	obj := &testStruct{Count: 1}
+
+	p, err := FieldPtr[int](fields.MustFind("Count"), obj)
+	assert.NoError(t, err)
+	*p++
+	assert.Equal(t, 2, obj.Count)
+
+	name, err := FieldPtr[string](fields.MustFind("inner.Name"), obj)
+	assert.NoError(t, err)
+	*name = "john"
+	assert.Equal(t, "john", obj.Name)
+
+	_, err = FieldPtr[int64](fields.MustFind("Count"), obj)
+	assert.EqualError(t, err, "fmap: field Count: field type int doesn't match int64")
+	_, err = FieldPtr[int](fields.MustFind("Count"), &inner{})
+	assert.Error(t, err)
+	_, err = FieldPtr[int](fields.MustFind("Count"), (*testStruct)(nil))
+	assert.Error(t, err)
+}
@@ -0,0 +1,103 @@
+package fmap
+
+import (
+	"context"
+	"errors"
+	"reflect"
+)
+
+var (
+	// SkipStruct is used as a return value from the walk functions to indicate that
+	// the nested fields of the current field are to be skipped.
+	SkipStruct = errors.New("skip this struct")
+
+	// SkipAll is used as a return value from the walk functions to indicate that
+	// all remaining fields are to be skipped. It is not returned as an error by any function.
+	SkipAll = errors.New("skip everything and stop the walk")
+)
+
+// Walk calls the fn for every field of the obj struct or ptr to struct in the struct definition order,
+// parents before their nested fields. It stops on the first error returned by the fn and returns it,
+// except SkipStruct, which skips the nested fields of the current field, and SkipAll, which stops the walk
+// and makes Walk return nil.
+func Walk(obj any, fn func(f Field) error) error {
+	fields, err := getFrom(reflect.TypeOf(obj))
+	if err != nil {
+		return err
+	}
+	return fields.walk(fn)
+}
+
+// walkContextInterval is the number of the fields WalkContext visits between the context checks.
+const walkContextInterval = 64
+
+// WalkContext is the Walk variant that aborts the walk when the ctx is done, e.g. when the request is cancelled,
+// and returns the ctx.Err(). The ctx is checked before the walk and every walkContextInterval fields
+// rather than every field, so the fn can be called for a few fields after the ctx is done.
+func WalkContext(ctx context.Context, obj any, fn func(f Field) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	fields, err := getFrom(reflect.TypeOf(obj))
+	if err != nil {
+		return err
+	}
+	visited := 0
+	return fields.walk(func(f Field) error {
+		visited++
+		if visited%walkContextInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		return fn(f)
+	})
+}
+
+// WalkTree is the Walk variant that passes the nesting depth of the field to the fn, 0 for the top-level fields,
+// e.g. to indent the fields in the tree view. The fields are visited parents before their nested fields,
+// so the fn can skip the subtree of the current field by returning SkipStruct.
+func WalkTree(obj any, fn func(f Field, depth int) error) error {
+	fields, err := getFrom(reflect.TypeOf(obj))
+	if err != nil {
+		return err
+	}
+	return fields.walk(func(f Field) error {
+		return fn(f, f.GetDepth())
+	})
+}
+
+// WalkValues is the Walk variant for the ptr to struct obj which passes the current field value to the fn.
+func WalkValues(obj any, fn func(f Field, v any) error) error {
+	fields, err := getFromPtr(obj)
+	if err != nil {
+		return err
+	}
+	return fields.walk(func(f Field) error {
+		return fn(f, f.Get(obj))
+	})
+}
+
+func (s *storage) walk(fn func(f Field) error) error {
+	skipDepth := -1
+	for _, path := range s.paths {
+		fld := s.asMap[path].(*field)
+		if skipDepth >= 0 {
+			if fld.depth > skipDepth {
+				continue
+			}
+			skipDepth = -1
+		}
+		err := fn(fld)
+		switch {
+		case err == nil:
+		case errors.Is(err, SkipStruct):
+			skipDepth = fld.depth
+		case errors.Is(err, SkipAll):
+			return nil
+		default:
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package fmap
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type walkAddress struct {
+	City string
+	Zip  int
+}
+
+type walkUser struct {
+	Name    string
+	Address walkAddress
+	Age     int
+}
+
+func TestWalk(t *testing.T) {
+	t.Run("All", func(t *testing.T) {
+		var paths []string
+		err := Walk(walkUser{}, func(f Field) error {
+			paths = append(paths, f.GetStructPath())
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Name", "Address", "Address.City", "Address.Zip", "Age"}, paths)
+	})
+	t.Run("SkipStruct", func(t *testing.T) {
+		var paths []string
+		err := Walk(&walkUser{}, func(f Field) error {
+			paths = append(paths, f.GetStructPath())
+			if f.GetName() == "Address" {
+				return SkipStruct
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Name", "Address", "Age"}, paths)
+	})
+	t.Run("SkipAll", func(t *testing.T) {
+		var paths []string
+		err := Walk(&walkUser{}, func(f Field) error {
+			paths = append(paths, f.GetStructPath())
+			if f.GetName() == "City" {
+				return SkipAll
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Name", "Address", "Address.City"}, paths)
+	})
+	t.Run("Error", func(t *testing.T) {
+		expected := errors.New("test")
+		count := 0
+		err := Walk(&walkUser{}, func(f Field) error {
+			count++
+			return expected
+		})
+		assert.ErrorIs(t, err, expected)
+		assert.Equal(t, 1, count)
+	})
+	t.Run("NotAStruct", func(t *testing.T) {
+		assert.Error(t, Walk(1, func(f Field) error { return nil }))
+	})
+}
+
+func TestWalkValues(t *testing.T) {
+	user := &walkUser{Name: "John", Address: walkAddress{City: "Paris", Zip: 75000}, Age: 30}
+	values := map[string]any{}
+	err := WalkValues(user, func(f Field, v any) error {
+		values[f.GetStructPath()] = v
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"Name":         "John",
+		"Address":      walkAddress{City: "Paris", Zip: 75000},
+		"Address.City": "Paris",
+		"Address.Zip":  75000,
+		"Age":          30,
+	}, values)
+	assert.Error(t, WalkValues(*user, func(f Field, v any) error { return nil }))
+}
+
+type walkWide struct {
+	A1  walkUser
+	A2  walkUser
+	A3  walkUser
+	A4  walkUser
+	A5  walkUser
+	A6  walkUser
+	A7  walkUser
+	A8  walkUser
+	A9  walkUser
+	A10 walkUser
+	A11 walkUser
+	A12 walkUser
+	A13 walkUser
+	A14 walkUser
+	A15 walkUser
+	A16 walkUser
+	A17 walkUser
+	A18 walkUser
+	A19 walkUser
+	A20 walkUser
+}
+
+func TestWalkContext(t *testing.T) {
+	t.Run("All", func(t *testing.T) {
+		var paths []string
+		err := WalkContext(context.Background(), walkUser{}, func(f Field) error {
+			paths = append(paths, f.GetStructPath())
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Name", "Address", "Address.City", "Address.Zip", "Age"}, paths)
+	})
+	t.Run("Cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		count := 0
+		err := WalkContext(ctx, walkUser{}, func(f Field) error {
+			count++
+			return nil
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Zero(t, count)
+	})
+	t.Run("CancelledDuringWalk", func(t *testing.T) {
+		fields, _ := Get[walkWide]()
+		assert.Greater(t, len(fields.GetAllPaths()), walkContextInterval)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		count := 0
+		err := WalkContext(ctx, walkWide{}, func(f Field) error {
+			count++
+			if count == 10 {
+				cancel()
+			}
+			return nil
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+		// the context is checked every walkContextInterval fields
+		assert.Equal(t, walkContextInterval-1, count)
+	})
+	t.Run("SkipAndErrors", func(t *testing.T) {
+		var paths []string
+		err := WalkContext(context.Background(), walkUser{}, func(f Field) error {
+			paths = append(paths, f.GetStructPath())
+			if f.GetStructPath() == "Address" {
+				return SkipStruct
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Name", "Address", "Age"}, paths)
+		assert.Error(t, WalkContext(context.Background(), 1, func(f Field) error { return nil }))
+	})
+}
+
+func TestWalkTree(t *testing.T) {
+	type tree struct {
+		User  walkUser
+		Admin *walkUser
+	}
+	var lines []string
+	err := WalkTree(tree{}, func(f Field, depth int) error {
+		lines = append(lines, strings.Repeat("  ", depth)+f.GetName())
+		if f.GetName() == "Admin" {
+			return SkipStruct
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"User",
+		"  Name",
+		"  Address",
+		"    City",
+		"    Zip",
+		"  Age",
+		"Admin",
+	}, lines)
+
+	assert.Error(t, WalkTree(1, func(f Field, depth int) error { return nil }))
+}
@@ -0,0 +1,110 @@
+package fmap
+
+import "reflect"
+
+// wrappedStorage is the Storage decorator which fields are the fields of the wrapped Storage replaced
+// by the wrap func, e.g. SyncField or HookField ones. The storages derived from it, e.g. Leaves or SubTree,
+// are wrapped with the same func, the wrapped Storage is not modified.
+type wrappedStorage struct {
+	Storage
+	wrap   func(fld Field) Field
+	fields map[string]Field
+	// wrapped maps the fields of the wrapped Storage to the ones returned by the wrap func.
+	wrapped map[Field]Field
+	tagIndexes
+}
+
+// wrapStorage returns the Storage which fields are the s fields wrapped by the wrap func.
+// The promoted names, see Options.PromoteEmbedded, are kept and share the wrapped field with their struct paths.
+func wrapStorage(s Storage, wrap func(fld Field) Field) *wrappedStorage {
+	names := storageNames(s)
+	fields := make(map[string]Field, len(names))
+	wrapped := make(map[Field]Field, len(names))
+	for _, name := range names {
+		fld := s.MustFind(name)
+		if _, ok := wrapped[fld]; !ok {
+			wrapped[fld] = wrap(fld)
+		}
+		fields[name] = wrapped[fld]
+	}
+	return &wrappedStorage{Storage: s, wrap: wrap, fields: fields, wrapped: wrapped}
+}
+
+// storageNames returns all names the fields of the s can be found by, including the promoted ones.
+// Only the struct paths are known for the Storage implementations outside the package.
+func storageNames(s Storage) []string {
+	var asMap map[string]Field
+	switch st := s.(type) {
+	case *storage:
+		asMap = st.asMap
+	case *wrappedStorage:
+		asMap = st.fields
+	default:
+		return s.GetAllPaths()
+	}
+	names := make([]string, 0, len(asMap))
+	for name := range asMap {
+		names = append(names, name)
+	}
+	return names
+}
+
+func (s *wrappedStorage) Find(path string) (Field, bool) {
+	fld, ok := s.fields[path]
+	return fld, ok
+}
+
+func (s *wrappedStorage) MustFind(path string) Field {
+	fld, ok := s.fields[path]
+	if !ok {
+		panic(fieldNotFoundError(path))
+	}
+	return fld
+}
+
+func (s *wrappedStorage) Get(path string) (Field, bool) {
+	return s.Find(path)
+}
+
+func (s *wrappedStorage) MustGet(path string) Field {
+	return s.MustFind(path)
+}
+
+func (s *wrappedStorage) GetFieldByPtr(structPtr, fieldPtr any) (Field, error) {
+	fld, err := s.Storage.GetFieldByPtr(structPtr, fieldPtr)
+	if err != nil {
+		return nil, err
+	}
+	return s.wrapped[fld], nil
+}
+
+func (s *wrappedStorage) OfType(typeOf reflect.Type) []Field {
+	fields := s.Storage.OfType(typeOf)
+	for i, fld := range fields {
+		fields[i] = s.wrapped[fld]
+	}
+	return fields
+}
+
+func (s *wrappedStorage) Leaves() Storage {
+	return wrapStorage(s.Storage.Leaves(), s.wrap)
+}
+
+// Filter passes the wrapped fields to the pred.
+func (s *wrappedStorage) Filter(pred func(f Field) bool) Storage {
+	return wrapStorage(s.Storage.Filter(func(f Field) bool {
+		return pred(s.wrapped[f])
+	}), s.wrap)
+}
+
+func (s *wrappedStorage) SubTree(path string) Storage {
+	return wrapStorage(s.Storage.SubTree(path), s.wrap)
+}
+
+func (s *wrappedStorage) SubTreeRerooted(path string) Storage {
+	return wrapStorage(s.Storage.SubTreeRerooted(path), s.wrap)
+}
+
+func (s *wrappedStorage) Rebase(delta uintptr, outer reflect.Type) Storage {
+	return wrapStorage(s.Storage.Rebase(delta, outer), s.wrap)
+}